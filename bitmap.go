@@ -0,0 +1,124 @@
+package qrcode
+
+import "image"
+
+// BitmapOutlines traces the outlines of set modules of bitmap (see Bitmap) into closed
+// loops for vector writers, each loop is a list of module corners without the repeated
+// first one, (0, 0) is the top-left corner of bitmap. Set areas are on the right side
+// of loops (y is down), so holes are in the opposite direction of outer outlines.
+func BitmapOutlines(bitmap [][]bool) [][]image.Point {
+	marked := func(x, y int) bool {
+		return y >= 0 && y < len(bitmap) && x >= 0 && x < len(bitmap[y]) && bitmap[y][x]
+	}
+
+	var starts []image.Point
+	next := make(map[image.Point][]image.Point)
+	addEdge := func(from, to image.Point) {
+		starts = append(starts, from)
+		next[from] = append(next[from], to)
+	}
+	for y := range bitmap {
+		for x := range bitmap[y] {
+			if !bitmap[y][x] {
+				continue
+			}
+			if !marked(x, y-1) {
+				addEdge(image.Pt(x, y), image.Pt(x+1, y))
+			}
+			if !marked(x+1, y) {
+				addEdge(image.Pt(x+1, y), image.Pt(x+1, y+1))
+			}
+			if !marked(x, y+1) {
+				addEdge(image.Pt(x+1, y+1), image.Pt(x, y+1))
+			}
+			if !marked(x-1, y) {
+				addEdge(image.Pt(x, y+1), image.Pt(x, y))
+			}
+		}
+	}
+
+	var loops [][]image.Point
+	for _, start := range starts {
+		if len(next[start]) == 0 {
+			continue
+		}
+
+		// every corner has as many edges in as out, so that following edges always
+		// returns to start, corners touched diagonally are passed twice.
+		loop := []image.Point{start}
+		for cur := start; ; {
+			to := next[cur][0]
+			next[cur] = next[cur][1:]
+			if to == start {
+				break
+			}
+			loop = append(loop, to)
+			cur = to
+		}
+		loops = append(loops, simplifyLoop(loop))
+	}
+
+	return loops
+}
+
+// simplifyLoop removes corners in the middle of straight lines.
+func simplifyLoop(loop []image.Point) []image.Point {
+	out := make([]image.Point, 0, len(loop))
+	n := len(loop)
+	for i, p := range loop {
+		prev, next := loop[(i+n-1)%n], loop[(i+1)%n]
+		if (prev.X == p.X && p.X == next.X) || (prev.Y == p.Y && p.Y == next.Y) {
+			continue
+		}
+		out = append(out, p)
+	}
+
+	return out
+}
+
+// BitmapRects merges set modules of bitmap (see Bitmap) into rectangles greedily for
+// vector writers: runs of each row are extended downwards as long as rows below have
+// the same run. (0, 0) is the top-left corner of bitmap.
+func BitmapRects(bitmap [][]bool) []image.Rectangle {
+	used := make([][]bool, len(bitmap))
+	for y := range used {
+		used[y] = make([]bool, len(bitmap[y]))
+	}
+	free := func(x, y int) bool {
+		return bitmap[y][x] && !used[y][x]
+	}
+
+	var out []image.Rectangle
+	for y := range bitmap {
+		for x := 0; x < len(bitmap[y]); x++ {
+			if !free(x, y) {
+				continue
+			}
+
+			w := 1
+			for x+w < len(bitmap[y]) && free(x+w, y) {
+				w++
+			}
+			h := 1
+		extend:
+			for y+h < len(bitmap) {
+				for dx := 0; dx < w; dx++ {
+					if !free(x+dx, y+h) {
+						break extend
+					}
+				}
+				h++
+			}
+
+			for dy := 0; dy < h; dy++ {
+				for dx := 0; dx < w; dx++ {
+					used[y+dy][x+dx] = true
+				}
+			}
+			out = append(out, image.Rect(x, y, x+w, y+h))
+			x += w - 1
+		}
+	}
+
+	return out
+}
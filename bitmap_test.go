@@ -0,0 +1,34 @@
+package qrcode
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BitmapOutlines(t *testing.T) {
+	// a ring and two squares touched diagonally.
+	bitmap := [][]bool{
+		{true, true, true, false, false},
+		{true, false, true, false, false},
+		{true, true, true, false, false},
+		{false, false, false, true, false},
+		{false, false, false, false, true},
+	}
+	loops := BitmapOutlines(bitmap)
+	assert.Len(t, loops, 4)
+	assert.Equal(t, []image.Point{{0, 0}, {3, 0}, {3, 3}, {0, 3}}, loops[0])
+	// the hole goes in the opposite direction.
+	assert.Equal(t, []image.Point{{2, 1}, {1, 1}, {1, 2}, {2, 2}}, loops[1])
+}
+
+func Test_BitmapRects(t *testing.T) {
+	bitmap := [][]bool{
+		{true, true, false},
+		{true, true, true},
+		{false, true, true},
+	}
+	assert.Equal(t, []image.Rectangle{image.Rect(0, 0, 2, 2), image.Rect(2, 1, 3, 3), image.Rect(1, 2, 2, 3)},
+		BitmapRects(bitmap))
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -88,7 +87,6 @@ type generateContext struct {
 
 type fileOutputOptions struct {
 	output        string
-	outputSuffix  string
 	blockSize     uint8
 	borders       [4]int
 	isCircleShape bool
@@ -102,15 +100,6 @@ func (foo fileOutputOptions) applyOptions() []standard.ImageOption {
 		standard.WithBorderWidth(foo.borders[:]...),
 	}
 
-	switch foo.outputSuffix {
-	case "png":
-		options = append(options, standard.WithBuiltinImageEncoder(standard.PNG_FORMAT))
-	case "jpg", "jpeg":
-		fallthrough
-	default:
-		options = append(options, standard.WithBuiltinImageEncoder(standard.JPEG_FORMAT))
-	}
-
 	if foo.isCircleShape {
 		options = append(options, standard.WithCircleShape())
 	}
@@ -134,7 +123,6 @@ func parseGenerateContextFrom(c *cli.Context) *generateContext {
 		mode: writerMode_FILE,
 		FOO: &fileOutputOptions{
 			output:        c.String("output"),
-			outputSuffix:  strings.TrimPrefix(filepath.Ext(c.String("output")), "."),
 			blockSize:     uint8(c.Uint("block")),
 			borders:       [4]int{},
 			isCircleShape: c.Bool("circle"),
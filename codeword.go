@@ -0,0 +1,272 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+var (
+	errInvalidMatrix       = errors.New("invalid matrix")
+	errFormatInfoUnmatched = errors.New("could not decode format info")
+)
+
+// walkDataModules visits the modules of data area in m (which is not reserved by
+// function patterns) in placement order: from the right-bottom corner, two columns
+// at a time, upward and downward in turn, and the vertical timing column is skipped.
+// fn returns false to stop walking.
+//
+// References:
+//   - http://www.thonky.com/qr-code-tutorial/module-placement-matrix#Place-the-Data-Bits
+func walkDataModules(m *Matrix, dimension int, fn func(x, y int) bool) {
+	upward := true
+	for right := dimension - 1; right > 0; right -= 2 {
+		if right == 6 {
+			// skip the vertical timing pattern.
+			right--
+		}
+
+		for i := 0; i < dimension; i++ {
+			y := i
+			if upward {
+				y = dimension - 1 - i
+			}
+
+			for x := right; x > right-2; x-- {
+				state, err := m.at(x, y)
+				if err != nil || state.qrtype() != QRType_INIT {
+					continue
+				}
+				if !fn(x, y) {
+					return
+				}
+			}
+		}
+
+		upward = !upward
+	}
+}
+
+// BlockDamage is the damage of one Reed-Solomon block.
+type BlockDamage struct {
+	// DataCodewords and ECCodewords are the number of codewords in the block.
+	DataCodewords int
+	ECCodewords   int
+
+	// Capacity is the number of damaged codewords the block could correct. Some
+	// codewords of small versions are reserved for misdecode protection, so it may
+	// be less than ECCodewords / 2.
+	Capacity int
+
+	// Damaged is the number of codewords which have at least one damaged module.
+	Damaged int
+}
+
+// Margin returns how many more codewords the block could lose.
+func (b BlockDamage) Margin() int {
+	return b.Capacity - b.Damaged
+}
+
+// DamageReport describes how the damaged modules affect each Reed-Solomon block.
+type DamageReport struct {
+	// Version and ECLevel of the QR code, which are decoded from the matrix.
+	Version int
+	ECLevel ecLevel
+
+	// Blocks are in the order of block index.
+	Blocks []BlockDamage
+
+	// FunctionModules is the number of damaged modules of finder patterns, separators,
+	// timing patterns, format info and version info. They are not protected by error
+	// correction, decoders may fail to locate the QR code if they are damaged.
+	FunctionModules int
+}
+
+// Margin returns how many more codewords the weakest block could lose, negative
+// means the damage is over the correction capacity.
+func (r *DamageReport) Margin() int {
+	margin := 0
+	for i, block := range r.Blocks {
+		if m := block.Margin(); i == 0 || m < margin {
+			margin = m
+		}
+	}
+
+	return margin
+}
+
+// Safe reports whether all blocks are in correction capacity and no function
+// modules are damaged.
+func (r *DamageReport) Safe() bool {
+	return r.FunctionModules == 0 && r.Margin() >= 0
+}
+
+// Damage reports how the modules on which damaged returns true affect the Reed-Solomon
+// blocks of the QR code, such as covered by a logo.
+func (q *QRCode) Damage(damaged func(x, y int) bool) (*DamageReport, error) {
+	if q.mat == nil {
+		return nil, errInvalidMatrix
+	}
+
+	return q.mat.Damage(damaged)
+}
+
+// Damage reports how the modules on which damaged returns true affect the Reed-Solomon
+// blocks. The version and error correction level are decoded from the matrix, so it
+// could be used by a Writer.
+func (m *Matrix) Damage(damaged func(x, y int) bool) (*DamageReport, error) {
+	dimension := m.Width()
+	ver := (dimension - 17) / 4
+	if dimension != m.Height() || ver < 1 || ver > _VERSION_COUNT || ver*4+17 != dimension {
+		return nil, fmt.Errorf("%w: %dx%d", errInvalidMatrix, m.Width(), m.Height())
+	}
+
+	// damaged is given in the coordinates of the transformed matrix.
+	if m.mirrored {
+		fn := damaged
+		damaged = func(x, y int) bool { return fn(dimension-1-x, y) }
+	}
+	m = m.normalize()
+
+	ec, err := m.errorCorrectionLevel()
+	if err != nil {
+		return nil, err
+	}
+	v := loadVersion(ver, ec)
+
+	report := &DamageReport{
+		Version: ver,
+		ECLevel: ec,
+	}
+	owners := codewordOwners(v)
+	for _, g := range v.Groups {
+		for i := 0; i < g.NumBlocks; i++ {
+			report.Blocks = append(report.Blocks, BlockDamage{
+				DataCodewords: g.NumDataCodewords,
+				ECCodewords:   g.ECBlockwordsPerBlock,
+				Capacity:      (g.ECBlockwordsPerBlock - misdecodeProtection(ver, ec)) / 2,
+			})
+		}
+	}
+
+	reserved := newMatrix(dimension, dimension)
+	prefill(reserved, ver)
+	reserved.iter(IterDirection_ROW, func(x, y int, v qrvalue) {
+		switch v.qrtype() {
+		case QRType_FINDER, QRType_SPLITTER, QRType_TIMING, QRType_FORMAT, QRType_VERSION, QRType_DARK:
+			if damaged(x, y) {
+				report.FunctionModules++
+			}
+		}
+	})
+
+	pos, lastDamaged := 0, -1
+	walkDataModules(reserved, dimension, func(x, y int) bool {
+		codeword := pos / 8
+		pos++
+		if codeword >= len(owners) {
+			// remainder bits
+			return false
+		}
+		if codeword != lastDamaged && damaged(x, y) {
+			report.Blocks[owners[codeword]].Damaged++
+			lastDamaged = codeword
+		}
+		return true
+	})
+
+	return report, nil
+}
+
+// codewordOwners returns the block index of each codeword in the final (interleaved)
+// codeword sequence.
+func codewordOwners(v version) []int {
+	var dataLens, ecLens []int
+	for _, g := range v.Groups {
+		for i := 0; i < g.NumBlocks; i++ {
+			dataLens = append(dataLens, g.NumDataCodewords)
+			ecLens = append(ecLens, g.ECBlockwordsPerBlock)
+		}
+	}
+
+	var owners []int
+	interleave := func(lens []int) {
+		for i, more := 0, true; more; i++ {
+			more = false
+			for block, n := range lens {
+				if i < n {
+					owners = append(owners, block)
+					more = true
+				}
+			}
+		}
+	}
+	interleave(dataLens)
+	interleave(ecLens)
+
+	return owners
+}
+
+// misdecodeProtection returns the number of error correction codewords reserved
+// for misdecode protection, ref to ISO/IEC 18004 Table 9.
+func misdecodeProtection(v int, ec ecLevel) int {
+	switch {
+	case v == 1 && ec == ErrorCorrectionLow:
+		return 3
+	case v == 1 && ec == ErrorCorrectionMedium, v == 2 && ec == ErrorCorrectionLow:
+		return 2
+	case v == 1, v == 3 && ec == ErrorCorrectionLow:
+		return 1
+	}
+
+	return 0
+}
+
+// errorCorrectionLevel decodes the error correction level from the format info
+// (the copy around the top-left finder pattern) in matrix.
+func (m *Matrix) errorCorrectionLevel() (ecLevel, error) {
+	var (
+		x, y   = 0, 8
+		format uint32
+	)
+	// same order as fillFormatInfo
+	for pos := 0; pos < formatInfoBitsNum; pos++ {
+		v, err := m.at(x, y)
+		if err != nil {
+			return 0, err
+		}
+		format <<= 1
+		if v.qrbool() {
+			format |= 1
+		}
+
+		x++
+		if x == 6 {
+			x = 7
+		} else if x == 8 {
+			x = m.Width() - 8
+		}
+	}
+
+	best, bestDistance := -1, formatInfoBitsNum
+	for id, seq := range formatBitSequence {
+		if d := bits.OnesCount32(seq.regular ^ format); d < bestDistance {
+			best, bestDistance = id, d
+		}
+	}
+	// format info could correct at most 3 error bits.
+	if best < 0 || bestDistance > 3 {
+		return 0, errFormatInfoUnmatched
+	}
+
+	switch best >> 3 {
+	case 0x01:
+		return ErrorCorrectionLow, nil
+	case 0x00:
+		return ErrorCorrectionMedium, nil
+	case 0x03:
+		return ErrorCorrectionQuart, nil
+	default:
+		return ErrorCorrectionHighest, nil
+	}
+}
@@ -0,0 +1,65 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Matrix_errorCorrectionLevel(t *testing.T) {
+	levels := []ecLevel{
+		ErrorCorrectionLow, ErrorCorrectionMedium, ErrorCorrectionQuart, ErrorCorrectionHighest,
+	}
+	for _, ec := range levels {
+		qrc, err := NewWith("Test_Matrix_errorCorrectionLevel", WithErrorCorrectionLevel(ec))
+		require.NoError(t, err)
+
+		got, err := qrc.mat.errorCorrectionLevel()
+		require.NoError(t, err)
+		assert.Equal(t, ec, got)
+	}
+}
+
+func Test_codewordOwners(t *testing.T) {
+	// 5-Q: 2 blocks of 15 data codewords and 2 blocks of 16, 18 EC codewords per block.
+	v := loadVersion(5, ErrorCorrectionQuart)
+	owners := codewordOwners(v)
+	require.Len(t, owners, 134)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 0, 1, 2, 3}, owners[:8])
+	// the last data codewords only exist in the longer blocks.
+	assert.Equal(t, []int{0, 1, 2, 3, 2, 3}, owners[56:62])
+	assert.Equal(t, []int{0, 1, 2, 3}, owners[62:66])
+}
+
+func Test_QRCode_Damage(t *testing.T) {
+	qrc, err := NewWith("Test_QRCode_Damage",
+		WithErrorCorrectionLevel(ErrorCorrectionHighest), WithVersion(5))
+	require.NoError(t, err)
+
+	report, err := qrc.Damage(func(x, y int) bool { return false })
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Version)
+	assert.Equal(t, ErrorCorrectionHighest, report.ECLevel)
+	assert.Len(t, report.Blocks, 4)
+	assert.Equal(t, 11, report.Margin())
+	assert.True(t, report.Safe())
+
+	// the right-bottom corner module is the first bit of the first codeword.
+	d := qrc.Dimension()
+	report, err = qrc.Damage(func(x, y int) bool { return x == d-1 && y == d-1 })
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Blocks[0].Damaged)
+	assert.Equal(t, 10, report.Margin())
+
+	// the top-left finder pattern.
+	report, err = qrc.Damage(func(x, y int) bool { return x < 7 && y < 7 })
+	require.NoError(t, err)
+	assert.Equal(t, 49, report.FunctionModules)
+	assert.False(t, report.Safe())
+
+	report, err = qrc.Damage(func(x, y int) bool { return true })
+	require.NoError(t, err)
+	assert.Less(t, report.Margin(), 0)
+}
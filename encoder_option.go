@@ -26,6 +26,12 @@ type encodingOption struct {
 	// EcLevel specifies which ecLevel to use
 	EcLevel ecLevel
 
+	// Mirror outputs the matrix mirrored horizontally.
+	Mirror bool
+
+	// ReflectanceReversal outputs the matrix with dark and light modules swapped.
+	ReflectanceReversal bool
+
 	// PS: The version (which implicitly defines the byte capacity of the qrcode) is dynamically selected at runtime
 }
 
@@ -86,3 +92,21 @@ func WithMinimumVersion(version int) EncodeOption {
 		option.MinimumVersion = version
 	})
 }
+
+// WithMirror outputs the QR code mirrored horizontally, which could be read through
+// glass or by readers which need mirrored symbols. It is applied to the matrix, so
+// every Writer draws the mirrored QR code.
+func WithMirror() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.Mirror = true
+	})
+}
+
+// WithReflectanceReversal outputs the QR code with light modules on a dark background,
+// as ISO/IEC 18004 allows. It is applied to the matrix, and writers draw the quiet zone
+// of the reversed matrix in the dark color.
+func WithReflectanceReversal() EncodeOption {
+	return newFnEncodingOption(func(option *encodingOption) {
+		option.ReflectanceReversal = true
+	})
+}
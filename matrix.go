@@ -36,6 +36,16 @@ type Matrix struct {
 	mat    [][]qrvalue
 	width  int
 	height int
+
+	// mirrored and reversed record the transforms applied by Mirror and
+	// ReverseReflectance, so that the layout of the symbol could still be decoded.
+	mirrored bool
+	reversed bool
+
+	// payload and ecLevel are what the QR code is encoded from, so that writers
+	// could describe the QR code by commands of devices, such as label printers.
+	payload []byte
+	ecLevel ecLevel
 }
 
 // do some init work
@@ -66,9 +76,13 @@ func (m *Matrix) Copy() *Matrix {
 	}
 
 	m2 := &Matrix{
-		width:  m.width,
-		height: m.height,
-		mat:    mat2,
+		width:    m.width,
+		height:   m.height,
+		mat:      mat2,
+		mirrored: m.mirrored,
+		reversed: m.reversed,
+		payload:  m.payload,
+		ecLevel:  m.ecLevel,
 	}
 
 	return m2
@@ -178,3 +192,130 @@ func (m *Matrix) Bitmap() [][]bool {
 
 	return table
 }
+
+// BitmapWithQuietZone outputs the same as Bitmap surrounded by the quiet zone of
+// quietZone modules, so that (0, 0) is the top-left corner of the quiet zone. The
+// quiet zone of reflectance reversed matrix is set (dark), see IsReflectanceReversed.
+func (m *Matrix) BitmapWithQuietZone(quietZone int) [][]bool {
+	bm := m.Bitmap()
+	dark := m.IsReflectanceReversed()
+
+	table := make([][]bool, m.Height()+2*quietZone)
+	for y := range table {
+		table[y] = make([]bool, m.Width()+2*quietZone)
+		for x := range table[y] {
+			if y < quietZone || y >= quietZone+m.Height() || x < quietZone || x >= quietZone+m.Width() {
+				table[y][x] = dark
+				continue
+			}
+			table[y][x] = bm[y-quietZone][x-quietZone]
+		}
+	}
+
+	return table
+}
+
+// FunctionPatterns outputs the same shape as Bitmap, true means the module belongs to
+// function patterns (finder patterns, separators, timing patterns, alignment patterns,
+// format info and version info) rather than data. Unlike QRValue.Type, alignment
+// patterns are included. If the matrix is not a standard QR code, only the modules
+// typed as non-data are reported.
+func (m *Matrix) FunctionPatterns() [][]bool {
+	table := make([][]bool, m.Height())
+	for t := range table {
+		table[t] = make([]bool, m.Width())
+	}
+
+	dimension := m.Width()
+	ver := (dimension - 17) / 4
+	if dimension != m.Height() || ver < 1 || ver > _VERSION_COUNT || ver*4+17 != dimension {
+		m.iter(IterDirection_ROW, func(x, y int, s qrvalue) {
+			table[y][x] = s.qrtype() != QRType_INIT && s.qrtype() != QRType_DATA
+		})
+		return table
+	}
+
+	// alignment patterns are typed as data, so the function patterns are the modules
+	// reserved before data is filled.
+	reserved := newMatrix(dimension, dimension)
+	prefill(reserved, ver)
+	reserved.iter(IterDirection_ROW, func(x, y int, s qrvalue) {
+		if m.mirrored {
+			x = dimension - 1 - x
+		}
+		table[y][x] = s.qrtype() != QRType_INIT
+	})
+
+	return table
+}
+
+// Mirror returns a copy of the matrix mirrored horizontally, as the symbol is read
+// through glass. Mirroring a mirrored matrix gets the normal one.
+func (m *Matrix) Mirror() *Matrix {
+	m2 := m.Copy()
+	for w := 0; w < m.width; w++ {
+		copy(m2.mat[w], m.mat[m.width-1-w])
+	}
+	m2.mirrored = !m.mirrored
+
+	return m2
+}
+
+// ReverseReflectance returns a copy of the matrix in which dark and light modules are
+// swapped, types of modules are kept. Writers should draw the quiet zone of reversed
+// matrix in the dark color, see IsReflectanceReversed.
+func (m *Matrix) ReverseReflectance() *Matrix {
+	m2 := m.Copy()
+	for w := 0; w < m.width; w++ {
+		for h := 0; h < m.height; h++ {
+			m2.mat[w][h] ^= 1
+		}
+	}
+	m2.reversed = !m.reversed
+
+	return m2
+}
+
+// IsMirrored reports whether the matrix is mirrored.
+func (m *Matrix) IsMirrored() bool {
+	return m.mirrored
+}
+
+// IsReflectanceReversed reports whether the matrix is reflectance reversed, which means
+// the quiet zone around it should be dark (set) rather than light.
+func (m *Matrix) IsReflectanceReversed() bool {
+	return m.reversed
+}
+
+// Payload returns the data the QR code is encoded from, it's nil if the matrix is not
+// built by QRCode.
+func (m *Matrix) Payload() []byte {
+	return m.payload
+}
+
+// ErrorCorrectionLevel returns the error correction level of the QR code, it's decoded
+// from the format info if the matrix is not built by QRCode, 0 if it's undecodable.
+func (m *Matrix) ErrorCorrectionLevel() ecLevel {
+	if m.ecLevel != 0 {
+		return m.ecLevel
+	}
+
+	ec, err := m.normalize().errorCorrectionLevel()
+	if err != nil {
+		return 0
+	}
+
+	return ec
+}
+
+// normalize returns the matrix without transforms of Mirror and ReverseReflectance.
+func (m *Matrix) normalize() *Matrix {
+	if m.mirrored {
+		m = m.Mirror()
+	}
+	if m.reversed {
+		m = m.ReverseReflectance()
+	}
+
+	return m
+}
@@ -171,3 +171,97 @@ func Test_Matrix_RowAndCol(t *testing.T) {
 		})
 	}
 }
+
+func Test_Matrix_FunctionPatterns(t *testing.T) {
+	qrc, err := NewWith("hello", WithVersion(2))
+	assert.NoError(t, err)
+
+	fp := qrc.mat.FunctionPatterns()
+	assert.Len(t, fp, 25)
+	// finder, timing and format info
+	assert.True(t, fp[0][0])
+	assert.True(t, fp[6][10])
+	assert.True(t, fp[8][2])
+	// the alignment pattern of version 2 is centered at (18, 18).
+	assert.True(t, fp[18][18])
+	assert.True(t, fp[16][20])
+	// data area
+	assert.False(t, fp[15][15])
+	assert.False(t, fp[24][24])
+}
+
+func Test_Matrix_Mirror_ReverseReflectance(t *testing.T) {
+	qrc, err := NewWith("hello", WithVersion(2))
+	assert.NoError(t, err)
+	normal := qrc.Matrix()
+	d := normal.Width()
+
+	transformed, err := NewWith("hello", WithVersion(2), WithMirror(), WithReflectanceReversal())
+	assert.NoError(t, err)
+	mat := transformed.Matrix()
+	assert.True(t, mat.IsMirrored())
+	assert.True(t, mat.IsReflectanceReversed())
+
+	bm, tbm := normal.Bitmap(), mat.Bitmap()
+	for y := 0; y < d; y++ {
+		for x := 0; x < d; x++ {
+			assert.Equal(t, bm[y][x], !tbm[y][d-1-x])
+		}
+	}
+
+	// transforms are undone by applying them again.
+	assert.Equal(t, normal.Bitmap(), mat.Mirror().ReverseReflectance().Bitmap())
+	assert.False(t, mat.Mirror().IsMirrored())
+
+	// function patterns and damage are reported in the transformed coordinates.
+	fp := mat.FunctionPatterns()
+	assert.True(t, fp[0][d-1])
+	assert.False(t, fp[15][d-1-15])
+
+	report, err := mat.Damage(func(x, y int) bool { return x >= d-7 && y < 7 })
+	assert.NoError(t, err)
+	assert.Equal(t, 49, report.FunctionModules)
+	assert.Equal(t, ErrorCorrectionQuart, report.ECLevel)
+}
+
+func Test_Matrix_Payload_ErrorCorrectionLevel(t *testing.T) {
+	qrc, err := NewWith("hello", WithErrorCorrectionLevel(ErrorCorrectionHighest), WithMirror())
+	assert.NoError(t, err)
+	mat := qrc.Matrix()
+	assert.Equal(t, []byte("hello"), mat.Payload())
+	assert.Equal(t, ErrorCorrectionHighest, mat.ErrorCorrectionLevel())
+
+	// matrix not built by QRCode has no payload, the level is decoded from format info.
+	mat.payload, mat.ecLevel = nil, 0
+	assert.Nil(t, mat.Payload())
+	assert.Equal(t, ErrorCorrectionHighest, mat.ErrorCorrectionLevel())
+}
+
+func Test_Matrix_BitmapWithQuietZone(t *testing.T) {
+	qrc, err := New("quiet zone")
+	assert.NoError(t, err)
+	mat := qrc.Matrix()
+	bm := mat.Bitmap()
+	d := mat.Width()
+
+	table := mat.BitmapWithQuietZone(2)
+	assert.Len(t, table, d+4)
+	for y := range table {
+		assert.Len(t, table[y], d+4)
+		for x := range table[y] {
+			want := false
+			if y >= 2 && y < d+2 && x >= 2 && x < d+2 {
+				want = bm[y-2][x-2]
+			}
+			assert.Equal(t, want, table[y][x], "module (%d, %d)", x, y)
+		}
+	}
+	assert.Equal(t, bm, mat.BitmapWithQuietZone(0))
+
+	// the quiet zone of reflectance reversed matrix is dark.
+	reversed := mat.ReverseReflectance()
+	table = reversed.BitmapWithQuietZone(1)
+	assert.True(t, table[0][0])
+	assert.True(t, table[d+1][d+1])
+	assert.Equal(t, !bm[0][0], table[1][1])
+}
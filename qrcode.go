@@ -54,6 +54,15 @@ func build(raw []byte, option *encodingOption) (*QRCode, error) {
 	}
 
 	qrc.masking()
+	qrc.mat.payload = raw
+	qrc.mat.ecLevel = qrc.v.ECLevel
+
+	if option.Mirror {
+		qrc.mat = qrc.mat.Mirror()
+	}
+	if option.ReflectanceReversal {
+		qrc.mat = qrc.mat.ReverseReflectance()
+	}
 
 	return qrc, nil
 }
@@ -72,6 +81,7 @@ type QRCode struct {
 	v              version  // indicate the QR version to encode.
 }
 
+// Save writes the QR code into w and closes w.
 func (q *QRCode) Save(w Writer) error {
 	if w == nil {
 		w = nonWriter{}
@@ -83,9 +93,28 @@ func (q *QRCode) Save(w Writer) error {
 		}
 	}()
 
+	return q.WriteWith(w)
+}
+
+// WriteWith writes the QR code into w like Save, but w is not closed, so that it
+// could be reused for more QR codes.
+func (q *QRCode) WriteWith(w Writer) error {
+	if w == nil {
+		return nil
+	}
+
 	return w.Write(*q.mat)
 }
 
+// Matrix returns a copy of the QR code matrix, which could be rendered without a Writer.
+func (q *QRCode) Matrix() Matrix {
+	if q.mat == nil {
+		return Matrix{}
+	}
+
+	return *q.mat.Copy()
+}
+
 func (q *QRCode) Dimension() int {
 	if q.mat == nil {
 		return 0
@@ -330,38 +359,45 @@ func (q *QRCode) prefillMatrix() {
 		q.mat = newMatrix(dimension, dimension)
 	}
 
+	prefill(q.mat, q.v.Ver)
+}
+
+// prefill reserves the function patterns of version ver in m.
+func prefill(m *Matrix, ver int) {
+	dimension := m.Width()
+
 	// add finder left-top
-	addFinder(q.mat, 0, 0)
-	addSplitter(q.mat, 7, 7, dimension)
+	addFinder(m, 0, 0)
+	addSplitter(m, 7, 7, dimension)
 	debugLogf("finish left-top finder")
 	// add finder right-top
-	addFinder(q.mat, dimension-7, 0)
-	addSplitter(q.mat, dimension-8, 7, dimension)
+	addFinder(m, dimension-7, 0)
+	addSplitter(m, dimension-8, 7, dimension)
 	debugLogf("finish right-top finder")
 	// add finder left-bottom
-	addFinder(q.mat, 0, dimension-7)
-	addSplitter(q.mat, 7, dimension-8, dimension)
+	addFinder(m, 0, dimension-7)
+	addSplitter(m, 7, dimension-8, dimension)
 	debugLogf("finish left-bottom finder")
 
 	// only version-1 QR code has no alignment module
-	if q.v.Ver > 1 {
+	if ver > 1 {
 		// add align-mode related to version cfg
-		for _, loc := range loadAlignmentPatternLocV2(q.v.Ver) {
-			addAlignment(q.mat, loc.X, loc.Y)
+		for _, loc := range loadAlignmentPatternLocV2(ver) {
+			addAlignment(m, loc.X, loc.Y)
 		}
 		debugLogf("finish align")
 	}
 	// add timing line
-	addTimingLine(q.mat, dimension)
+	addTimingLine(m, dimension)
 	// add darkBlock always be position (4*ver+9, 8)
-	addDarkBlock(q.mat, 8, 4*q.v.Ver+9)
+	addDarkBlock(m, 8, 4*ver+9)
 	// reserveFormatBlock for version and format info
-	reserveFormatBlock(q.mat, dimension)
+	reserveFormatBlock(m, dimension)
 
 	// reserveVersionBlock for version over 7
 	// only version 7 and larger version should add version info
-	if q.v.Ver >= 7 {
-		reserveVersionBlock(q.mat, dimension)
+	if ver >= 7 {
+		reserveVersionBlock(m, dimension)
 	}
 }
 
@@ -530,74 +566,25 @@ func reserveVersionBlock(m *Matrix, dimension int) {
 //   - http://www.thonky.com/qr-code-tutorial/module-placement-matrix#Place-the-Data-Bits
 func (q *QRCode) fillDataBinary(m *Matrix, dimension int) {
 	var (
-		// x always move from right, left right loop (2 rows), y move upward, downward, upward loop
-		x, y      = dimension - 1, dimension - 1
-		l         = q.dataBSet.Len()
-		upForward = true
-		pos       int
+		l   = q.dataBSet.Len()
+		pos int
 	)
 
-	for i := 0; pos < l; i++ {
-		// debugLogf("fillDataBinary: dimension: %d, len: %d: pos: %d", dimension, l, pos)
+	walkDataModules(m, dimension, func(x, y int) bool {
+		if pos >= l {
+			return false
+		}
+
 		set := QRValue_DATA_V0
 		if q.dataBSet.At(pos) {
 			set = QRValue_DATA_V1
 		}
+		_ = m.set(x, y, set)
+		pos++
+		return true
+	})
 
-		state, err := m.at(x, y)
-		if err != nil {
-			if err == ErrorOutRangeOfW {
-				break
-			}
-
-			if err == ErrorOutRangeOfH {
-				// turn around while y is out of range.
-				x = x - 2
-				switch upForward {
-				case true:
-					y = y + 1
-				default:
-					y = y - 1
-				}
-
-				if x == 7 || x == 6 {
-					x = x - 1
-				}
-				upForward = !upForward
-				state, _ = m.at(x, y) // renew state qrbool after turn around writing direction.
-			}
-		}
-
-		// data bit should only be set into un-set block in matrix.
-		if state.qrtype() == QRType_INIT {
-			_ = m.set(x, y, set)
-			pos++
-			debugLogf("normal set turn forward: upForward: %v, x: %d, y: %d", upForward, x, y)
-		}
-
-		// DO NOT CHANGE FOLLOWING CODE FOR NOW !!!
-		// change x, y
-		mod2 := i % 2
-
-		// in one 8bit block
-		if upForward {
-			if mod2 == 0 {
-				x = x - 1
-			} else {
-				y = y - 1
-				x = x + 1
-			}
-		} else {
-			if mod2 == 0 {
-				x = x - 1
-			} else {
-				y = y + 1
-				x = x + 1
-			}
-		}
-	}
-
-	debugLogf("fillDone and x: %d, y: %d, pos: %d, total: %d", x, y, pos, l)
+	debugLogf("fillDone and pos: %d, total: %d", pos, l)
 }
 
 // draw from bitset to matrix.Matrix, calculate all mask modula score,
@@ -148,3 +148,37 @@ func Test_NewWith_MinimumVersion_WithExplicitVersion(t *testing.T) {
 	// WithVersion takes precedence, so version should be 10
 	assert.Equal(t, 10, qrc.v.Ver)
 }
+
+type countingWriter struct {
+	writes, closes int
+}
+
+func (w *countingWriter) Write(mat Matrix) error {
+	w.writes++
+	return nil
+}
+
+func (w *countingWriter) Close() error {
+	w.closes++
+	return nil
+}
+
+func Test_QRCode_WriteWith(t *testing.T) {
+	qrc, err := New("Test_QRCode_WriteWith")
+	assert.NoError(t, err)
+
+	w := &countingWriter{}
+	assert.NoError(t, qrc.WriteWith(w))
+	assert.NoError(t, qrc.WriteWith(w))
+	assert.Equal(t, 2, w.writes)
+	assert.Zero(t, w.closes)
+
+	assert.NoError(t, qrc.Save(w))
+	assert.Equal(t, 1, w.closes)
+
+	// the matrix is a copy.
+	mat := qrc.Matrix()
+	assert.Equal(t, qrc.Dimension(), mat.Width())
+	_ = mat.set(0, 0, QRValue_INIT_V0)
+	assert.True(t, qrc.mat.Bitmap()[0][0])
+}
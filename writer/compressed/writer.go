@@ -64,8 +64,13 @@ func (w compressedWriter) Write(mat qrcode.Matrix) error {
 		}
 	}
 
-	// background
-	rectangle(0, 0, width, height, img, bgColor)
+	// background, the quiet zone of reflectance reversed matrix is dark, and light
+	// modules are drawn over it.
+	quietZone := bgColor
+	if mat.IsReflectanceReversed() {
+		quietZone = fgColor
+	}
+	rectangle(0, 0, width, height, img, quietZone)
 
 	mat.Iterate(qrcode.IterDirection_COLUMN, func(x int, y int, v qrcode.QRValue) {
 		sx := x*blockWidth + padding
@@ -73,8 +78,11 @@ func (w compressedWriter) Write(mat qrcode.Matrix) error {
 		es := (x+1)*blockWidth + padding
 		ey := (y+1)*blockWidth + padding
 
-		if v.IsSet() {
+		switch {
+		case v.IsSet():
 			rectangle(sx, sy, es, ey, img, fgColor)
+		case quietZone != bgColor:
+			rectangle(sx, sy, es, ey, img, bgColor)
 		}
 
 		//switch v.IsSet() {
@@ -0,0 +1,95 @@
+package dxf
+
+// Unit is the drawing unit of DXF.
+type Unit uint8
+
+const (
+	// UnitMillimeter draws in mm, it's the default.
+	UnitMillimeter Unit = iota
+	// UnitInch draws in inches.
+	UnitInch
+)
+
+// insUnits returns the value of $INSUNITS.
+func (u Unit) insUnits() int {
+	if u == UnitInch {
+		return 1
+	}
+
+	return 4
+}
+
+const (
+	_defaultModuleSize = 1.0
+	// quietZoneModules is the width of the quiet zone required by ISO/IEC 18004.
+	quietZoneModules = 4
+
+	_layer       = "QR"
+	_layerFinder = "QR_FINDER"
+	_layerData   = "QR_DATA"
+)
+
+// Option configures the DXF writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	unit Unit
+	// moduleSize is the size of each module in unit.
+	moduleSize float64
+	// layers puts finder patterns and the rest into separate layers.
+	layers bool
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		unit:       UnitMillimeter,
+		moduleSize: _defaultModuleSize,
+	}
+}
+
+// WithUnit sets the drawing unit, UnitMillimeter by default.
+func WithUnit(u Unit) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if u > UnitInch {
+			return
+		}
+
+		o.unit = u
+	})
+}
+
+// WithModuleSize sets the size of each module in the drawing unit, 1 by default.
+func WithModuleSize(size float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if size <= 0 {
+			return
+		}
+
+		o.moduleSize = size
+	})
+}
+
+// WithLayers puts outlines of finder patterns into layer QR_FINDER and the rest into
+// layer QR_DATA, so that they could be cut or colored separately. All outlines are in
+// layer QR by default.
+func WithLayers() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.layers = true
+	})
+}
@@ -0,0 +1,348 @@
+package dxf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// Writer implements qrcode.Writer to draw outlines of dark areas as closed LWPOLYLINE
+// entities in DXF, holes are separate polylines inside outer outlines.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a DXF writer which writes the drawing into out, out is not closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// layer is outlines of dark areas in a layer.
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("dxf: empty matrix")
+
+type layer struct {
+	name  string
+	loops [][]image.Point
+}
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+
+	dark, finder := grids(mat)
+	var layers []layer
+	if w.option.layers {
+		data := make([][]bool, len(dark))
+		finders := make([][]bool, len(dark))
+		for y := range dark {
+			data[y] = make([]bool, len(dark[y]))
+			finders[y] = make([]bool, len(dark[y]))
+			for x := range dark[y] {
+				finders[y][x] = dark[y][x] && finder[y][x]
+				data[y][x] = dark[y][x] && !finder[y][x]
+			}
+		}
+		layers = []layer{
+			{name: _layerFinder, loops: qrcode.BitmapOutlines(finders)},
+			{name: _layerData, loops: qrcode.BitmapOutlines(data)},
+		}
+	} else {
+		layers = []layer{{name: _layer, loops: qrcode.BitmapOutlines(dark)}}
+	}
+
+	bw := bufio.NewWriter(w.out)
+	w.writeDXF(bw, layers, len(dark))
+	return bw.Flush()
+}
+
+// grids returns dark modules and finder patterns of mat. The dark quiet zone of
+// reflectance reversed matrix is included, since the material is light.
+func grids(mat qrcode.Matrix) (dark, finder [][]bool) {
+	qz := 0
+	if mat.IsReflectanceReversed() {
+		qz = quietZoneModules
+	}
+	dark = mat.BitmapWithQuietZone(qz)
+	n := mat.Width()
+
+	// finder patterns are at top-left, top-right and bottom-left corners, the
+	// bottom-left one is at bottom-right in mirrored matrix.
+	bottomX := 0
+	if mat.IsMirrored() {
+		bottomX = n - 7
+	}
+	inFinder := func(x, y int) bool {
+		in := func(x0, y0 int) bool { return x >= x0 && x < x0+7 && y >= y0 && y < y0+7 }
+		return in(0, 0) || in(n-7, 0) || in(bottomX, n-7)
+	}
+
+	finder = make([][]bool, len(dark))
+	for y := range finder {
+		finder[y] = make([]bool, len(dark[y]))
+		for x := range finder[y] {
+			finder[y][x] = inFinder(x-qz, y-qz)
+		}
+	}
+
+	return dark, finder
+}
+
+// num formats v rounded to 6 decimals.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
+
+// drawing buffers group code pairs of sections after HEADER, handles are allocated in
+// order, so that $HANDSEED is known when they are done.
+type drawing struct {
+	bytes.Buffer
+	handle int64
+}
+
+func (d *drawing) pair(code int, value string) {
+	fmt.Fprintf(d, "%d\n%s\n", code, value)
+}
+
+// newHandle allocates a handle, handles are hexadecimal.
+func (d *drawing) newHandle() string {
+	d.handle++
+	return strings.ToUpper(strconv.FormatInt(d.handle, 16))
+}
+
+// table starts the symbol table of name with count records, and returns its handle.
+func (d *drawing) table(name string, count int) string {
+	h := d.newHandle()
+	d.pair(0, "TABLE")
+	d.pair(2, name)
+	d.pair(5, h)
+	d.pair(330, "0")
+	d.pair(100, "AcDbSymbolTable")
+	d.pair(70, strconv.Itoa(count))
+	if name == "DIMSTYLE" {
+		d.pair(100, "AcDbDimStyleTable")
+	}
+
+	return h
+}
+
+// record starts a record of the table owner, subclass is the marker of its type. The
+// handle of record is returned.
+func (d *drawing) record(typ, owner, subclass, name string) string {
+	h := d.newHandle()
+	d.pair(0, typ)
+	// DIMSTYLE records have handles in group code 105 instead of 5.
+	if typ == "DIMSTYLE" {
+		d.pair(105, h)
+	} else {
+		d.pair(5, h)
+	}
+	d.pair(330, owner)
+	d.pair(100, "AcDbSymbolTableRecord")
+	d.pair(100, subclass)
+	d.pair(2, name)
+	d.pair(70, "0")
+
+	return h
+}
+
+// block writes the empty block definition of the block record owner.
+func (d *drawing) block(owner, name string, paperSpace bool) {
+	d.pair(0, "BLOCK")
+	d.pair(5, d.newHandle())
+	d.pair(330, owner)
+	d.pair(100, "AcDbEntity")
+	if paperSpace {
+		d.pair(67, "1")
+	}
+	d.pair(8, "0")
+	d.pair(100, "AcDbBlockBegin")
+	d.pair(2, name)
+	d.pair(70, "0")
+	d.pair(10, "0")
+	d.pair(20, "0")
+	d.pair(30, "0")
+	d.pair(3, name)
+	d.pair(1, "")
+	d.pair(0, "ENDBLK")
+	d.pair(5, d.newHandle())
+	d.pair(330, owner)
+	d.pair(100, "AcDbEntity")
+	if paperSpace {
+		d.pair(67, "1")
+	}
+	d.pair(8, "0")
+	d.pair(100, "AcDbBlockEnd")
+}
+
+// writeDXF writes the drawing in DXF R2000 (AC1015): HEADER, CLASSES, TABLES with
+// the standard records, BLOCKS of model and paper space, ENTITIES and OBJECTS with
+// the root dictionary. Every object has a handle and its owner. Y axis of DXF is up.
+func (w *Writer) writeDXF(bw *bufio.Writer, layers []layer, rows int) {
+	size := w.option.moduleSize
+	extent := num(float64(rows) * size)
+	pair := func(code int, value string) {
+		fmt.Fprintf(bw, "%d\n%s\n", code, value)
+	}
+
+	d := &drawing{}
+	d.pair(0, "SECTION")
+	d.pair(2, "CLASSES")
+	d.pair(0, "ENDSEC")
+
+	d.pair(0, "SECTION")
+	d.pair(2, "TABLES")
+	d.table("VPORT", 0)
+	d.pair(0, "ENDTAB")
+
+	ltype := d.table("LTYPE", 3)
+	for _, name := range []string{"ByBlock", "ByLayer", "Continuous"} {
+		d.record("LTYPE", ltype, "AcDbLinetypeTableRecord", name)
+		description := ""
+		if name == "Continuous" {
+			description = "Solid line"
+		}
+		d.pair(3, description)
+		d.pair(72, "65")
+		d.pair(73, "0")
+		d.pair(40, "0")
+	}
+	d.pair(0, "ENDTAB")
+
+	layer := d.table("LAYER", len(layers)+1)
+	for _, name := range append([]string{"0"}, layerNames(layers)...) {
+		d.record("LAYER", layer, "AcDbLayerTableRecord", name)
+		d.pair(62, "7")
+		d.pair(6, "Continuous")
+		d.pair(370, "-3")
+	}
+	d.pair(0, "ENDTAB")
+
+	style := d.table("STYLE", 1)
+	d.record("STYLE", style, "AcDbTextStyleTableRecord", "Standard")
+	d.pair(40, "0")
+	d.pair(41, "1")
+	d.pair(50, "0")
+	d.pair(71, "0")
+	d.pair(42, "2.5")
+	d.pair(3, "txt")
+	d.pair(4, "")
+	d.pair(0, "ENDTAB")
+
+	for _, name := range []string{"VIEW", "UCS"} {
+		d.table(name, 0)
+		d.pair(0, "ENDTAB")
+	}
+
+	appID := d.table("APPID", 1)
+	d.record("APPID", appID, "AcDbRegAppTableRecord", "ACAD")
+	d.pair(0, "ENDTAB")
+
+	dimStyle := d.table("DIMSTYLE", 1)
+	d.record("DIMSTYLE", dimStyle, "AcDbDimStyleTableRecord", "Standard")
+	d.pair(0, "ENDTAB")
+
+	blockRecord := d.table("BLOCK_RECORD", 2)
+	modelSpace := d.record("BLOCK_RECORD", blockRecord, "AcDbBlockTableRecord", "*Model_Space")
+	paperSpace := d.record("BLOCK_RECORD", blockRecord, "AcDbBlockTableRecord", "*Paper_Space")
+	d.pair(0, "ENDTAB")
+	d.pair(0, "ENDSEC")
+
+	d.pair(0, "SECTION")
+	d.pair(2, "BLOCKS")
+	d.block(modelSpace, "*Model_Space", false)
+	d.block(paperSpace, "*Paper_Space", true)
+	d.pair(0, "ENDSEC")
+
+	d.pair(0, "SECTION")
+	d.pair(2, "ENTITIES")
+	for _, l := range layers {
+		for _, loop := range l.loops {
+			d.pair(0, "LWPOLYLINE")
+			d.pair(5, d.newHandle())
+			d.pair(330, modelSpace)
+			d.pair(100, "AcDbEntity")
+			d.pair(8, l.name)
+			d.pair(100, "AcDbPolyline")
+			d.pair(90, strconv.Itoa(len(loop)))
+			d.pair(70, "1") // closed
+			for _, p := range loop {
+				d.pair(10, num(float64(p.X)*size))
+				d.pair(20, num(float64(rows-p.Y)*size))
+			}
+		}
+	}
+	d.pair(0, "ENDSEC")
+
+	// the root dictionary is the first object, it owns the dictionary of groups.
+	d.pair(0, "SECTION")
+	d.pair(2, "OBJECTS")
+	root := d.newHandle()
+	group := d.newHandle()
+	d.pair(0, "DICTIONARY")
+	d.pair(5, root)
+	d.pair(330, "0")
+	d.pair(100, "AcDbDictionary")
+	d.pair(281, "1")
+	d.pair(3, "ACAD_GROUP")
+	d.pair(350, group)
+	d.pair(0, "DICTIONARY")
+	d.pair(5, group)
+	d.pair(330, root)
+	d.pair(100, "AcDbDictionary")
+	d.pair(281, "1")
+	d.pair(0, "ENDSEC")
+	d.pair(0, "EOF")
+
+	pair(0, "SECTION")
+	pair(2, "HEADER")
+	pair(9, "$ACADVER")
+	pair(1, "AC1015")
+	pair(9, "$HANDSEED")
+	pair(5, strings.ToUpper(strconv.FormatInt(d.handle+1, 16)))
+	pair(9, "$INSUNITS")
+	pair(70, strconv.Itoa(w.option.unit.insUnits()))
+	pair(9, "$EXTMIN")
+	pair(10, "0")
+	pair(20, "0")
+	pair(30, "0")
+	pair(9, "$EXTMAX")
+	pair(10, extent)
+	pair(20, extent)
+	pair(30, "0")
+	pair(0, "ENDSEC")
+	bw.Write(d.Bytes())
+}
+
+func layerNames(layers []layer) []string {
+	names := make([]string, 0, len(layers))
+	for _, l := range layers {
+		names = append(names, l.name)
+	}
+
+	return names
+}
@@ -0,0 +1,288 @@
+package dxf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type polyline struct {
+	layer  string
+	points [][2]float64
+}
+
+// parse reads group code pairs of DXF, returns header variables and polylines.
+func parse(t *testing.T, dxf string) (header map[string]string, polylines []polyline) {
+	lines := strings.Split(strings.TrimSuffix(dxf, "\n"), "\n")
+	require.Zero(t, len(lines)%2)
+
+	header = make(map[string]string)
+	var (
+		variable string
+		cur      *polyline
+	)
+	for i := 0; i < len(lines); i += 2 {
+		code, value := lines[i], lines[i+1]
+		switch {
+		case code == "9":
+			variable = value
+		case code == "0":
+			variable = ""
+			if cur != nil {
+				polylines = append(polylines, *cur)
+				cur = nil
+			}
+			if value == "LWPOLYLINE" {
+				cur = &polyline{}
+			}
+		case variable != "" && header[variable] == "":
+			header[variable] = value
+		case cur != nil && code == "8":
+			cur.layer = value
+		case cur != nil && code == "10":
+			v, err := strconv.ParseFloat(value, 64)
+			require.NoError(t, err)
+			cur.points = append(cur.points, [2]float64{v})
+		case cur != nil && code == "20":
+			v, err := strconv.ParseFloat(value, 64)
+			require.NoError(t, err)
+			cur.points[len(cur.points)-1][1] = v
+		}
+	}
+
+	return header, polylines
+}
+
+// inside reports whether (x, y) is inside of odd polylines.
+func inside(polylines []polyline, x, y float64) bool {
+	in := false
+	for _, pl := range polylines {
+		for i := range pl.points {
+			a, b := pl.points[i], pl.points[(i+1)%len(pl.points)]
+			if (a[1] > y) != (b[1] > y) && x < a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+				in = !in
+			}
+		}
+	}
+
+	return in
+}
+
+func Test_Writer(t *testing.T) {
+	qrc, err := qrcode.New("cut me out of vinyl")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width()
+
+	// invalid options are ignored.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithUnit(UnitInch), WithModuleSize(0.1), WithUnit(UnitInch+1), WithModuleSize(-1))))
+	header, polylines := parse(t, buf.String())
+	assert.Equal(t, "1", header["$INSUNITS"])
+	assert.Equal(t, "AC1015", header["$ACADVER"])
+
+	bm := mat.Bitmap()
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			px, py := (float64(x)+0.5)*0.1, (float64(n-y)-0.5)*0.1
+			require.Equal(t, bm[y][x], inside(polylines, px, py), "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_Layers(t *testing.T) {
+	qrc, err := qrcode.NewWith("layers", qrcode.WithMirror())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLayers())))
+	_, polylines := parse(t, buf.String())
+	byLayer := make(map[string][]polyline)
+	for _, pl := range polylines {
+		byLayer[pl.layer] = append(byLayer[pl.layer], pl)
+	}
+	require.Len(t, byLayer, 2)
+	// each finder pattern is an outer square with a hole and the center square.
+	assert.Len(t, byLayer[_layerFinder], 9)
+	// the bottom-left finder is at the bottom-right of mirrored matrix.
+	assert.True(t, inside(byLayer[_layerFinder], float64(n)-0.5, 0.5))
+	assert.False(t, inside(byLayer[_layerFinder], 0.5, 0.5))
+	assert.False(t, inside(byLayer[_layerData], 0.5, float64(n)-0.5))
+}
+
+func Test_Writer_ReflectanceReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("reversed", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width() + 2*quietZoneModules
+
+	// the dark quiet zone is cut with data, finder patterns are offset by it.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLayers())))
+	header, polylines := parse(t, buf.String())
+	assert.Equal(t, strconv.Itoa(n), header["$EXTMAX"])
+	byLayer := make(map[string][]polyline)
+	for _, pl := range polylines {
+		byLayer[pl.layer] = append(byLayer[pl.layer], pl)
+	}
+	assert.True(t, inside(byLayer[_layerData], 0.5, 0.5))
+	assert.False(t, inside(byLayer[_layerFinder], 0.5, 0.5))
+	// the top-left corner of the reversed finder pattern is light.
+	assert.False(t, inside(polylines, quietZoneModules+0.5, float64(n-quietZoneModules)-0.5))
+	assert.True(t, inside(byLayer[_layerFinder], quietZoneModules+1.5, float64(n-quietZoneModules)-1.5))
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorIs(t, New(&buf, WithLayers()).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+	assert.Zero(t, buf.Len())
+}
+
+// object is a record started by group code 0 in a section.
+type object struct {
+	section, typ string
+	pairs        [][2]string
+}
+
+// values returns values of group code in o.
+func (o object) values(code string) []string {
+	var out []string
+	for _, p := range o.pairs {
+		if p[0] == code {
+			out = append(out, p[1])
+		}
+	}
+
+	return out
+}
+
+// parseObjects splits DXF into objects of sections.
+func parseObjects(t *testing.T, dxf string) []object {
+	lines := strings.Split(strings.TrimSuffix(dxf, "\n"), "\n")
+	require.Zero(t, len(lines)%2)
+
+	var (
+		objects []object
+		section string
+	)
+	for i := 0; i < len(lines); i += 2 {
+		code, value := lines[i], lines[i+1]
+		if code == "0" {
+			objects = append(objects, object{section: section, typ: value})
+			continue
+		}
+
+		cur := &objects[len(objects)-1]
+		cur.pairs = append(cur.pairs, [2]string{code, value})
+		if cur.typ == "SECTION" && code == "2" {
+			section = value
+		}
+	}
+
+	return objects
+}
+
+func Test_Writer_Structure(t *testing.T) {
+	qrc, err := qrcode.New("strict readers")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLayers())))
+	out := buf.String()
+	header, _ := parse(t, out)
+	objects := parseObjects(t, out)
+
+	var sections []string
+	for _, o := range objects {
+		if o.typ == "SECTION" {
+			sections = append(sections, o.values("2")[0])
+		}
+	}
+	assert.Equal(t, []string{"HEADER", "CLASSES", "TABLES", "BLOCKS", "ENTITIES", "OBJECTS"}, sections)
+	assert.Equal(t, "EOF", objects[len(objects)-1].typ)
+
+	// every object has a unique handle less than $HANDSEED, owners are objects.
+	seed, err := strconv.ParseInt(header["$HANDSEED"], 16, 64)
+	require.NoError(t, err)
+	byHandle := make(map[string]object)
+	for _, o := range objects {
+		if o.section == "HEADER" || o.section == "CLASSES" {
+			continue
+		}
+		switch o.typ {
+		case "SECTION", "ENDSEC", "ENDTAB", "EOF":
+			continue
+		}
+
+		handles := append(o.values("5"), o.values("105")...)
+		require.Len(t, handles, 1, "%s in %s", o.typ, o.section)
+		h, err := strconv.ParseInt(handles[0], 16, 64)
+		require.NoError(t, err)
+		assert.Less(t, h, seed)
+		require.NotContains(t, byHandle, handles[0])
+		byHandle[handles[0]] = o
+	}
+	owner := func(o object) object {
+		owners := o.values("330")
+		require.Len(t, owners, 1, o.typ)
+		require.Contains(t, byHandle, owners[0], o.typ)
+		return byHandle[owners[0]]
+	}
+
+	tables := make(map[string]int)
+	blockRecords := make(map[string]string)
+	var layers []string
+	for h, o := range byHandle {
+		switch {
+		case o.typ == "TABLE":
+			assert.Equal(t, []string{"AcDbSymbolTable"}, o.values("100")[:1])
+			count, err := strconv.Atoi(o.values("70")[0])
+			require.NoError(t, err)
+			tables[o.values("2")[0]] = count
+		case o.section == "TABLES":
+			table := owner(o)
+			assert.Equal(t, "TABLE", table.typ)
+			assert.Equal(t, o.typ, table.values("2")[0])
+			assert.Equal(t, "AcDbSymbolTableRecord", o.values("100")[0])
+			if o.typ == "LAYER" {
+				assert.Equal(t, []string{"AcDbSymbolTableRecord", "AcDbLayerTableRecord"}, o.values("100"))
+				layers = append(layers, o.values("2")[0])
+			}
+			if o.typ == "BLOCK_RECORD" {
+				blockRecords[o.values("2")[0]] = h
+			}
+		case o.section == "BLOCKS":
+			assert.Equal(t, "BLOCK_RECORD", owner(o).typ)
+		case o.section == "ENTITIES":
+			assert.Equal(t, "*Model_Space", owner(o).values("2")[0])
+		}
+	}
+	assert.Equal(t, map[string]int{
+		"VPORT": 0, "LTYPE": 3, "LAYER": 3, "STYLE": 1, "VIEW": 0, "UCS": 0,
+		"APPID": 1, "DIMSTYLE": 1, "BLOCK_RECORD": 2,
+	}, tables)
+	assert.ElementsMatch(t, []string{"0", _layerFinder, _layerData}, layers)
+	assert.Len(t, blockRecords, 2)
+	assert.Contains(t, blockRecords, "*Model_Space")
+	assert.Contains(t, blockRecords, "*Paper_Space")
+
+	// the root dictionary is the first object.
+	var dictionaries []object
+	for _, o := range objects {
+		if o.section == "OBJECTS" && o.typ != "SECTION" && o.typ != "ENDSEC" && o.typ != "EOF" {
+			dictionaries = append(dictionaries, o)
+		}
+	}
+	require.Len(t, dictionaries, 2)
+	assert.Equal(t, []string{"0"}, dictionaries[0].values("330"))
+	assert.Equal(t, []string{"ACAD_GROUP"}, dictionaries[0].values("3"))
+	assert.Equal(t, dictionaries[0].values("350"), dictionaries[1].values("5"))
+	assert.Equal(t, dictionaries[0].values("5")[0], owner(dictionaries[1]).values("5")[0])
+}
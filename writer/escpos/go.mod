@@ -0,0 +1,15 @@
+module github.com/yeqown/go-qrcode/writer/escpos
+
+go 1.19
+
+require (
+	github.com/stretchr/testify v1.7.0
+	github.com/yeqown/go-qrcode/v2 v2.2.5
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/yeqown/reedsolomon v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
+)
@@ -0,0 +1,106 @@
+package escpos
+
+// Mode decides how the QR code is described to the printer.
+type Mode uint8
+
+const (
+	// ModeNative emits QR code commands (GS ( k) with the payload and the error correction
+	// level, so that the printer draws it. It's the default, the matrix is emitted as
+	// raster if the printer can't draw it.
+	ModeNative Mode = iota
+	// ModeRaster emits the matrix as raster bit image (GS v 0) at exact dots per module.
+	ModeRaster
+)
+
+// Paper is the width of receipt paper.
+type Paper uint8
+
+const (
+	// Paper80mm is the 80mm paper with 576 printable dots, it's the default.
+	Paper80mm Paper = iota
+	// Paper58mm is the 58mm paper with 384 printable dots.
+	Paper58mm
+)
+
+// dots returns printable dots of the paper in 203 dpi.
+func (p Paper) dots() int {
+	if p == Paper58mm {
+		return 384
+	}
+
+	return 576
+}
+
+const (
+	// _defaultDotsPerModule is about 0.5mm in 203 dpi of most thermal printers.
+	_defaultDotsPerModule = 4
+	// _maxNativeModuleDots is the max module size of GS ( k.
+	_maxNativeModuleDots = 16
+)
+
+// Option configures the receipt printer writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	mode          Mode
+	paper         Paper
+	dotsPerModule int
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		mode:          ModeNative,
+		paper:         Paper80mm,
+		dotsPerModule: _defaultDotsPerModule,
+	}
+}
+
+// WithMode sets how the QR code is described, ModeNative by default.
+func WithMode(mode Mode) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mode > ModeRaster {
+			return
+		}
+
+		o.mode = mode
+	})
+}
+
+// WithPaper sets the width of paper, the QR code is centered on it. Paper80mm by default.
+func WithPaper(paper Paper) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if paper > Paper58mm {
+			return
+		}
+
+		o.paper = paper
+	})
+}
+
+// WithDotsPerModule sets the size of module in dots, 4 by default. GS ( k supports at
+// most 16 dots per module, larger modules are emitted as raster in ModeNative.
+func WithDotsPerModule(n int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if n <= 0 {
+			return
+		}
+
+		o.dotsPerModule = n
+	})
+}
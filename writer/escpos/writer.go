@@ -0,0 +1,176 @@
+package escpos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+var (
+	// ErrNoPayload means the matrix is not built by QRCode, so it can't be described by
+	// GS ( k, ModeRaster works for any matrix.
+	ErrNoPayload = errors.New("escpos: matrix has no payload")
+	// ErrTooWide means the QR code in dots is wider than the paper.
+	ErrTooWide = errors.New("escpos: QR code is wider than paper")
+	// ErrEmptyMatrix means the matrix emitted as raster has no modules.
+	ErrEmptyMatrix = errors.New("escpos: empty matrix")
+)
+
+const (
+	esc = 0x1b
+	gs  = 0x1d
+
+	// quietZoneModules is the width of the quiet zone required by ISO/IEC 18004.
+	quietZoneModules = 4
+	// _maxRasterRows is the rows of each GS v 0 command, cheap printers can't buffer
+	// large images.
+	_maxRasterRows = 255
+)
+
+// Writer implements qrcode.Writer to describe QR code in ESC/POS commands of receipt
+// printers.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a receipt printer writer which writes commands into out, out is not
+// closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	// GS ( k of empty matrix fails with ErrNoPayload.
+	if !w.native(mat) && (mat.Width() == 0 || mat.Height() == 0) {
+		return ErrEmptyMatrix
+	}
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	if w.native(mat) {
+		err = w.writeNative(&buf, mat)
+	} else {
+		err = w.writeRaster(&buf, mat)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.out.Write(buf.Bytes())
+	return err
+}
+
+// native reports whether the matrix is described by GS ( k. Mirrored and reflectance
+// reversed matrix can't be drawn by printers, and GS ( k can't draw modules over
+// _maxNativeModuleDots dots, so they are emitted as raster.
+func (w *Writer) native(mat qrcode.Matrix) bool {
+	return w.option.mode == ModeNative && !mat.IsMirrored() && !mat.IsReflectanceReversed() &&
+		w.option.dotsPerModule <= _maxNativeModuleDots
+}
+
+// qrFunction writes GS ( k with cn=49 (QR code), fn and parameters.
+func qrFunction(buf *bytes.Buffer, fn byte, params ...byte) {
+	n := len(params) + 2
+	buf.Write([]byte{gs, '(', 'k', byte(n), byte(n >> 8), 49, fn})
+	buf.Write(params)
+}
+
+func (w *Writer) writeNative(buf *bytes.Buffer, mat qrcode.Matrix) error {
+	payload := mat.Payload()
+	if payload == nil {
+		return ErrNoPayload
+	}
+	ec, err := ecLevelCode(mat)
+	if err != nil {
+		return err
+	}
+
+	buf.Write([]byte{esc, 'a', 1}) // center
+	qrFunction(buf, 65, 50, 0)     // model 2
+	qrFunction(buf, 67, byte(w.option.dotsPerModule))
+	qrFunction(buf, 69, ec)
+	qrFunction(buf, 80, append([]byte{48}, payload...)...) // store
+	qrFunction(buf, 81, 48)                                // print
+	buf.WriteByte('\n')
+	buf.Write([]byte{esc, 'a', 0}) // left
+
+	return nil
+}
+
+// writeRaster writes the matrix as GS v 0 raster bit images, centered on the paper.
+// The dark quiet zone of reflectance reversed matrix is included, since the paper is
+// white.
+func (w *Writer) writeRaster(buf *bytes.Buffer, mat qrcode.Matrix) error {
+	qz := 0
+	if mat.IsReflectanceReversed() {
+		qz = quietZoneModules
+	}
+	modules := mat.BitmapWithQuietZone(qz)
+	dots := w.option.dotsPerModule
+	size := len(modules) * dots
+
+	paper := w.option.paper.dots()
+	if size > paper {
+		return fmt.Errorf("%w: %d dots > %d dots", ErrTooWide, size, paper)
+	}
+	left := (paper - size) / 2
+	bytesPerRow := (left + size + 7) / 8
+
+	for y0 := 0; y0 < size; y0 += _maxRasterRows {
+		rows := size - y0
+		if rows > _maxRasterRows {
+			rows = _maxRasterRows
+		}
+
+		buf.Write([]byte{gs, 'v', '0', 0,
+			byte(bytesPerRow), byte(bytesPerRow >> 8), byte(rows), byte(rows >> 8)})
+		raster := make([]byte, bytesPerRow*rows)
+		for y := 0; y < rows; y++ {
+			for x := 0; x < size; x++ {
+				if modules[(y0+y)/dots][x/dots] {
+					px := left + x
+					raster[y*bytesPerRow+px/8] |= 0x80 >> (px % 8)
+				}
+			}
+		}
+		buf.Write(raster)
+	}
+
+	return nil
+}
+
+// ecLevelCode returns the error correction level in GS ( k.
+func ecLevelCode(mat qrcode.Matrix) (byte, error) {
+	switch mat.ErrorCorrectionLevel() {
+	case qrcode.ErrorCorrectionLow:
+		return 48, nil
+	case qrcode.ErrorCorrectionMedium:
+		return 49, nil
+	case qrcode.ErrorCorrectionQuart:
+		return 50, nil
+	case qrcode.ErrorCorrectionHighest:
+		return 51, nil
+	}
+
+	return 0, errors.New("escpos: unknown error correction level")
+}
@@ -0,0 +1,141 @@
+package escpos
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Writer_Native(t *testing.T) {
+	qrc, err := qrcode.NewWith("https://pay", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	want := []byte{
+		0x1b, 'a', 1,
+		0x1d, '(', 'k', 4, 0, 49, 65, 50, 0,
+		0x1d, '(', 'k', 3, 0, 49, 67, 6,
+		0x1d, '(', 'k', 3, 0, 49, 69, 51,
+		0x1d, '(', 'k', 14, 0, 49, 80, 48,
+	}
+	want = append(want, "https://pay"...)
+	want = append(want,
+		0x1d, '(', 'k', 3, 0, 49, 81, 48,
+		'\n',
+		0x1b, 'a', 0,
+	)
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(6))))
+	assert.Equal(t, want, buf.Bytes())
+}
+
+// decodeRaster returns dots of GS v 0 commands in out.
+func decodeRaster(t *testing.T, out []byte) (bytesPerRow int, rows [][]byte) {
+	for len(out) > 0 {
+		require.True(t, len(out) >= 8)
+		require.Equal(t, []byte{0x1d, 'v', '0', 0}, out[:4])
+		bytesPerRow = int(out[4]) | int(out[5])<<8
+		n := int(out[6]) | int(out[7])<<8
+		out = out[8:]
+		for y := 0; y < n; y++ {
+			rows = append(rows, out[:bytesPerRow])
+			out = out[bytesPerRow:]
+		}
+	}
+
+	return bytesPerRow, rows
+}
+
+func Test_Writer_Raster(t *testing.T) {
+	qrc, err := qrcode.NewWith("receipt raster mode", qrcode.WithVersion(10))
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	bm := mat.Bitmap()
+
+	const dots = 5
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithMode(ModeRaster), WithPaper(Paper58mm), WithDotsPerModule(dots))))
+	bytesPerRow, rows := decodeRaster(t, buf.Bytes())
+
+	size := len(bm) * dots
+	left := (384 - size) / 2
+	require.Len(t, rows, size)
+	// more than one command for cheap printers.
+	assert.Greater(t, size, _maxRasterRows)
+	assert.Equal(t, (left+size+7)/8, bytesPerRow)
+
+	for y, row := range rows {
+		for x := 0; x < bytesPerRow*8; x++ {
+			dot := row[x/8]&(0x80>>(x%8)) != 0
+			want := x >= left && x < left+size && bm[y/dots][(x-left)/dots]
+			require.Equal(t, want, dot, "dot (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_ReflectanceReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("reversed", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// printers can't draw reversed QR code, so it's emitted as raster with dark quiet zone.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(1))))
+	_, rows := decodeRaster(t, buf.Bytes())
+	assert.Len(t, rows, mat.Width()+2*quietZoneModules)
+}
+
+func Test_Writer_NativeFallback(t *testing.T) {
+	qrc, err := qrcode.New("large modules")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// GS ( k draws at most 16 dots per module, larger modules are not shrunk.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(_maxNativeModuleDots+1))))
+	_, rows := decodeRaster(t, buf.Bytes())
+	assert.Len(t, rows, mat.Width()*(_maxNativeModuleDots+1))
+
+	buf.Reset()
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(_maxNativeModuleDots))))
+	assert.Contains(t, buf.String(), string([]byte{0x1d, '(', 'k', 3, 0, 49, 67, _maxNativeModuleDots}))
+}
+
+func Test_Writer_Mirrored(t *testing.T) {
+	qrc, err := qrcode.NewWith("mirrored", qrcode.WithMirror())
+	require.NoError(t, err)
+	normal, err := qrcode.New("mirrored")
+	require.NoError(t, err)
+	mat := normal.Matrix()
+	bm := mat.Bitmap()
+
+	// printers can't mirror GS ( k, dots of the raster are mirrored modules.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithPaper(Paper58mm), WithDotsPerModule(3))))
+	_, rows := decodeRaster(t, buf.Bytes())
+	size := len(bm) * 3
+	left := (384 - size) / 2
+	require.Len(t, rows, size)
+	for y, row := range rows {
+		for x := left; x < left+size; x++ {
+			dot := row[x/8]&(0x80>>(x%8)) != 0
+			require.Equal(t, bm[y/3][len(bm)-1-(x-left)/3], dot, "dot (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_Errors(t *testing.T) {
+	// GS ( k needs the payload, raster needs modules.
+	var buf bytes.Buffer
+	assert.ErrorIs(t, New(&buf).Write(qrcode.Matrix{}), ErrNoPayload)
+	assert.ErrorIs(t, New(&buf, WithMode(ModeRaster)).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+
+	qrc, err := qrcode.New("too wide")
+	require.NoError(t, err)
+	err = New(&buf, WithMode(ModeRaster), WithPaper(Paper58mm), WithDotsPerModule(20)).Write(qrc.Matrix())
+	assert.ErrorIs(t, err, ErrTooWide)
+	assert.Zero(t, buf.Len())
+}
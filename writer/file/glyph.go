@@ -0,0 +1,80 @@
+package file
+
+// Glyphs is the set of characters to draw modules.
+type Glyphs uint8
+
+const (
+	// GlyphsHalfBlock draws 1×2 modules in one character by ▀, ▄, █ and space, it's
+	// the default.
+	GlyphsHalfBlock Glyphs = iota
+	// GlyphsFullBlock draws a module by two characters (██), which looks square in most
+	// monospaced fonts.
+	GlyphsFullBlock
+	// GlyphsQuadrant draws 2×2 modules in one character by Unicode quadrant blocks
+	// (▘▝▖▗▚▞ ...).
+	GlyphsQuadrant
+	// GlyphsBraille draws 2×4 modules in one character by Braille patterns, it's the
+	// most compact but some fonts leave gaps between dots.
+	GlyphsBraille
+	// GlyphsASCII draws a module by "##" for systems without Unicode.
+	GlyphsASCII
+)
+
+// glyphSet draws cells of cellWidth×cellHeight modules by a string each.
+type glyphSet struct {
+	cellWidth, cellHeight int
+	// glyph returns the string of the cell, bit (dy*cellWidth + dx) of ink is set if the
+	// module at (dx, dy) in the cell is drawn.
+	glyph func(ink uint) string
+}
+
+var halfBlockRunes = [4]rune{' ', '▀', '▄', '█'}
+
+var quadrantRunes = [16]rune{
+	' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛', '▗', '▚', '▐', '▜', '▄', '▙', '▟', '█',
+}
+
+// brailleDots maps the bit of module in the cell to the dot of Braille pattern:
+//
+//	1 4
+//	2 5
+//	3 6
+//	7 8
+var brailleDots = [8]rune{0x01, 0x08, 0x02, 0x10, 0x04, 0x20, 0x40, 0x80}
+
+func (g Glyphs) set() glyphSet {
+	switch g {
+	case GlyphsFullBlock:
+		return glyphSet{cellWidth: 1, cellHeight: 1, glyph: func(ink uint) string {
+			if ink != 0 {
+				return "██"
+			}
+			return "  "
+		}}
+	case GlyphsQuadrant:
+		return glyphSet{cellWidth: 2, cellHeight: 2, glyph: func(ink uint) string {
+			return string(quadrantRunes[ink])
+		}}
+	case GlyphsBraille:
+		return glyphSet{cellWidth: 2, cellHeight: 4, glyph: func(ink uint) string {
+			r := rune(0x2800)
+			for i, dot := range brailleDots {
+				if ink&(1<<i) != 0 {
+					r |= dot
+				}
+			}
+			return string(r)
+		}}
+	case GlyphsASCII:
+		return glyphSet{cellWidth: 1, cellHeight: 1, glyph: func(ink uint) string {
+			if ink != 0 {
+				return "##"
+			}
+			return "  "
+		}}
+	default:
+		return glyphSet{cellWidth: 1, cellHeight: 2, glyph: func(ink uint) string {
+			return string(halfBlockRunes[ink])
+		}}
+	}
+}
@@ -0,0 +1,77 @@
+package file
+
+// Option configures the file writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	glyphs Glyphs
+	// invert draws light modules instead of dark ones.
+	invert bool
+	// quietZone is the width of quiet zone in modules, -1 means it's not set.
+	quietZone int
+	// trim removes blank characters at the end of lines.
+	trim bool
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		glyphs:    GlyphsHalfBlock,
+		quietZone: -1,
+	}
+}
+
+// WithGlyphs sets the characters to draw modules, GlyphsHalfBlock by default.
+func WithGlyphs(g Glyphs) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if g > GlyphsASCII {
+			return
+		}
+
+		o.glyphs = g
+	})
+}
+
+// WithInvert draws light modules (and the quiet zone) instead of dark ones, so that the
+// QR code is readable as light text on dark background.
+func WithInvert() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.invert = true
+	})
+}
+
+// WithQuietZone sets the width of quiet zone in modules. By default, there is no quiet
+// zone for normal QR code, since it's left to the blank around, and 4 modules if the
+// quiet zone is drawn: the inverted or the reflectance reversed one (not both).
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithTrimTrailingSpaces removes blank characters at the end of each line, which keeps
+// the output clean in emails, READMEs and logs.
+func WithTrimTrailingSpaces() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.trim = true
+	})
+}
@@ -1,80 +1,98 @@
 package file
 
 import (
+	"bufio"
 	"errors"
-	"os"
+	"io"
+	"strings"
 
 	"github.com/yeqown/go-qrcode/v2"
 )
 
-const (
-	upRune     = 9600 // '▀'
-	downRune   = 9604 // '▄'
-	upDownRune = 9608 // '█'
-	spaceRune  = 32   // ' '
-)
-
 var _ qrcode.Writer = (*Writer)(nil)
 
-// Writer implements qrcode.Writer.
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("file: empty matrix")
+
+// Writer implements qrcode.Writer to draw QR code by characters into any io.Writer,
+// such as a file, os.Stdout, or a strings.Builder.
 type Writer struct {
-	out *os.File
+	out    io.Writer
+	option *outputOptions
 }
 
-// Close method to implement qrcode.Writer.
+// Close method to implement qrcode.Writer, the output writer is not closed.
 func (a *Writer) Close() error { return nil }
 
 // Write method to implement qrcode.Writer.
 func (a *Writer) Write(mat qrcode.Matrix) error {
 	if a.out == nil {
-		return errors.New("nil file")
+		return errors.New("nil writer")
 	}
-
-	bm := mat.Bitmap()
-
-	output := make([][]string, len(bm)/2+len(bm)%2)
-	for t := range output {
-		output[t] = make([]string, len(bm[0]))
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
 	}
 
-	for col := range output {
-		for row := range output[col] {
-			var selectedRune rune = spaceRune
-
-			if bm[col*2][row] {
-				selectedRune = upRune
-			}
-
-			if col*2+1 < len(bm) {
-				if bm[col*2+1][row] && !bm[col*2][row] {
-					selectedRune = downRune
-				}
-
-				if bm[col*2+1][row] && bm[col*2][row] {
-					selectedRune = upDownRune
+	ink := a.ink(mat)
+	set := a.option.glyphs.set()
+	bw := bufio.NewWriter(a.out)
+
+	var line strings.Builder
+	for y := 0; y < len(ink); y += set.cellHeight {
+		line.Reset()
+		for x := 0; x < len(ink[0]); x += set.cellWidth {
+			var bits uint
+			for dy := 0; dy < set.cellHeight && y+dy < len(ink); dy++ {
+				for dx := 0; dx < set.cellWidth && x+dx < len(ink[0]); dx++ {
+					if ink[y+dy][x+dx] {
+						bits |= 1 << (dy*set.cellWidth + dx)
+					}
 				}
 			}
+			line.WriteString(set.glyph(bits))
+		}
 
-			output[col][row] = string(selectedRune)
+		s := line.String()
+		if a.option.trim {
+			s = strings.TrimRight(s, " ⠀")
+		}
+		if _, err := bw.WriteString(s + "\n"); err != nil {
+			return err
 		}
 	}
 
-	for _, col := range output {
-		for _, row := range col {
-			_, err := a.out.WriteString(row)
-			if err != nil {
-				return err
-			}
+	return bw.Flush()
+}
+
+// quietZoneModules is the width of the quiet zone required by ISO/IEC 18004.
+const quietZoneModules = 4
+
+// ink returns whether the module at (x, y) is drawn, the quiet zone is included.
+func (a *Writer) ink(mat qrcode.Matrix) [][]bool {
+	qz := a.option.quietZone
+	if qz < 0 {
+		// the quiet zone must be drawn if it's inked (inverted or reflectance reversed).
+		qz = 0
+		if mat.IsReflectanceReversed() != a.option.invert {
+			qz = quietZoneModules
 		}
-		_, err := a.out.WriteString("\n")
-		if err != nil {
-			return err
+	}
+	modules := mat.BitmapWithQuietZone(qz)
+	for _, row := range modules {
+		for x := range row {
+			row[x] = row[x] != a.option.invert
 		}
 	}
 
-	return nil
+	return modules
 }
 
-func New(f *os.File) *Writer {
-	return &Writer{out: f}
+// New creates a writer which draws QR code into out, *os.File is accepted as before.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
 }
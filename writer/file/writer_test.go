@@ -0,0 +1,250 @@
+package file
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cellModules maps each character to the inked modules in its cell, (dx, dy) pairs.
+var cellModules = map[Glyphs]map[rune][][2]int{
+	GlyphsHalfBlock: {
+		' ': nil, '▀': {{0, 0}}, '▄': {{0, 1}}, '█': {{0, 0}, {0, 1}},
+	},
+	GlyphsQuadrant: {
+		' ': nil,
+		'▘': {{0, 0}}, '▝': {{1, 0}}, '▖': {{0, 1}}, '▗': {{1, 1}},
+		'▀': {{0, 0}, {1, 0}}, '▄': {{0, 1}, {1, 1}}, '▌': {{0, 0}, {0, 1}}, '▐': {{1, 0}, {1, 1}},
+		'▚': {{0, 0}, {1, 1}}, '▞': {{1, 0}, {0, 1}},
+		'▛': {{0, 0}, {1, 0}, {0, 1}}, '▜': {{0, 0}, {1, 0}, {1, 1}},
+		'▙': {{0, 0}, {0, 1}, {1, 1}}, '▟': {{1, 0}, {0, 1}, {1, 1}},
+		'█': {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+	},
+}
+
+// brailleModules are the modules of Braille dots 1 to 8.
+var brailleModules = [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+// decode draws the output back into modules, cells left out by trimming are blank.
+func decode(t *testing.T, out string, g Glyphs, width, height int) [][]bool {
+	modules := make([][]bool, height)
+	for y := range modules {
+		modules[y] = make([]bool, width)
+	}
+	ink := func(x, y int) {
+		require.Less(t, y, height)
+		require.Less(t, x, width)
+		modules[y][x] = true
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	for i, line := range lines {
+		switch g {
+		case GlyphsFullBlock, GlyphsASCII:
+			for x := 0; line != ""; x++ {
+				switch {
+				case strings.HasPrefix(line, "██"):
+					ink(x, i)
+					line = line[len("██"):]
+				case strings.HasPrefix(line, "##"):
+					ink(x, i)
+					line = line[2:]
+				case strings.HasPrefix(line, "  "):
+					line = line[2:]
+				default:
+					t.Fatalf("unexpected characters %q", line)
+				}
+			}
+		case GlyphsBraille:
+			for x, r := range []rune(line) {
+				require.True(t, r >= 0x2800 && r <= 0x28ff, "unexpected rune %q", r)
+				for dot, m := range brailleModules {
+					if (r-0x2800)&(1<<dot) != 0 {
+						ink(2*x+m[0], 4*i+m[1])
+					}
+				}
+			}
+		default:
+			w, h := 1, 2
+			if g == GlyphsQuadrant {
+				w = 2
+			}
+			for x, r := range []rune(line) {
+				cell, ok := cellModules[g][r]
+				require.True(t, ok, "unexpected rune %q", r)
+				for _, m := range cell {
+					ink(w*x+m[0], h*i+m[1])
+				}
+			}
+		}
+	}
+
+	return modules
+}
+
+// expected returns modules of mat with quiet zone of qz modules, the ones of quiet zone
+// are set to quietZone.
+func expected(mat qrcode.Matrix, qz int, quietZone bool) [][]bool {
+	bm := mat.Bitmap()
+	rows := make([][]bool, len(bm)+2*qz)
+	for y := range rows {
+		rows[y] = make([]bool, len(bm)+2*qz)
+		for x := range rows[y] {
+			rows[y][x] = quietZone
+			if y >= qz && y < qz+len(bm) && x >= qz && x < qz+len(bm) {
+				rows[y][x] = bm[y-qz][x-qz]
+			}
+		}
+	}
+
+	return rows
+}
+
+func invert(rows [][]bool) [][]bool {
+	out := make([][]bool, len(rows))
+	for y := range rows {
+		out[y] = make([]bool, len(rows[y]))
+		for x := range rows[y] {
+			out[y][x] = !rows[y][x]
+		}
+	}
+
+	return out
+}
+
+func Test_Writer_Glyphs(t *testing.T) {
+	qrc, err := qrcode.New("file writer glyphs")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	glyphs := []Glyphs{GlyphsHalfBlock, GlyphsFullBlock, GlyphsQuadrant, GlyphsBraille, GlyphsASCII}
+	for _, g := range glyphs {
+		for _, trim := range []bool{false, true} {
+			opts := []Option{WithGlyphs(g), WithQuietZone(1)}
+			if trim {
+				opts = append(opts, WithTrimTrailingSpaces())
+			}
+			var buf bytes.Buffer
+			require.NoError(t, New(&buf, opts...).Write(mat))
+			out := buf.String()
+			if trim {
+				for _, line := range strings.Split(out, "\n") {
+					assert.False(t, strings.HasSuffix(line, " ") || strings.HasSuffix(line, "⠀"),
+						"glyphs %d: trailing blank in %q", g, line)
+				}
+			}
+
+			// cells out of the code (in the last row or column) are blank.
+			want := expected(mat, 1, false)
+			got := decode(t, out, g, len(want)+1, len(want)+3)
+			for y := range got {
+				for x := range got[y] {
+					inked := y < len(want) && x < len(want) && want[y][x]
+					require.Equal(t, inked, got[y][x], "glyphs %d, trim %v: module (%d, %d)", g, trim, x, y)
+				}
+			}
+		}
+	}
+}
+
+func Test_Writer_Invert(t *testing.T) {
+	qrc, err := qrcode.New("file writer invert")
+	require.NoError(t, err)
+	reversed, err := qrcode.NewWith("file writer invert", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		qrc  *qrcode.QRCode
+		opts []Option
+		want func(mat qrcode.Matrix) [][]bool
+	}{
+		{
+			// light modules and the quiet zone (4 modules by default) are inked.
+			name: "inverted",
+			qrc:  qrc,
+			opts: []Option{WithInvert()},
+			want: func(mat qrcode.Matrix) [][]bool { return invert(expected(mat, quietZoneModules, false)) },
+		},
+		{
+			// no quiet zone is drawn for normal QR code by default.
+			name: "normal",
+			qrc:  qrc,
+			want: func(mat qrcode.Matrix) [][]bool { return expected(mat, 0, false) },
+		},
+		{
+			// the dark quiet zone of reflectance reversed matrix is inked, unless inverted.
+			name: "reversed",
+			qrc:  reversed,
+			want: func(mat qrcode.Matrix) [][]bool { return expected(mat, quietZoneModules, true) },
+		},
+		{
+			name: "reversed and inverted",
+			qrc:  reversed,
+			opts: []Option{WithInvert()},
+			want: func(mat qrcode.Matrix) [][]bool { return invert(expected(mat, 0, true)) },
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, c.qrc.Save(New(&buf, append(c.opts, WithGlyphs(GlyphsFullBlock))...)))
+			want := c.want(c.qrc.Matrix())
+			assert.Equal(t, want, decode(t, buf.String(), GlyphsFullBlock, len(want), len(want)))
+		})
+	}
+}
+
+func Test_Writer_Mirrored(t *testing.T) {
+	qrc, err := qrcode.NewWith("file writer mirrored", qrcode.WithMirror())
+	require.NoError(t, err)
+	normal, err := qrcode.New("file writer mirrored")
+	require.NoError(t, err)
+
+	// quadrant cells of mirrored matrix are the cells of normal matrix read from right to
+	// left, the odd width leaves a blank column at the right.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithGlyphs(GlyphsQuadrant), WithQuietZone(0))))
+	want := expected(normal.Matrix(), 0, false)
+	got := decode(t, buf.String(), GlyphsQuadrant, len(want)+1, len(want)+1)
+	for y := range want {
+		for x := range want[y] {
+			require.Equal(t, want[y][len(want)-1-x], got[y][x], "module (%d, %d)", x, y)
+		}
+		assert.False(t, got[y][len(want)])
+	}
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorIs(t, New(&buf, WithInvert()).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+	assert.Zero(t, buf.Len())
+}
+
+func Test_New_File(t *testing.T) {
+	qrc, err := qrcode.New("file writer os.File")
+	require.NoError(t, err)
+
+	// *os.File is written as before, the default is half blocks without quiet zone.
+	name := filepath.Join(t.TempDir(), "qrcode.txt")
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	require.NoError(t, qrc.Save(New(f)))
+	require.NoError(t, f.Close())
+
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	want := expected(qrc.Matrix(), 0, false)
+	assert.Equal(t, want, decode(t, string(data), GlyphsHalfBlock, len(want), len(want)+1)[:len(want)])
+
+	// a nil file fails rather than panics.
+	assert.Error(t, New((*os.File)(nil)).Write(qrc.Matrix()))
+	assert.EqualError(t, New(nil).Write(qrc.Matrix()), "nil writer")
+}
@@ -0,0 +1,187 @@
+package gcode
+
+// Strategy decides how modules are machined.
+type Strategy uint8
+
+const (
+	// StrategyRaster fills marked modules by scan lines, runs of each line are merged.
+	// It's for laser engravers, and it's the default.
+	StrategyRaster Strategy = iota
+	// StrategyContour cuts the outlines of marked areas, it's for routers and laser
+	// cutting.
+	StrategyContour
+)
+
+// Format is the output format.
+type Format uint8
+
+const (
+	// FormatGCode writes G-code (mm, absolute coordinates), it's the default.
+	FormatGCode Format = iota
+	// FormatSVG writes SVG in mm for laser software. Marked areas are filled for
+	// StrategyRaster, and outlined by hairlines for StrategyContour.
+	FormatSVG
+)
+
+const (
+	_defaultModuleSize  = 1.0
+	_defaultLineSpacing = 0.1
+	_defaultPower       = 1000
+	_defaultFeedRate    = 1500
+	_defaultPlungeRate  = 100
+	_defaultSafeZ       = 5
+	_defaultCutZ        = -0.2
+	// quietZoneModules is the width of the quiet zone required by ISO/IEC 18004.
+	quietZoneModules = 4
+)
+
+// Option configures the G-code writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	strategy Strategy
+	format   Format
+
+	// moduleSize is the size of each module in mm.
+	moduleSize float64
+	// originX and originY are the position of bottom-left corner in mm.
+	originX, originY float64
+	// invert marks light modules and the quiet zone instead of dark ones.
+	invert bool
+	// quietZone is the width of quiet zone in modules, -1 means it's not set.
+	quietZone int
+
+	// lineSpacing, power and feedRate are settings of laser.
+	lineSpacing float64
+	power       int
+	feedRate    float64
+
+	// safeZ, cutZ and plungeRate are settings of router.
+	safeZ, cutZ float64
+	plungeRate  float64
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		strategy:    StrategyRaster,
+		format:      FormatGCode,
+		moduleSize:  _defaultModuleSize,
+		quietZone:   -1,
+		lineSpacing: _defaultLineSpacing,
+		power:       _defaultPower,
+		feedRate:    _defaultFeedRate,
+		safeZ:       _defaultSafeZ,
+		cutZ:        _defaultCutZ,
+		plungeRate:  _defaultPlungeRate,
+	}
+}
+
+// WithStrategy sets how modules are machined, StrategyRaster by default.
+func WithStrategy(s Strategy) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if s > StrategyContour {
+			return
+		}
+
+		o.strategy = s
+	})
+}
+
+// WithFormat sets the output format, FormatGCode by default.
+func WithFormat(f Format) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if f > FormatSVG {
+			return
+		}
+
+		o.format = f
+	})
+}
+
+// WithModuleSize sets the physical size of each module in mm, 1mm by default.
+func WithModuleSize(mm float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mm <= 0 {
+			return
+		}
+
+		o.moduleSize = mm
+	})
+}
+
+// WithOrigin sets the position of the bottom-left corner of the QR code in mm.
+func WithOrigin(x, y float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.originX, o.originY = x, y
+	})
+}
+
+// WithInvert marks light modules and the quiet zone instead of dark ones, for materials
+// which turn light when they are marked, such as anodized aluminium.
+func WithInvert() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.invert = true
+	})
+}
+
+// WithQuietZone sets the width of quiet zone in modules. By default, it's 4 modules if
+// the quiet zone is marked (inverted or reflectance reversed), otherwise there is none,
+// since it's left to the blank material.
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithLaser sets the power (S value, 1000 by default), the feed rate in mm/min (1500 by
+// default) and the spacing of scan lines in mm (0.1mm by default) of laser engraving.
+// The spacing is at most the module size when engraving.
+func WithLaser(power int, feedRate, lineSpacing float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if power > 0 {
+			o.power = power
+		}
+		if feedRate > 0 {
+			o.feedRate = feedRate
+		}
+		if lineSpacing > 0 {
+			o.lineSpacing = lineSpacing
+		}
+	})
+}
+
+// WithRouter sets the safe height and cutting depth in mm (5mm and -0.2mm by default),
+// and the plunge rate in mm/min (100 by default) of routers. The feed rate is shared
+// with WithLaser.
+func WithRouter(safeZ, cutZ, plungeRate float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if safeZ <= cutZ {
+			return
+		}
+
+		o.safeZ, o.cutZ = safeZ, cutZ
+		if plungeRate > 0 {
+			o.plungeRate = plungeRate
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package gcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("gcode: empty matrix")
+
+// Writer implements qrcode.Writer to turn QR code into toolpaths of laser engravers and
+// CNC routers.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a G-code writer which writes into out, out is not closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+
+	modules := w.marked(mat)
+	bw := bufio.NewWriter(w.out)
+	switch {
+	case w.option.format == FormatSVG:
+		w.writeSVG(bw, modules)
+	case w.option.strategy == StrategyContour:
+		w.writeContour(bw, modules)
+	default:
+		w.writeRaster(bw, modules)
+	}
+
+	return bw.Flush()
+}
+
+// marked returns whether the module at (x, y) is marked, the quiet zone is included.
+func (w *Writer) marked(mat qrcode.Matrix) [][]bool {
+	qz := w.option.quietZone
+	if qz < 0 {
+		// the quiet zone must be drawn if it's marked (inverted or reflectance reversed).
+		qz = 0
+		if mat.IsReflectanceReversed() != w.option.invert {
+			qz = quietZoneModules
+		}
+	}
+	modules := mat.BitmapWithQuietZone(qz)
+	for _, row := range modules {
+		for x := range row {
+			row[x] = row[x] != w.option.invert
+		}
+	}
+
+	return modules
+}
+
+// mm formats v in mm with 3 decimals, trailing zeros are removed.
+func mm(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+
+	return s
+}
+
+// machineX and machineY convert the corner of modules into machine coordinates, the Y
+// axis of machines is up.
+func (w *Writer) machineX(x float64) float64 {
+	return w.option.originX + x*w.option.moduleSize
+}
+
+func (w *Writer) machineY(y float64, rows int) float64 {
+	return w.option.originY + (float64(rows)-y)*w.option.moduleSize
+}
+
+// writeRaster fills marked modules by horizontal scan lines. Lines go back and forth,
+// the laser is off in G0 moves and on in G1 moves. Lines are at most a module apart, so
+// that no row of modules is skipped.
+func (w *Writer) writeRaster(bw *bufio.Writer, modules [][]bool) {
+	o := w.option
+	rows := len(modules)
+	height := float64(rows) * o.moduleSize
+	spacing := math.Min(o.lineSpacing, o.moduleSize)
+
+	fmt.Fprintf(bw, "; go-qrcode raster %dx%d modules, %smm per module\n", rows, rows, mm(o.moduleSize))
+	bw.WriteString("G21\nG90\nM4 S0\n")
+	forward := true
+	for i := 0; (float64(i)+0.5)*spacing < height; i++ {
+		// distance of the scan line from the top edge, in modules.
+		top := (float64(i) + 0.5) * spacing / o.moduleSize
+		row := modules[int(top)]
+		y := mm(w.machineY(top, rows))
+
+		type run struct{ from, to int }
+		var runs []run
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			runs = append(runs, run{from: start, to: x})
+		}
+		if len(runs) == 0 {
+			continue
+		}
+
+		if !forward {
+			for l, r := 0, len(runs)-1; l < r; l, r = l+1, r-1 {
+				runs[l], runs[r] = runs[r], runs[l]
+			}
+			for j := range runs {
+				runs[j].from, runs[j].to = runs[j].to, runs[j].from
+			}
+		}
+		for _, r := range runs {
+			fmt.Fprintf(bw, "G0 X%s Y%s\n", mm(w.machineX(float64(r.from))), y)
+			fmt.Fprintf(bw, "G1 X%s S%d F%s\n", mm(w.machineX(float64(r.to))), o.power, mm(o.feedRate))
+		}
+		forward = !forward
+	}
+	bw.WriteString("M5\n")
+	fmt.Fprintf(bw, "G0 X%s Y%s\n", mm(o.originX), mm(o.originY))
+}
+
+// writeContour cuts outlines of marked areas, the tool is lifted to safe Z between
+// loops.
+func (w *Writer) writeContour(bw *bufio.Writer, modules [][]bool) {
+	o := w.option
+	rows := len(modules)
+
+	fmt.Fprintf(bw, "; go-qrcode contour %dx%d modules, %smm per module\n", rows, rows, mm(o.moduleSize))
+	fmt.Fprintf(bw, "G21\nG90\nG0 Z%s\nM3\n", mm(o.safeZ))
+	for _, loop := range qrcode.BitmapOutlines(modules) {
+		at := func(p image.Point) string {
+			return "X" + mm(w.machineX(float64(p.X))) + " Y" + mm(w.machineY(float64(p.Y), rows))
+		}
+
+		fmt.Fprintf(bw, "G0 %s\n", at(loop[0]))
+		fmt.Fprintf(bw, "G1 Z%s F%s\n", mm(o.cutZ), mm(o.plungeRate))
+		for i, p := range append(loop[1:], loop[0]) {
+			if i == 0 {
+				fmt.Fprintf(bw, "G1 %s F%s\n", at(p), mm(o.feedRate))
+				continue
+			}
+			fmt.Fprintf(bw, "G1 %s\n", at(p))
+		}
+		fmt.Fprintf(bw, "G0 Z%s\n", mm(o.safeZ))
+	}
+	bw.WriteString("M5\n")
+	fmt.Fprintf(bw, "G0 X%s Y%s\n", mm(o.originX), mm(o.originY))
+}
+
+// writeSVG writes outlines of marked areas in SVG, the user unit is a module and the
+// document is sized in mm. The origin is left to laser software.
+func (w *Writer) writeSVG(bw *bufio.Writer, modules [][]bool) {
+	o := w.option
+	n := len(modules)
+	size := mm(float64(n) * o.moduleSize)
+
+	var d strings.Builder
+	for _, loop := range qrcode.BitmapOutlines(modules) {
+		for i, p := range loop {
+			cmd := "L"
+			if i == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&d, "%s%d %d", cmd, p.X, p.Y)
+		}
+		d.WriteString("Z")
+	}
+
+	style := `fill="#000" fill-rule="evenodd"`
+	if o.strategy == StrategyContour {
+		// hairline of 0.01mm, which is cut by most laser software.
+		style = `fill="none" stroke="#000" stroke-width="` + strconv.FormatFloat(0.01/o.moduleSize, 'g', 4, 64) + `"`
+	}
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %d %d">`+"\n",
+		size, size, n, n)
+	fmt.Fprintf(bw, `<path d="%s" %s/>`+"\n", d.String(), style)
+	bw.WriteString("</svg>\n")
+}
@@ -0,0 +1,226 @@
+package gcode
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var word = regexp.MustCompile(`([GXYZSF])(-?[0-9.]+)`)
+
+// move is a parsed G0 / G1 line, fields which are not set keep the last values.
+type move struct {
+	g          int
+	x, y, z, s float64
+}
+
+func parseMoves(t *testing.T, gcode string) []move {
+	var (
+		moves []move
+		cur   move
+	)
+	sc := bufio.NewScanner(strings.NewReader(gcode))
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "G0 ") && !strings.HasPrefix(line, "G1 ") {
+			continue
+		}
+		for _, m := range word.FindAllStringSubmatch(line, -1) {
+			v, err := strconv.ParseFloat(m[2], 64)
+			require.NoError(t, err)
+			switch m[1] {
+			case "G":
+				cur.g = int(v)
+			case "X":
+				cur.x = v
+			case "Y":
+				cur.y = v
+			case "Z":
+				cur.z = v
+			case "S":
+				cur.s = v
+			}
+		}
+		moves = append(moves, cur)
+	}
+
+	return moves
+}
+
+// rasterBitmap marks modules which are burned by G1 moves, the module size is 1mm and
+// the origin is (ox, oy).
+func rasterBitmap(t *testing.T, gcode string, n int, ox, oy float64) [][]bool {
+	bm := make([][]bool, n)
+	for i := range bm {
+		bm[i] = make([]bool, n)
+	}
+
+	moves := parseMoves(t, gcode)
+	for i := 1; i < len(moves); i++ {
+		from, to := moves[i-1], moves[i]
+		if to.g != 1 || to.s == 0 {
+			continue
+		}
+		require.Equal(t, from.y, to.y, "scan lines are horizontal")
+		row := int(float64(n) - (to.y - oy))
+		x0, x1 := math.Min(from.x, to.x)-ox, math.Max(from.x, to.x)-ox
+		for x := int(math.Round(x0)); x < int(math.Round(x1)); x++ {
+			bm[row][x] = true
+		}
+	}
+
+	return bm
+}
+
+func Test_Writer_Raster(t *testing.T) {
+	qrc, err := qrcode.New("engrave me")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithOrigin(10, 20), WithLaser(800, 2000, 0.25))))
+	assert.Contains(t, buf.String(), "S800 F2000")
+	assert.Equal(t, mat.Bitmap(), rasterBitmap(t, buf.String(), mat.Width(), 10, 20))
+}
+
+func Test_Writer_Raster_WideLineSpacing(t *testing.T) {
+	qrc, err := qrcode.New("engrave me")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// lines 1.5mm apart would skip rows of 1mm modules, so they are a module apart.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLaser(0, 0, 1.5))))
+	assert.Equal(t, mat.Bitmap(), rasterBitmap(t, buf.String(), mat.Width(), 0, 0))
+	ys := make(map[float64]bool)
+	for _, m := range parseMoves(t, buf.String()) {
+		ys[m.y] = true
+	}
+	// lines are at the middle of rows, and the origin is the last move.
+	for y := range ys {
+		assert.True(t, y == 0 || y-math.Floor(y) == 0.5, "line at Y%v", y)
+	}
+}
+
+func Test_Writer_Raster_Mirrored(t *testing.T) {
+	qrc, err := qrcode.NewWith("engrave me", qrcode.WithMirror())
+	require.NoError(t, err)
+	normal, err := qrcode.New("engrave me")
+	require.NoError(t, err)
+	mat := normal.Matrix()
+
+	// mirrored codes are engraved on the back of glass, rows are reversed.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf)))
+	got := rasterBitmap(t, buf.String(), mat.Width(), 0, 0)
+	for y, row := range mat.Bitmap() {
+		for x, v := range row {
+			require.Equal(t, v, got[y][len(row)-1-x], "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_Raster_Invert(t *testing.T) {
+	qrc, err := qrcode.New("engrave me")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// the quiet zone is marked, so it's 4 modules by default.
+	n := mat.Width() + 2*quietZoneModules
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithInvert())))
+	got := rasterBitmap(t, buf.String(), n, 0, 0)
+	bm := mat.Bitmap()
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			want := true
+			if y >= 4 && y < n-4 && x >= 4 && x < n-4 {
+				want = !bm[y-4][x-4]
+			}
+			require.Equal(t, want, got[y][x], "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_Contour(t *testing.T) {
+	qrc, err := qrcode.New("route me")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width()
+
+	// the router settings with safe Z below cutting depth are ignored.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithStrategy(StrategyContour), WithModuleSize(2),
+		WithRouter(3, -0.5, 50), WithRouter(-1, 0, 10))))
+	out := buf.String()
+	assert.Contains(t, out, "G1 Z-0.5 F50\n")
+
+	// loops are cut at Z-0.5 and parsed back into polygons.
+	var (
+		loops [][]move
+		loop  []move
+	)
+	for _, m := range parseMoves(t, out) {
+		switch {
+		case m.g == 1 && m.z == -0.5 && (loop == nil || m.x != loop[len(loop)-1].x || m.y != loop[len(loop)-1].y):
+			loop = append(loop, m)
+		case m.g == 0 && m.z == 3 && loop != nil:
+			loops = append(loops, loop)
+			loop = nil
+		case m.g == 0:
+			loop = []move{m}
+		}
+	}
+	require.NotEmpty(t, loops)
+
+	// the center of module is marked if it's inside of odd loops.
+	bm := mat.Bitmap()
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			px, py := (float64(x)+0.5)*2, (float64(n-y)-0.5)*2
+			inside := false
+			for _, l := range loops {
+				for i := range l {
+					a, b := l[i], l[(i+1)%len(l)]
+					if (a.y > py) != (b.y > py) && px < a.x+(py-a.y)*(b.x-a.x)/(b.y-a.y) {
+						inside = !inside
+					}
+				}
+			}
+			require.Equal(t, bm[y][x], inside, "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_SVG(t *testing.T) {
+	qrc, err := qrcode.New("laser svg")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithFormat(FormatSVG), WithModuleSize(0.5))))
+	size := mm(float64(mat.Width()) * 0.5)
+	assert.Contains(t, buf.String(), `width="`+size+`mm"`)
+	assert.Contains(t, buf.String(), `fill-rule="evenodd"`)
+
+	buf.Reset()
+	require.NoError(t, qrc.Save(New(&buf, WithFormat(FormatSVG), WithStrategy(StrategyContour))))
+	assert.Contains(t, buf.String(), `fill="none" stroke="#000" stroke-width="0.01"`)
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	for _, opt := range []Option{WithInvert(), WithStrategy(StrategyContour), WithFormat(FormatSVG)} {
+		var buf bytes.Buffer
+		assert.ErrorIs(t, New(&buf, opt).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+		assert.Zero(t, buf.Len())
+	}
+}
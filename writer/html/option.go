@@ -0,0 +1,119 @@
+package html
+
+import (
+	"image/color"
+)
+
+// Layout is how modules are laid out in HTML.
+type Layout uint8
+
+const (
+	// LayoutTable lays out modules in a <table>, adjacent modules in the same color are
+	// merged by colspan. It's the default, and it's safe for Outlook and Gmail.
+	LayoutTable Layout = iota
+	// LayoutGrid lays out runs of dark modules by CSS grid, it's smaller but only works
+	// in modern email clients and browsers.
+	LayoutGrid
+)
+
+const (
+	_defaultModuleSize = 4
+	_defaultQuietZone  = 4
+)
+
+// Option configures the HTML writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	// fg and bg are colors of dark and light modules.
+	fg, bg color.RGBA
+	// moduleSize is the size of each module in px.
+	moduleSize int
+	// quietZone is the width of quiet zone in modules.
+	quietZone int
+	layout    Layout
+	// fallbackImage puts a data URI image under the runs of LayoutGrid.
+	fallbackImage bool
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		fg:         color.RGBA{A: 255},
+		bg:         color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		moduleSize: _defaultModuleSize,
+		quietZone:  _defaultQuietZone,
+		layout:     LayoutTable,
+	}
+}
+
+// WithColors sets colors of dark modules (fg) and light modules and the quiet zone (bg).
+func WithColors(fg, bg color.Color) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if fg != nil {
+			o.fg = color.RGBAModel.Convert(fg).(color.RGBA)
+		}
+		if bg != nil {
+			o.bg = color.RGBAModel.Convert(bg).(color.RGBA)
+		}
+	})
+}
+
+// WithModuleSize sets the size of each module in px, 4 by default.
+func WithModuleSize(px int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if px <= 0 {
+			return
+		}
+
+		o.moduleSize = px
+	})
+}
+
+// WithQuietZone sets the width of quiet zone in modules, 4 by default as ISO/IEC 18004
+// requires.
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithLayout sets how modules are laid out, LayoutTable by default.
+func WithLayout(layout Layout) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if layout > LayoutGrid {
+			return
+		}
+
+		o.layout = layout
+	})
+}
+
+// WithFallbackImage puts the QR code as PNG image in data URI under the runs of
+// LayoutGrid, so that it shows in clients which strip styles or don't support CSS grid,
+// where the runs collapse. It's ignored by LayoutTable, whose attributes work without
+// styles. Gmail and Outlook don't show data URI images anyway.
+func WithFallbackImage() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.fallbackImage = true
+	})
+}
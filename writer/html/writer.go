@@ -0,0 +1,174 @@
+package html
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("html: empty matrix")
+
+// Writer implements qrcode.Writer to render QR code as HTML without images, so that it
+// shows in emails even if images are blocked.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a HTML writer which writes the markup into out, out is not closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+
+	rows := mat.BitmapWithQuietZone(w.option.quietZone)
+	bw := bufio.NewWriter(w.out)
+	switch w.option.layout {
+	case LayoutGrid:
+		if err := w.writeGrid(bw, rows); err != nil {
+			return err
+		}
+	default:
+		w.writeTable(bw, rows)
+	}
+
+	return bw.Flush()
+}
+
+// runs calls fn with each run of modules in the same color in row.
+func runs(row []bool, fn func(x, n int, dark bool)) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		fn(x, n, row[x])
+		x += n
+	}
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// writeTable writes modules as <table>. Colors and sizes are set by both attributes and
+// inline styles, since Outlook ignores some styles and Gmail ignores some attributes.
+// Light runs are left to the background of table.
+func (w *Writer) writeTable(bw *bufio.Writer, rows [][]bool) {
+	px := w.option.moduleSize
+	fg, bg := hex(w.option.fg), hex(w.option.bg)
+	width := len(rows[0]) * px
+
+	fmt.Fprintf(bw, `<table role="presentation" width="%d" cellpadding="0" cellspacing="0" border="0" bgcolor="%s" `+
+		`style="width:%dpx;table-layout:fixed;border-collapse:collapse;border-spacing:0;background-color:%s;margin:0;padding:0">`,
+		width, bg, width, bg)
+	for _, row := range rows {
+		fmt.Fprintf(bw, `<tr style="height:%dpx">`, px)
+		runs(row, func(_, n int, dark bool) {
+			colspan := ""
+			if n > 1 {
+				colspan = fmt.Sprintf(` colspan="%d"`, n)
+			}
+			bgcolor := ""
+			if dark {
+				bgcolor = fmt.Sprintf(` bgcolor="%s"`, fg)
+			}
+			style := fmt.Sprintf("width:%dpx;height:%dpx;padding:0;font-size:0;line-height:0", n*px, px)
+			if dark {
+				style += ";background-color:" + fg
+			}
+			fmt.Fprintf(bw, `<td%s width="%d" height="%d"%s style="%s"></td>`, colspan, n*px, px, bgcolor, style)
+		})
+		bw.WriteString("</tr>")
+	}
+	bw.WriteString("</table>\n")
+}
+
+// writeGrid writes runs of dark modules as items of CSS grid, light modules are left to
+// the background of grid. Rows are explicit, so that light rows at the bottom (quiet
+// zone) are kept. The fallback image is the first item covering the whole grid, runs
+// are drawn over it where CSS grid works, elsewhere the empty runs collapse and only
+// the image shows.
+func (w *Writer) writeGrid(bw *bufio.Writer, rows [][]bool) error {
+	px := w.option.moduleSize
+	fg := hex(w.option.fg)
+
+	fmt.Fprintf(bw, `<div style="display:grid;grid-template-columns:repeat(%d,%dpx);grid-template-rows:repeat(%d,%dpx);`+
+		`width:%dpx;height:%dpx;background-color:%s">`,
+		len(rows[0]), px, len(rows), px, len(rows[0])*px, len(rows)*px, hex(w.option.bg))
+	if w.option.fallbackImage {
+		if err := w.writeImage(bw, rows); err != nil {
+			return err
+		}
+	}
+	for y, row := range rows {
+		runs(row, func(x, n int, dark bool) {
+			if !dark {
+				return
+			}
+			fmt.Fprintf(bw, `<div style="grid-area:%d/%d/span 1/span %d;background-color:%s"></div>`, y+1, x+1, n, fg)
+		})
+	}
+	bw.WriteString("</div>\n")
+	return nil
+}
+
+// writeImage writes modules as PNG image in data URI, which is an item covering the
+// whole CSS grid.
+func (w *Writer) writeImage(bw *bufio.Writer, rows [][]bool) error {
+	px := w.option.moduleSize
+	img := image.NewPaletted(image.Rect(0, 0, len(rows[0])*px, len(rows)*px),
+		color.Palette{w.option.bg, w.option.fg})
+	for y, row := range rows {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < px; dy++ {
+				offset := img.PixOffset(x*px, y*px+dy)
+				for dx := 0; dx < px; dx++ {
+					img.Pix[offset+dx] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	b := img.Bounds()
+	_, err := fmt.Fprintf(bw, `<img src="data:image/png;base64,%s" width="%d" height="%d" alt="QR code" `+
+		`style="grid-area:1/1/span %d/span %d;display:block;width:%dpx;height:%dpx">`,
+		base64.StdEncoding.EncodeToString(buf.Bytes()), b.Dx(), b.Dy(), len(rows), len(rows[0]), b.Dx(), b.Dy())
+	return err
+}
@@ -0,0 +1,183 @@
+package html
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Writer_Table(t *testing.T) {
+	qrc, err := qrcode.New("html writer")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithQuietZone(2), WithModuleSize(3))))
+	out := buf.String()
+	width := mat.Width() + 4
+	assert.Contains(t, out, `width="`+strconv.Itoa(width*3)+`"`)
+
+	rows := strings.Split(strings.TrimSuffix(strings.SplitN(out, "<tr", 2)[1], "</tr></table>\n"), "</tr><tr")
+	require.Len(t, rows, width)
+
+	colspan := regexp.MustCompile(`<td( colspan="(\d+)")?`)
+	bm := mat.Bitmap()
+	for y, row := range rows {
+		modules := 0
+		for _, m := range colspan.FindAllStringSubmatch(row, -1) {
+			n := 1
+			if m[2] != "" {
+				n, _ = strconv.Atoi(m[2])
+			}
+			modules += n
+		}
+		assert.Equal(t, width, modules, "row %d", y)
+	}
+
+	// the first row is the quiet zone, it's merged into one cell.
+	assert.Equal(t, 1, strings.Count(rows[0], "<td"))
+	// the first row of finder pattern: light quiet zone, 7 dark modules, ...
+	assert.True(t, bm[0][0])
+	assert.True(t, strings.HasPrefix(strings.SplitN(rows[2], "<td", 3)[2], ` colspan="7" width="21" height="3" bgcolor="#000000"`))
+}
+
+// gridModules draws the runs of grid back into n×n modules.
+func gridModules(t *testing.T, out string, n int) [][]bool {
+	modules := make([][]bool, n)
+	for y := range modules {
+		modules[y] = make([]bool, n)
+	}
+	item := regexp.MustCompile(`grid-area:(\d+)/(\d+)/span 1/span (\d+)`)
+	for _, m := range item.FindAllStringSubmatch(out, -1) {
+		y, _ := strconv.Atoi(m[1])
+		x, _ := strconv.Atoi(m[2])
+		span, _ := strconv.Atoi(m[3])
+		for i := 0; i < span; i++ {
+			require.False(t, modules[y-1][x-1+i], "items overlap")
+			modules[y-1][x-1+i] = true
+		}
+	}
+
+	return modules
+}
+
+func Test_Writer_Grid(t *testing.T) {
+	qrc, err := qrcode.New("html writer")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLayout(LayoutGrid), WithColors(nil, color.White))))
+	out := buf.String()
+	n := mat.Width() + 8
+
+	// rows are explicit, so that the bottom quiet zone (no items) is kept.
+	size := strconv.Itoa(n*4) + "px"
+	assert.Contains(t, out, "grid-template-columns:repeat("+strconv.Itoa(n)+",4px)")
+	assert.Contains(t, out, "grid-template-rows:repeat("+strconv.Itoa(n)+",4px)")
+	assert.Contains(t, out, "width:"+size+";height:"+size)
+
+	// items cover dark modules exactly.
+	got := gridModules(t, out, n)
+	bm := mat.Bitmap()
+	for y := range got {
+		for x := range got[y] {
+			dark := y >= 4 && y < n-4 && x >= 4 && x < n-4 && bm[y-4][x-4]
+			assert.Equal(t, dark, got[y][x], "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_Grid_MirroredAndReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("html writer", qrcode.WithMirror(), qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	normal, err := qrcode.New("html writer")
+	require.NoError(t, err)
+	mat := normal.Matrix()
+
+	// the mirrored modules are light where normal ones are dark, the quiet zone is dark.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithLayout(LayoutGrid), WithQuietZone(1))))
+	n := mat.Width() + 2
+	got := gridModules(t, buf.String(), n)
+	bm := mat.Bitmap()
+	for y := range got {
+		for x := range got[y] {
+			dark := y == 0 || y == n-1 || x == 0 || x == n-1 || !bm[y-1][n-2-x]
+			assert.Equal(t, dark, got[y][x], "module (%d, %d)", x, y)
+		}
+	}
+	// the top quiet zone is one run.
+	assert.Contains(t, buf.String(), `grid-area:1/1/span 1/span `+strconv.Itoa(n)+`;`)
+}
+
+func Test_Writer_Table_Reversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("html writer", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+
+	// the dark quiet zone is a dark cell across the first row, and the light modules
+	// of reversed finder pattern are left to the background.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithQuietZone(1), WithModuleSize(1))))
+	n := qrc.Dimension() + 2
+	rows := strings.Split(buf.String(), "<tr")
+	require.Len(t, rows, n+1)
+	assert.Equal(t, ` style="height:1px"><td colspan="`+strconv.Itoa(n)+`" width="`+strconv.Itoa(n)+
+		`" height="1" bgcolor="#000000" style="width:`+strconv.Itoa(n)+`px;height:1px;padding:0;font-size:0;line-height:0;background-color:#000000"></td></tr>`,
+		rows[1])
+	assert.True(t, strings.HasPrefix(rows[2], ` style="height:1px"><td width="1" height="1" bgcolor="#000000"`))
+	assert.True(t, strings.HasPrefix(strings.SplitN(rows[2], "<td", 3)[2], ` colspan="7" width="7" height="1" style=`))
+}
+
+func Test_Writer_FallbackImage(t *testing.T) {
+	qrc, err := qrcode.New("html writer")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	w := New(&buf, WithLayout(LayoutGrid), WithFallbackImage(), WithModuleSize(2), WithQuietZone(1))
+	require.NoError(t, w.Write(mat))
+	out := buf.String()
+
+	// the image is the first item covering the whole grid, and it's never hidden, so
+	// that it shows where the runs collapse.
+	n := mat.Width() + 2
+	img := regexp.MustCompile(`^<div [^>]+><img src="data:image/png;base64,([^"]+)" [^>]*style="([^"]+)">`).FindStringSubmatch(out)
+	require.Len(t, img, 3)
+	assert.Equal(t, "grid-area:1/1/span "+strconv.Itoa(n)+"/span "+strconv.Itoa(n)+";display:block;width:"+
+		strconv.Itoa(n*2)+"px;height:"+strconv.Itoa(n*2)+"px", img[2])
+	assert.NotContains(t, out, "display:none")
+	assert.Equal(t, 1, strings.Count(out, "<img"))
+
+	data, err := base64.StdEncoding.DecodeString(img[1])
+	require.NoError(t, err)
+	decoded, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, n*2, decoded.Bounds().Dx())
+	assert.Equal(t, n*2, decoded.Bounds().Dy())
+
+	// table works without styles, so it has no image.
+	buf.Reset()
+	w = New(&buf, WithFallbackImage())
+	require.NoError(t, w.Write(mat))
+	assert.NotContains(t, buf.String(), "<img")
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	for _, layout := range []Layout{LayoutTable, LayoutGrid} {
+		var buf bytes.Buffer
+		w := New(&buf, WithLayout(layout), WithFallbackImage())
+		assert.ErrorIs(t, w.Write(qrcode.Matrix{}), ErrEmptyMatrix)
+		assert.Zero(t, buf.Len())
+	}
+}
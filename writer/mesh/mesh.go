@@ -0,0 +1,279 @@
+package mesh
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+type vec3 struct {
+	x, y, z float64
+}
+
+// _holeSegments is the number of sides of the polygon of hanging hole, it's a multiple of
+// 4, so that the polygon has vertices on both axes.
+const _holeSegments = 32
+
+// ErrHoleTooLarge means the hanging hole and its rim are wider than the base plate.
+var ErrHoleTooLarge = errors.New("mesh: hanging hole is wider than the plate")
+
+// vertexKey identifies a vertex of a part: the grid point (x, y), the height z, and the
+// index of rectangle which owns the vertex if the point is pinched, -1 otherwise.
+type vertexKey struct {
+	x, y  int
+	z     float64
+	owner int
+}
+
+// part is an indexed triangle mesh in one color, triangles are counter-clockwise seen
+// from outside.
+type part struct {
+	name      string
+	color     color.RGBA
+	vertices  []vec3
+	triangles [][3]int
+
+	index map[vertexKey]int
+}
+
+func newPart(name string, c color.RGBA) *part {
+	return &part{name: name, color: c, index: make(map[vertexKey]int)}
+}
+
+func (p *part) add(v vec3) int {
+	p.vertices = append(p.vertices, v)
+	return len(p.vertices) - 1
+}
+
+func (p *part) vertex(k vertexKey, v vec3) int {
+	if i, ok := p.index[k]; ok {
+		return i
+	}
+
+	i := p.add(v)
+	p.index[k] = i
+	return i
+}
+
+func (p *part) triangle(a, b, c int) {
+	p.triangles = append(p.triangles, [3]int{a, b, c})
+}
+
+func (p *part) quad(a, b, c, d int) {
+	p.triangles = append(p.triangles, [3]int{a, b, c}, [3]int{a, c, d})
+}
+
+// extrude adds set cells as a solid from z0 to z1, each cell is size×size and
+// the row 0 of cells is at the top (the largest y). The surface is one closed manifold
+// for each connected area: top and bottom faces are merged rectangles, and every face
+// is split at all corners of rectangles on its edges, so that faces meet at whole edges
+// without T-junctions. Rectangles touching only at a corner have their own vertices
+// there.
+func (p *part) extrude(cells [][]bool, size, z0, z1 float64) {
+	rows := len(cells)
+	set := func(x, y int) bool {
+		return y >= 0 && y < rows && x >= 0 && x < len(cells[y]) && cells[y][x]
+	}
+	// pinched reports whether the grid point (x, y) is the corner of two set cells
+	// touching diagonally, the surface would not be manifold if they shared it.
+	pinched := func(x, y int) bool {
+		tl, tr, bl, br := set(x-1, y-1), set(x, y-1), set(x-1, y), set(x, y)
+		return tl == br && tr == bl && tl != tr
+	}
+
+	rects := qrcode.BitmapRects(cells)
+	corners := make(map[image.Point]bool, 4*len(rects))
+	for _, r := range rects {
+		corners[r.Min] = true
+		corners[image.Pt(r.Max.X, r.Min.Y)] = true
+		corners[r.Max] = true
+		corners[image.Pt(r.Min.X, r.Max.Y)] = true
+	}
+
+	for i, r := range rects {
+		// vertex returns the vertex at the grid point pt of the rectangle.
+		vertex := func(pt image.Point, z float64) int {
+			k := vertexKey{x: pt.X, y: pt.Y, z: z, owner: -1}
+			if pinched(pt.X, pt.Y) {
+				k.owner = i
+			}
+			return p.vertex(k, vec3{x: float64(pt.X) * size, y: float64(rows-pt.Y) * size, z: z})
+		}
+
+		// ring is the boundary of rectangle counter-clockwise seen from above, with all
+		// corners on it; out is the cell outside of each edge of ring.
+		var ring, out []image.Point
+		bl, br, tr, tl := image.Pt(r.Min.X, r.Max.Y), r.Max, image.Pt(r.Max.X, r.Min.Y), r.Min
+		for _, side := range [4][3]image.Point{
+			{bl, br, image.Pt(0, 0)},   // front, cells of the row below
+			{br, tr, image.Pt(0, -1)},  // right
+			{tr, tl, image.Pt(-1, -1)}, // back, cells of the row above
+			{tl, bl, image.Pt(-1, 0)},  // left
+		} {
+			from, to, offset := side[0], side[1], side[2]
+			step := image.Pt(sign(to.X-from.X), sign(to.Y-from.Y))
+			for pt := from; pt != to; pt = pt.Add(step) {
+				if pt == from || corners[pt] {
+					ring = append(ring, pt)
+					out = append(out, pt.Add(offset))
+				}
+			}
+		}
+
+		top, bottom := make([]int, len(ring)), make([]int, len(ring))
+		for j, pt := range ring {
+			top[j], bottom[j] = vertex(pt, z1), vertex(pt, z0)
+		}
+		for j := range ring {
+			k := (j + 1) % len(ring)
+			if !set(out[j].X, out[j].Y) {
+				p.quad(bottom[j], bottom[k], top[k], top[j])
+			}
+		}
+		for _, tri := range triangulate(ring) {
+			p.triangle(top[tri[0]], top[tri[1]], top[tri[2]])
+			p.triangle(bottom[tri[2]], bottom[tri[1]], bottom[tri[0]])
+		}
+	}
+}
+
+// triangulate splits the convex polygon into len(ring)-2 triangles by indices of ring,
+// which are counter-clockwise like ring. Ears are clipped only if they are not
+// degenerate and no other point of ring is on them, so that collinear points on the
+// sides of ring are kept as vertices of triangles.
+func triangulate(ring []image.Point) [][3]int {
+	cross := func(o, a, b image.Point) int {
+		u, v := a.Sub(o), b.Sub(o)
+		return u.X*v.Y - u.Y*v.X
+	}
+	left := make([]int, len(ring))
+	for i := range left {
+		left[i] = i
+	}
+
+	triangles := make([][3]int, 0, len(ring)-2)
+	for len(left) > 3 {
+		for i := range left {
+			a, b, c := left[(i+len(left)-1)%len(left)], left[i], left[(i+1)%len(left)]
+			if cross(ring[a], ring[b], ring[c]) == 0 {
+				continue
+			}
+			ear := true
+			for _, j := range left {
+				if j == a || j == b || j == c {
+					continue
+				}
+				d1, d2, d3 := cross(ring[a], ring[b], ring[j]), cross(ring[b], ring[c], ring[j]), cross(ring[c], ring[a], ring[j])
+				if (d1 >= 0 && d2 >= 0 && d3 >= 0) || (d1 <= 0 && d2 <= 0 && d3 <= 0) {
+					ear = false
+					break
+				}
+			}
+			if !ear {
+				continue
+			}
+
+			triangles = append(triangles, [3]int{a, b, c})
+			left = append(left[:i], left[i+1:]...)
+			break
+		}
+	}
+
+	return append(triangles, [3]int{left[0], left[1], left[2]})
+}
+
+// slab adds a box of width×height from z0 to z1 with the bottom-left corner at the
+// origin. If r > 0, a hole through the box at (cx, cy) is cut by the regular polygon
+// around the circle of radius r, so that a pin of diameter 2r fits, the polygon must be
+// inside the box. Faces between the box and the hole are fans from the corners of box to
+// the quarters of polygon.
+func (p *part) slab(width, height, z0, z1, cx, cy, r float64) {
+	// box is counter-clockwise seen from above: bottom-left, bottom-right, top-right
+	// and top-left.
+	var bottom, top [4]int
+	for i, c := range [4][2]float64{{0, 0}, {width, 0}, {width, height}, {0, height}} {
+		bottom[i] = p.add(vec3{x: c[0], y: c[1], z: z0})
+		top[i] = p.add(vec3{x: c[0], y: c[1], z: z1})
+	}
+	for i := range bottom {
+		j := (i + 1) % len(bottom)
+		p.quad(bottom[i], bottom[j], top[j], top[i])
+	}
+	if r <= 0 {
+		p.quad(top[0], top[1], top[2], top[3])
+		p.quad(bottom[0], bottom[3], bottom[2], bottom[1])
+		return
+	}
+
+	// the polygon is counter-clockwise from the vertex on the right of center.
+	n := _holeSegments
+	radius := r / math.Cos(math.Pi/float64(n))
+	holeBottom, holeTop := make([]int, n), make([]int, n)
+	for i := range holeBottom {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		x, y := cx+radius*math.Cos(a), cy+radius*math.Sin(a)
+		holeBottom[i] = p.add(vec3{x: x, y: y, z: z0})
+		holeTop[i] = p.add(vec3{x: x, y: y, z: z1})
+	}
+	for i := range holeBottom {
+		j := (i + 1) % n
+		p.quad(holeBottom[j], holeBottom[i], holeTop[i], holeTop[j])
+	}
+
+	// the side i of box (from corner i to i+1) meets the polygon at its extreme vertex
+	// toward the side, the quarter of polygon up to the next extreme vertex meets the
+	// corner i+1.
+	for i := range bottom {
+		j := (i + 1) % len(bottom)
+		extreme := (3*n/4 + i*n/4) % n
+		p.triangle(top[i], top[j], holeTop[extreme])
+		p.triangle(bottom[j], bottom[i], holeBottom[extreme])
+		for k := extreme; k < extreme+n/4; k++ {
+			a, b := k%n, (k+1)%n
+			p.triangle(holeTop[b], holeTop[a], top[j])
+			p.triangle(holeBottom[a], holeBottom[b], bottom[j])
+		}
+	}
+}
+
+// build makes the base plate and raised modules of mat, in mm with the origin at the
+// bottom-left corner of base plate, Z is up.
+func (o *outputOptions) build(mat qrcode.Matrix) (base, modules *part, err error) {
+	// the dark quiet zone of reflectance reversed matrix is raised too.
+	code := mat.BitmapWithQuietZone(o.quietZone)
+	size := float64(len(code)) * o.moduleSize
+
+	// the tab of hanging hole is above the quiet zone, the hole is surrounded by a rim of
+	// half its diameter, and at least a module.
+	r := o.holeDiameter / 2
+	tab := 0.0
+	if r > 0 {
+		rim := math.Max(r, o.moduleSize)
+		tab = 2 * (r + rim)
+		if tab > size {
+			return nil, nil, fmt.Errorf("%w: %gmm > %gmm", ErrHoleTooLarge, tab, size)
+		}
+	}
+
+	base = newPart("base", o.baseColor)
+	base.slab(size, size+tab, 0, o.baseThickness, size/2, size+tab/2, r)
+	modules = newPart("modules", o.moduleColor)
+	modules.extrude(code, o.moduleSize, o.baseThickness, o.baseThickness+o.moduleHeight)
+
+	return base, modules, nil
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+
+	return 0
+}
@@ -0,0 +1,157 @@
+package mesh
+
+import (
+	"image/color"
+)
+
+// Format is the 3D file format.
+type Format uint8
+
+const (
+	// FormatSTL writes binary STL, it has no colors. It's the default.
+	FormatSTL Format = iota
+	// FormatOBJ writes Wavefront OBJ, parts are named objects with materials and vertex
+	// colors.
+	FormatOBJ
+	// Format3MF writes 3MF package, parts are objects with their own base materials.
+	Format3MF
+)
+
+const (
+	_defaultModuleSize    = 2.0
+	_defaultBaseThickness = 2.0
+	_defaultModuleHeight  = 1.0
+	_defaultQuietZone     = 2
+)
+
+// Option configures the mesh writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	format Format
+
+	// moduleSize, baseThickness, moduleHeight and holeDiameter are in mm.
+	moduleSize    float64
+	baseThickness float64
+	moduleHeight  float64
+	// quietZone is the border of base plate in modules.
+	quietZone int
+	// holeDiameter is the size of hanging hole, 0 means no hole.
+	holeDiameter float64
+
+	// baseColor and moduleColor are colors of base plate and raised modules.
+	baseColor, moduleColor color.RGBA
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		format:        FormatSTL,
+		moduleSize:    _defaultModuleSize,
+		baseThickness: _defaultBaseThickness,
+		moduleHeight:  _defaultModuleHeight,
+		quietZone:     _defaultQuietZone,
+		baseColor:     color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		moduleColor:   color.RGBA{A: 255},
+	}
+}
+
+// WithFormat sets the file format, FormatSTL by default.
+func WithFormat(f Format) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if f > Format3MF {
+			return
+		}
+
+		o.format = f
+	})
+}
+
+// WithModuleSize sets the size of each module in mm, 2mm by default. Most FDM printers
+// print modules of 1.5mm or larger well.
+func WithModuleSize(mm float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mm <= 0 {
+			return
+		}
+
+		o.moduleSize = mm
+	})
+}
+
+// WithBaseThickness sets the thickness of base plate in mm, 2mm by default.
+func WithBaseThickness(mm float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mm <= 0 {
+			return
+		}
+
+		o.baseThickness = mm
+	})
+}
+
+// WithModuleHeight sets the height of raised modules above the base plate in mm, 1mm by
+// default.
+func WithModuleHeight(mm float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mm <= 0 {
+			return
+		}
+
+		o.moduleHeight = mm
+	})
+}
+
+// WithQuietZone sets the border of base plate around the QR code in modules, 2 by
+// default. ISO/IEC 18004 requires 4, but the edge of plate is usually clear enough.
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithHangingHole adds a tab above the QR code with a round hole of diameter in mm through
+// the base plate. The hole is a polygon around the circle, and it has a rim of half its
+// diameter (at least a module), Write fails with ErrHoleTooLarge if they are wider than
+// the plate.
+func WithHangingHole(diameter float64) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if diameter <= 0 {
+			return
+		}
+
+		o.holeDiameter = diameter
+	})
+}
+
+// WithColors sets colors of the base plate and raised modules, white and black by default.
+// They are written into OBJ and 3MF.
+func WithColors(base, modules color.Color) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if base != nil {
+			o.baseColor = color.RGBAModel.Convert(base).(color.RGBA)
+		}
+		if modules != nil {
+			o.moduleColor = color.RGBAModel.Convert(modules).(color.RGBA)
+		}
+	})
+}
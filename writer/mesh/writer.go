@@ -0,0 +1,201 @@
+package mesh
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("mesh: empty matrix")
+
+// Writer implements qrcode.Writer to make a 3D printable model of QR code: a base plate
+// with raised modules. Each part is a closed manifold surface, so that slicers don't need
+// to repair it.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a mesh writer which writes the model into out, out is not closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+
+	base, modules, err := w.option.build(mat)
+	if err != nil {
+		return err
+	}
+	parts := []*part{base, modules}
+	switch w.option.format {
+	case FormatOBJ:
+		return writeOBJ(w.out, parts)
+	case Format3MF:
+		return write3MF(w.out, parts)
+	default:
+		return writeSTL(w.out, parts)
+	}
+}
+
+func normal(a, b, c vec3) vec3 {
+	u := vec3{b.x - a.x, b.y - a.y, b.z - a.z}
+	v := vec3{c.x - a.x, c.y - a.y, c.z - a.z}
+	n := vec3{u.y*v.z - u.z*v.y, u.z*v.x - u.x*v.z, u.x*v.y - u.y*v.x}
+	l := math.Sqrt(n.x*n.x + n.y*n.y + n.z*n.z)
+	if l == 0 {
+		return vec3{}
+	}
+
+	return vec3{n.x / l, n.y / l, n.z / l}
+}
+
+// writeSTL writes binary STL: 80 bytes header, the count of triangles, and 50 bytes of
+// each triangle (normal, 3 vertices in float32 and 2 bytes attribute).
+func writeSTL(out io.Writer, parts []*part) error {
+	bw := bufio.NewWriter(out)
+
+	var header [80]byte
+	copy(header[:], "go-qrcode mesh")
+	_, _ = bw.Write(header[:])
+
+	count := 0
+	for _, p := range parts {
+		count += len(p.triangles)
+	}
+	_ = binary.Write(bw, binary.LittleEndian, uint32(count))
+
+	var buf [50]byte
+	for _, p := range parts {
+		for _, t := range p.triangles {
+			a, b, c := p.vertices[t[0]], p.vertices[t[1]], p.vertices[t[2]]
+			for i, v := range []vec3{normal(a, b, c), a, b, c} {
+				binary.LittleEndian.PutUint32(buf[i*12:], math.Float32bits(float32(v.x)))
+				binary.LittleEndian.PutUint32(buf[i*12+4:], math.Float32bits(float32(v.y)))
+				binary.LittleEndian.PutUint32(buf[i*12+8:], math.Float32bits(float32(v.z)))
+			}
+			if _, err := bw.Write(buf[:]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// writeOBJ writes Wavefront OBJ, each part is an object using material of its name.
+// Vertices carry colors (x y z r g b), which most tools read without a .mtl file.
+func writeOBJ(out io.Writer, parts []*part) error {
+	bw := bufio.NewWriter(out)
+	bw.WriteString("# go-qrcode mesh\n")
+
+	offset := 1
+	for _, p := range parts {
+		r, g, b := float64(p.color.R)/255, float64(p.color.G)/255, float64(p.color.B)/255
+		fmt.Fprintf(bw, "o %s\nusemtl %s\n", p.name, p.name)
+		for _, v := range p.vertices {
+			fmt.Fprintf(bw, "v %s %s %s %.4g %.4g %.4g\n", num(v.x), num(v.y), num(v.z), r, g, b)
+		}
+		for _, t := range p.triangles {
+			fmt.Fprintf(bw, "f %d %d %d\n", t[0]+offset, t[1]+offset, t[2]+offset)
+		}
+		offset += len(p.vertices)
+	}
+
+	return bw.Flush()
+}
+
+const (
+	_3mfContentTypes = `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="model" ContentType="application/vnd.ms-package.3dmanufacturing-3dmodel+xml"/>
+</Types>
+`
+	_3mfRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Target="/3D/3dmodel.model" Id="rel0" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"/>
+</Relationships>
+`
+)
+
+// write3MF writes 3MF package, a zip of the model in millimeters, each part is an object
+// with its own base material.
+func write3MF(out io.Writer, parts []*part) error {
+	zw := zip.NewWriter(out)
+	for _, f := range []struct{ name, content string }{
+		{name: "[Content_Types].xml", content: _3mfContentTypes},
+		{name: "_rels/.rels", content: _3mfRels},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := zw.Create("3D/3dmodel.model")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(fw)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<model unit="millimeter" xml:lang="en-US" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">` + "\n")
+	bw.WriteString("<resources>\n<basematerials id=\"1\">\n")
+	for _, p := range parts {
+		fmt.Fprintf(bw, "<base name=\"%s\" displaycolor=\"#%02X%02X%02X%02X\"/>\n", p.name, p.color.R, p.color.G, p.color.B, p.color.A)
+	}
+	bw.WriteString("</basematerials>\n")
+	for i, p := range parts {
+		fmt.Fprintf(bw, "<object id=\"%d\" name=\"%s\" type=\"model\" pid=\"1\" pindex=\"%d\">\n<mesh>\n<vertices>\n", i+2, p.name, i)
+		for _, v := range p.vertices {
+			fmt.Fprintf(bw, "<vertex x=\"%s\" y=\"%s\" z=\"%s\"/>\n", num(v.x), num(v.y), num(v.z))
+		}
+		bw.WriteString("</vertices>\n<triangles>\n")
+		for _, t := range p.triangles {
+			fmt.Fprintf(bw, "<triangle v1=\"%d\" v2=\"%d\" v3=\"%d\"/>\n", t[0], t[1], t[2])
+		}
+		bw.WriteString("</triangles>\n</mesh>\n</object>\n")
+	}
+	bw.WriteString("</resources>\n<build>\n")
+	for i := range parts {
+		fmt.Fprintf(bw, "<item objectid=\"%d\"/>\n", i+2)
+	}
+	bw.WriteString("</build>\n</model>\n")
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
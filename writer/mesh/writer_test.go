@@ -0,0 +1,291 @@
+package mesh
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertManifold checks every edge is used by exactly one triangle in each direction,
+// so the surface is closed and no more than two triangles meet at an edge.
+func assertManifold(t *testing.T, p *part) {
+	edges := make(map[[2]int]int)
+	for _, tri := range p.triangles {
+		for i := 0; i < 3; i++ {
+			edges[[2]int{tri[i], tri[(i+1)%3]}]++
+		}
+	}
+	for e, n := range edges {
+		require.Equal(t, 1, n, "part %s edge %v is used more than once", p.name, e)
+		require.Equal(t, 1, edges[[2]int{e[1], e[0]}], "part %s edge %v has no opposite edge", p.name, e)
+	}
+}
+
+// volume returns the signed volume of the closed surface, it's positive if triangles
+// are counter-clockwise seen from outside.
+func volume(p *part) float64 {
+	v := 0.0
+	for _, tri := range p.triangles {
+		a, b, c := p.vertices[tri[0]], p.vertices[tri[1]], p.vertices[tri[2]]
+		v += (a.x*(b.y*c.z-b.z*c.y) - a.y*(b.x*c.z-b.z*c.x) + a.z*(b.x*c.y-b.y*c.x)) / 6
+	}
+
+	return v
+}
+
+func Test_build(t *testing.T) {
+	qrc, err := qrcode.New("3d printed sign")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	o := defaultOutputOptions()
+	WithHangingHole(5).apply(o)
+	base, modules, err := o.build(mat)
+	require.NoError(t, err)
+	assertManifold(t, base)
+	assertManifold(t, modules)
+
+	dark := 0
+	for _, row := range mat.Bitmap() {
+		for _, v := range row {
+			if v {
+				dark++
+			}
+		}
+	}
+	// modules are 2mm×2mm×1mm.
+	assert.InDelta(t, float64(dark)*4, volume(modules), 1e-6)
+
+	// the top area of raised modules equals dark modules.
+	area := 0.0
+	for _, tri := range modules.triangles {
+		a, b, c := modules.vertices[tri[0]], modules.vertices[tri[1]], modules.vertices[tri[2]]
+		if n := normal(a, b, c); n.z > 0.5 {
+			area += ((b.x-a.x)*(c.y-a.y) - (c.x-a.x)*(b.y-a.y)) / 2
+			assert.Equal(t, 3.0, a.z)
+		}
+	}
+	assert.InDelta(t, float64(dark)*4, area, 1e-9)
+
+	// the plate is 2 modules larger at each side, and the tab of hole (5mm and rim of
+	// 2.5mm) is above.
+	var maxX, maxY float64
+	for _, v := range base.vertices {
+		if v.x > maxX {
+			maxX = v.x
+		}
+		if v.y > maxY {
+			maxY = v.y
+		}
+	}
+	width := float64(mat.Width()+4) * 2
+	assert.Equal(t, width, maxX)
+	assert.Equal(t, width+5*2, maxY)
+	// the hole of polygon around the circle of 5mm is cut through the plate which is 2mm
+	// thick.
+	n := float64(_holeSegments)
+	radius := 2.5 / math.Cos(math.Pi/n)
+	hole := n * radius * radius * math.Sin(2*math.Pi/n) / 2
+	assert.InDelta(t, (width*(width+10)-hole)*2, volume(base), 1e-6)
+	assert.Len(t, base.triangles, 4*_holeSegments+16)
+}
+
+func Test_build_HoleTooLarge(t *testing.T) {
+	qrc, err := qrcode.New("3d printed sign")
+	require.NoError(t, err)
+
+	// the plate of version 2 is 29 modules (58mm), the hole of 30mm needs 60mm with rims.
+	o := defaultOutputOptions()
+	WithHangingHole(30).apply(o)
+	_, _, err = o.build(qrc.Matrix())
+	assert.ErrorIs(t, err, ErrHoleTooLarge)
+
+	WithHangingHole(25).apply(o)
+	_, _, err = o.build(qrc.Matrix())
+	assert.NoError(t, err)
+}
+
+func Test_extrude(t *testing.T) {
+	// cells touching at corners only, and a T shape which meets cells of other widths.
+	grid := [][]bool{
+		{true, false, true},
+		{false, true, false},
+		{true, true, true},
+		{false, true, false},
+	}
+	p := newPart("test", color.RGBA{A: 255})
+	p.extrude(grid, 1, 0, 1)
+	assertManifold(t, p)
+	assert.InDelta(t, 7, volume(p), 1e-9)
+
+	// vertices at the pinched corners are not shared, the others are.
+	seen := make(map[vec3]int)
+	for _, v := range p.vertices {
+		seen[v]++
+	}
+	assert.Equal(t, 2, seen[vec3{x: 1, y: 3, z: 1}])
+	assert.Equal(t, 1, seen[vec3{x: 1, y: 2, z: 1}])
+
+	// a block is merged into a box.
+	block := [][]bool{
+		{true, true, true},
+		{true, true, true},
+	}
+	p = newPart("block", color.RGBA{A: 255})
+	p.extrude(block, 1, 0, 1)
+	assertManifold(t, p)
+	assert.Len(t, p.triangles, 12)
+	assert.InDelta(t, 6, volume(p), 1e-9)
+}
+
+func Test_Writer_STL(t *testing.T) {
+	qrc, err := qrcode.New("stl")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf)))
+	out := buf.Bytes()
+	base, modules, err := defaultOutputOptions().build(mat)
+	require.NoError(t, err)
+	count := binary.LittleEndian.Uint32(out[80:84])
+	assert.Equal(t, len(base.triangles)+len(modules.triangles), int(count))
+	assert.Len(t, out, 84+50*int(count))
+}
+
+func Test_Writer_OBJ(t *testing.T) {
+	qrc, err := qrcode.New("obj")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithFormat(FormatOBJ), WithColors(nil, color.RGBA{R: 255, A: 255}))))
+	out := buf.String()
+	assert.Contains(t, out, "o base\nusemtl base\n")
+	assert.Contains(t, out, "o modules\nusemtl modules\n")
+	// the first vertex of base is white, and modules are red.
+	assert.Contains(t, out, "v 0 0 0 1 1 1\n")
+	assert.Contains(t, out, " 3 1 0 0\n")
+	assert.NotContains(t, out, " 3 0 0 0\n")
+}
+
+func Test_Writer_3MF(t *testing.T) {
+	qrc, err := qrcode.New("3mf")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithFormat(Format3MF))))
+	out := buf.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(zr.File))
+	var model string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "3D/3dmodel.model" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			model = string(data)
+		}
+	}
+	assert.Equal(t, []string{"[Content_Types].xml", "_rels/.rels", "3D/3dmodel.model"}, names)
+	assert.Contains(t, model, `<base name="base" displaycolor="#FFFFFFFF"/>`)
+	assert.Contains(t, model, `<base name="modules" displaycolor="#000000FF"/>`)
+	assert.Equal(t, 2, strings.Count(model, "<object "))
+}
+
+func Test_build_TriangleCount(t *testing.T) {
+	qrc, err := qrcode.NewWith("large sign", qrcode.WithVersion(40))
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	_, modules, err := defaultOutputOptions().build(mat)
+	require.NoError(t, err)
+	assertManifold(t, modules)
+
+	dark := 0
+	for _, row := range mat.Bitmap() {
+		for _, v := range row {
+			if v {
+				dark++
+			}
+		}
+	}
+	// each module on its own needs 4 triangles of top and bottom, and 2 of each wall,
+	// about 8 triangles per module in QR codes.
+	assert.Less(t, len(modules.triangles), 7*dark)
+}
+
+// topModules returns the modules covered by top faces of raised modules, in rows of
+// the code from the top.
+func topModules(t *testing.T, p *part, size float64, n int) [][]bool {
+	modules := make([][]bool, n)
+	for y := range modules {
+		modules[y] = make([]bool, n)
+	}
+	for y := range modules {
+		for x := range modules[y] {
+			// a point of module off the diagonals of rectangles is inside of a top
+			// triangle.
+			px, py := (float64(x)+0.31)*size, (float64(n-y)-0.43)*size
+			for _, tri := range p.triangles {
+				a, b, c := p.vertices[tri[0]], p.vertices[tri[1]], p.vertices[tri[2]]
+				if normal(a, b, c).z < 0.5 {
+					continue
+				}
+				d1 := (b.x-a.x)*(py-a.y) - (b.y-a.y)*(px-a.x)
+				d2 := (c.x-b.x)*(py-b.y) - (c.y-b.y)*(px-b.x)
+				d3 := (a.x-c.x)*(py-c.y) - (a.y-c.y)*(px-c.x)
+				if d1 > 0 && d2 > 0 && d3 > 0 {
+					require.False(t, modules[y][x], "module (%d, %d) is covered twice", x, y)
+					modules[y][x] = true
+				}
+			}
+		}
+	}
+
+	return modules
+}
+
+func Test_build_MirroredAndReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("3d printed sign", qrcode.WithMirror(), qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	normal, err := qrcode.New("3d printed sign")
+	require.NoError(t, err)
+	mat := normal.Matrix()
+
+	// the mirrored code is raised where the normal one is light, and the quiet zone
+	// is raised too.
+	_, modules, err := defaultOutputOptions().build(qrc.Matrix())
+	require.NoError(t, err)
+	assertManifold(t, modules)
+	n := mat.Width() + 4
+	got := topModules(t, modules, 2, n)
+	bm := mat.Bitmap()
+	for y := range got {
+		for x := range got[y] {
+			raised := y < 2 || y >= n-2 || x < 2 || x >= n-2 || !bm[y-2][n-3-x]
+			require.Equal(t, raised, got[y][x], "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	for _, f := range []Format{FormatSTL, FormatOBJ, Format3MF} {
+		var buf bytes.Buffer
+		assert.ErrorIs(t, New(&buf, WithFormat(f)).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+		assert.Zero(t, buf.Len())
+	}
+}
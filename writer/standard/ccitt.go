@@ -0,0 +1,243 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// ccittCode is a bit code of ITU-T T.4, the low n bits of bits are the code.
+type ccittCode struct {
+	bits uint16
+	n    uint8
+}
+
+var (
+	// ccittModeCodes are codes of 2-D coding modes, ref to ITU-T T.4 Table 4.
+	ccittPass       = ccittCode{0x1, 4}
+	ccittHorizontal = ccittCode{0x1, 3}
+	ccittVertical   = [7]ccittCode{ // V(-3) ... V(+3)
+		{0x2, 7}, {0x2, 6}, {0x2, 3}, {0x1, 1}, {0x3, 3}, {0x3, 6}, {0x3, 7},
+	}
+	ccittEOL = ccittCode{0x1, 12}
+
+	// ccittWhiteTerminating and ccittBlackTerminating are terminating codes of run
+	// length 0 to 63, ref to ITU-T T.4 Table 2.
+	ccittWhiteTerminating = [64]ccittCode{
+		{0x35, 8}, {0x07, 6}, {0x07, 4}, {0x08, 4}, {0x0b, 4}, {0x0c, 4}, {0x0e, 4}, {0x0f, 4},
+		{0x13, 5}, {0x14, 5}, {0x07, 5}, {0x08, 5}, {0x08, 6}, {0x03, 6}, {0x34, 6}, {0x35, 6},
+		{0x2a, 6}, {0x2b, 6}, {0x27, 7}, {0x0c, 7}, {0x08, 7}, {0x17, 7}, {0x03, 7}, {0x04, 7},
+		{0x28, 7}, {0x2b, 7}, {0x13, 7}, {0x24, 7}, {0x18, 7}, {0x02, 8}, {0x03, 8}, {0x1a, 8},
+		{0x1b, 8}, {0x12, 8}, {0x13, 8}, {0x14, 8}, {0x15, 8}, {0x16, 8}, {0x17, 8}, {0x28, 8},
+		{0x29, 8}, {0x2a, 8}, {0x2b, 8}, {0x2c, 8}, {0x2d, 8}, {0x04, 8}, {0x05, 8}, {0x0a, 8},
+		{0x0b, 8}, {0x52, 8}, {0x53, 8}, {0x54, 8}, {0x55, 8}, {0x24, 8}, {0x25, 8}, {0x58, 8},
+		{0x59, 8}, {0x5a, 8}, {0x5b, 8}, {0x4a, 8}, {0x4b, 8}, {0x32, 8}, {0x33, 8}, {0x34, 8},
+	}
+	ccittBlackTerminating = [64]ccittCode{
+		{0x37, 10}, {0x02, 3}, {0x03, 2}, {0x02, 2}, {0x03, 3}, {0x03, 4}, {0x02, 4}, {0x03, 5},
+		{0x05, 6}, {0x04, 6}, {0x04, 7}, {0x05, 7}, {0x07, 7}, {0x04, 8}, {0x07, 8}, {0x18, 9},
+		{0x17, 10}, {0x18, 10}, {0x08, 10}, {0x67, 11}, {0x68, 11}, {0x6c, 11}, {0x37, 11}, {0x28, 11},
+		{0x17, 11}, {0x18, 11}, {0xca, 12}, {0xcb, 12}, {0xcc, 12}, {0xcd, 12}, {0x68, 12}, {0x69, 12},
+		{0x6a, 12}, {0x6b, 12}, {0xd2, 12}, {0xd3, 12}, {0xd4, 12}, {0xd5, 12}, {0xd6, 12}, {0xd7, 12},
+		{0x6c, 12}, {0x6d, 12}, {0xda, 12}, {0xdb, 12}, {0x54, 12}, {0x55, 12}, {0x56, 12}, {0x57, 12},
+		{0x64, 12}, {0x65, 12}, {0x52, 12}, {0x53, 12}, {0x24, 12}, {0x37, 12}, {0x38, 12}, {0x27, 12},
+		{0x28, 12}, {0x58, 12}, {0x59, 12}, {0x2b, 12}, {0x2c, 12}, {0x5a, 12}, {0x66, 12}, {0x67, 12},
+	}
+
+	// ccittWhiteMakeup and ccittBlackMakeup are make-up codes of run length 64 to
+	// 1728 (i+1)*64, ref to ITU-T T.4 Table 3.
+	ccittWhiteMakeup = [27]ccittCode{
+		{0x1b, 5}, {0x12, 5}, {0x17, 6}, {0x37, 7}, {0x36, 8}, {0x37, 8}, {0x64, 8}, {0x65, 8},
+		{0x68, 8}, {0x67, 8}, {0xcc, 9}, {0xcd, 9}, {0xd2, 9}, {0xd3, 9}, {0xd4, 9}, {0xd5, 9},
+		{0xd6, 9}, {0xd7, 9}, {0xd8, 9}, {0xd9, 9}, {0xda, 9}, {0xdb, 9}, {0x98, 9}, {0x99, 9},
+		{0x9a, 9}, {0x18, 6}, {0x9b, 9},
+	}
+	ccittBlackMakeup = [27]ccittCode{
+		{0x0f, 10}, {0xc8, 12}, {0xc9, 12}, {0x5b, 12}, {0x33, 12}, {0x34, 12}, {0x35, 12}, {0x6c, 13},
+		{0x6d, 13}, {0x4a, 13}, {0x4b, 13}, {0x4c, 13}, {0x4d, 13}, {0x72, 13}, {0x73, 13}, {0x74, 13},
+		{0x75, 13}, {0x76, 13}, {0x77, 13}, {0x52, 13}, {0x53, 13}, {0x54, 13}, {0x55, 13}, {0x5a, 13},
+		{0x5b, 13}, {0x64, 13}, {0x65, 13},
+	}
+
+	// ccittExtendedMakeup are make-up codes of run length 1792 to 2560 (i*64+1792)
+	// shared by white and black runs, ref to ITU-T T.4 Table 3 (extended).
+	ccittExtendedMakeup = [13]ccittCode{
+		{0x08, 11}, {0x0c, 11}, {0x0d, 11}, {0x12, 12}, {0x13, 12}, {0x14, 12}, {0x15, 12},
+		{0x16, 12}, {0x17, 12}, {0x1c, 12}, {0x1d, 12}, {0x1e, 12}, {0x1f, 12},
+	}
+)
+
+// ccittBitWriter writes codes MSB first.
+type ccittBitWriter struct {
+	buf   bytes.Buffer
+	acc   uint32
+	nBits uint8
+}
+
+func (b *ccittBitWriter) write(c ccittCode) {
+	b.acc = b.acc<<c.n | uint32(c.bits)
+	b.nBits += c.n
+	for b.nBits >= 8 {
+		b.nBits -= 8
+		b.buf.WriteByte(byte(b.acc >> b.nBits))
+	}
+}
+
+// bytes returns the written codes, the last byte is padded with 0.
+func (b *ccittBitWriter) bytes() []byte {
+	if b.nBits > 0 {
+		b.buf.WriteByte(byte(b.acc << (8 - b.nBits)))
+		b.nBits = 0
+	}
+
+	return b.buf.Bytes()
+}
+
+// writeRun writes the run length of black (or white) pixels as make-up codes
+// followed by a terminating code.
+func (b *ccittBitWriter) writeRun(run int, black bool) {
+	terminating, makeup := &ccittWhiteTerminating, &ccittWhiteMakeup
+	if black {
+		terminating, makeup = &ccittBlackTerminating, &ccittBlackMakeup
+	}
+
+	for run >= 2560 {
+		b.write(ccittExtendedMakeup[len(ccittExtendedMakeup)-1])
+		run -= 2560
+	}
+	if run >= 1792 {
+		b.write(ccittExtendedMakeup[(run-1792)/64])
+		run %= 64
+	} else if run >= 64 {
+		b.write(makeup[run/64-1])
+		run %= 64
+	}
+	b.write(terminating[run])
+}
+
+// nextChange returns the position of the first changing element at or after from,
+// whose color is black (or white). The pixel before the line is white, and the
+// width of line is returned if there is none.
+func nextChange(line []bool, from int, black bool) int {
+	for i := from; i < len(line); i++ {
+		prev := false
+		if i > 0 {
+			prev = line[i-1]
+		}
+		if line[i] == black && prev != black {
+			return i
+		}
+	}
+
+	return len(line)
+}
+
+// encodeG4 encodes the bilevel image (true is black) by CCITT Group 4 (ITU-T T.6)
+// two-dimensional coding, the data ends with EOFB.
+func encodeG4(rows [][]bool, width int) []byte {
+	b := &ccittBitWriter{}
+	ref := make([]bool, width) // imaginary white line above the image
+
+	for _, cur := range rows {
+		a0, black := -1, false
+		for a0 < width {
+			a1 := nextChange(cur, a0+1, !black)
+			b1 := nextChange(ref, a0+1, !black)
+			b2 := nextChange(ref, b1+1, black)
+
+			switch {
+			case b2 < a1:
+				b.write(ccittPass)
+				a0 = b2
+			case a1-b1 >= -3 && a1-b1 <= 3:
+				b.write(ccittVertical[a1-b1+3])
+				a0, black = a1, !black
+			default:
+				a2 := nextChange(cur, a1+1, black)
+				start := a0
+				if start < 0 {
+					start = 0
+				}
+				b.write(ccittHorizontal)
+				b.writeRun(a1-start, black)
+				b.writeRun(a2-a1, !black)
+				a0 = a2
+			}
+		}
+		ref = cur
+	}
+
+	b.write(ccittEOL)
+	b.write(ccittEOL)
+	return b.bytes()
+}
+
+// TIFF field types used by writeTIFFG4.
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// writeTIFFG4 writes the bilevel image (true is black) into a little-endian TIFF
+// compressed by CCITT Group 4, with one strip.
+func writeTIFFG4(w io.Writer, rows [][]bool, width, dpi int) error {
+	data := encodeG4(rows, width)
+	if dpi <= 0 {
+		dpi = 72
+	}
+
+	type entry struct {
+		tag, typ uint16
+		value    uint32
+	}
+	const ifdEntries = 13
+	dataOffset := uint32(8)
+	// values and the IFD begin on word boundaries.
+	resolutionOffset := dataOffset + uint32(len(data)+len(data)%2)
+	ifdOffset := resolutionOffset + 16
+
+	entries := [ifdEntries]entry{
+		{256, tiffLong, uint32(width)},            // ImageWidth
+		{257, tiffLong, uint32(len(rows))},        // ImageLength
+		{258, tiffShort, 1},                       // BitsPerSample
+		{259, tiffShort, 4},                       // Compression: CCITT T.6
+		{262, tiffShort, 0},                       // PhotometricInterpretation: WhiteIsZero
+		{273, tiffLong, dataOffset},               // StripOffsets
+		{277, tiffShort, 1},                       // SamplesPerPixel
+		{278, tiffLong, uint32(len(rows))},        // RowsPerStrip
+		{279, tiffLong, uint32(len(data))},        // StripByteCounts
+		{282, tiffRational, resolutionOffset},     // XResolution
+		{283, tiffRational, resolutionOffset + 8}, // YResolution
+		{293, tiffLong, 0},                        // T6Options
+		{296, tiffShort, 2},                       // ResolutionUnit: inch
+	}
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("II")
+	_ = binary.Write(&buf, le, uint16(42))
+	_ = binary.Write(&buf, le, ifdOffset)
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	_ = binary.Write(&buf, le, [4]uint32{uint32(dpi), 1, uint32(dpi), 1})
+
+	_ = binary.Write(&buf, le, uint16(ifdEntries))
+	for _, e := range entries {
+		_ = binary.Write(&buf, le, e.tag)
+		_ = binary.Write(&buf, le, e.typ)
+		_ = binary.Write(&buf, le, uint32(1))
+		if e.typ == tiffShort {
+			// SHORT values are left-justified in the value field.
+			_ = binary.Write(&buf, le, [2]uint16{uint16(e.value), 0})
+			continue
+		}
+		_ = binary.Write(&buf, le, e.value)
+	}
+	_ = binary.Write(&buf, le, uint32(0)) // no next IFD
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,105 @@
+package standard
+
+import (
+	"image"
+	"math"
+
+	"github.com/yeqown/go-qrcode/v2"
+	drawpkg "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// ComposeTransform is an affine transform in the same form as f64.Aff3:
+//
+//	x' = T[0]*x + T[1]*y + T[2]
+//	y' = T[3]*x + T[4]*y + T[5]
+//
+// The zero ComposeTransform means no transform.
+type ComposeTransform f64.Aff3
+
+// ComposeIdentity is the transform which changes nothing.
+var ComposeIdentity = ComposeTransform{1, 0, 0, 0, 1, 0}
+
+// ComposeRotate returns the transform rotating by degrees clockwise (y axis points down).
+func ComposeRotate(degrees float64) ComposeTransform {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return ComposeTransform{cos, -sin, 0, sin, cos, 0}
+}
+
+// ComposeSkew returns the transform skewing by xDegrees along x axis and yDegrees along y axis.
+func ComposeSkew(xDegrees, yDegrees float64) ComposeTransform {
+	return ComposeTransform{1, math.Tan(xDegrees * math.Pi / 180), 0, math.Tan(yDegrees * math.Pi / 180), 1, 0}
+}
+
+// ComposeScale returns the transform scaling by sx and sy.
+func ComposeScale(sx, sy float64) ComposeTransform {
+	return ComposeTransform{sx, 0, 0, 0, sy, 0}
+}
+
+// Then returns the transform applying t first and then next.
+func (t ComposeTransform) Then(next ComposeTransform) ComposeTransform {
+	t, next = t.orIdentity(), next.orIdentity()
+	return ComposeTransform{
+		next[0]*t[0] + next[1]*t[3], next[0]*t[1] + next[1]*t[4], next[0]*t[2] + next[1]*t[5] + next[2],
+		next[3]*t[0] + next[4]*t[3], next[3]*t[1] + next[4]*t[4], next[3]*t[2] + next[4]*t[5] + next[5],
+	}
+}
+
+func (t ComposeTransform) orIdentity() ComposeTransform {
+	if t == (ComposeTransform{}) {
+		return ComposeIdentity
+	}
+
+	return t
+}
+
+// Placement decides where and how a QR code is composed onto an image.
+type Placement struct {
+	// Rect is the destination rectangle, the QR code is fitted into it as DrawOnto does.
+	Rect image.Rectangle
+
+	// Transform is applied around the center of Rect, for example, ComposeRotate(15) or
+	// ComposeRotate(15).Then(ComposeSkew(10, 0)). The zero value means no transform.
+	Transform ComposeTransform
+
+	// TransparentBackground leaves light modules and the quiet zone transparent, so
+	// that the background of the template shows through.
+	TransparentBackground bool
+}
+
+// Compose draws mat with all styles of r onto dst (a ticket or badge template, for
+// example) at placement. The QR code is blended over dst: transformed edges are
+// antialiased and no opaque square is painted outside the transformed code.
+func (r *Renderer) Compose(dst drawpkg.Image, mat qrcode.Matrix, placement Placement) error {
+	option := r.option
+	if placement.TransparentBackground {
+		cp := *option
+		cp.bgTransparent = true
+		option = &cp
+	}
+
+	img, target, err := r.renderFit(placement.Rect, mat, option)
+	if err != nil || img == nil {
+		return err
+	}
+
+	// map the image onto target: scale, then transform around the center of rect.
+	src := img.Bounds()
+	sx := float64(target.Dx()) / float64(src.Dx())
+	sy := float64(target.Dy()) / float64(src.Dy())
+	cx := float64(placement.Rect.Min.X+placement.Rect.Max.X) / 2
+	cy := float64(placement.Rect.Min.Y+placement.Rect.Max.Y) / 2
+	toTarget := ComposeTransform{
+		sx, 0, float64(target.Min.X) - sx*float64(src.Min.X) - cx,
+		0, sy, float64(target.Min.Y) - sy*float64(src.Min.Y) - cy,
+	}
+	s2d := toTarget.Then(placement.Transform).Then(ComposeTransform{1, 0, cx, 0, 1, cy})
+
+	if placement.Transform.orIdentity() == ComposeIdentity && target.Size() == src.Size() {
+		drawpkg.Draw(dst, target, img, src.Min, drawpkg.Over)
+		return nil
+	}
+
+	drawpkg.CatmullRom.Transform(dst, f64.Aff3(s2d), img, src, drawpkg.Over, nil)
+	return nil
+}
@@ -0,0 +1,82 @@
+package standard
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_ComposeTransform(t *testing.T) {
+	apply := func(tr ComposeTransform, x, y float64) (float64, float64) {
+		tr = tr.orIdentity()
+		return tr[0]*x + tr[1]*y + tr[2], tr[3]*x + tr[4]*y + tr[5]
+	}
+
+	x, y := apply(ComposeRotate(90), 1, 0)
+	assert.InDelta(t, 0, x, 1e-9)
+	assert.InDelta(t, 1, y, 1e-9)
+
+	// scale first, then rotate.
+	x, y = apply(ComposeScale(2, 1).Then(ComposeRotate(90)), 1, 0)
+	assert.InDelta(t, 0, x, 1e-9)
+	assert.InDelta(t, 2, y, 1e-9)
+
+	x, y = apply(ComposeSkew(45, 0), 0, 1)
+	assert.InDelta(t, 1, x, 1e-9)
+	assert.InDelta(t, 1, y, 1e-9)
+
+	assert.Equal(t, ComposeIdentity, ComposeTransform{}.Then(ComposeTransform{}))
+}
+
+func Test_Renderer_Compose(t *testing.T) {
+	qrc, err := qrcode.New("Test_Renderer_Compose")
+	require.NoError(t, err)
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	template := func() *image.RGBA {
+		dst := image.NewRGBA(image.Rect(0, 0, 400, 400))
+		for i := 0; i < len(dst.Pix); i += 4 {
+			copy(dst.Pix[i:], []uint8{red.R, red.G, red.B, red.A})
+		}
+		return dst
+	}
+	r := NewRenderer()
+	rect := image.Rect(100, 100, 300, 300)
+
+	// without transform, it's the same as DrawOnto.
+	want, got := template(), template()
+	require.NoError(t, r.DrawOnto(want, rect, qrc.Matrix()))
+	require.NoError(t, r.Compose(got, qrc.Matrix(), Placement{Rect: rect}))
+	assert.Equal(t, want.Pix, got.Pix)
+
+	// rotated by 45 degrees, the corners of rect are not painted, but the center is.
+	got = template()
+	require.NoError(t, r.Compose(got, qrc.Matrix(), Placement{Rect: rect, Transform: ComposeRotate(45)}))
+	assert.Equal(t, red, got.RGBAAt(102, 102))
+	assert.Equal(t, red, got.RGBAAt(297, 297))
+	// the rotated code reaches out of rect by (sqrt(2)-1) * 100.
+	half := 100.0
+	edge := int(200 - half*math.Sqrt2)
+	assert.NotEqual(t, red, got.RGBAAt(edge+5, 200))
+	assert.Equal(t, red, got.RGBAAt(edge-5, 200))
+
+	// transparent background keeps the template in light modules and quiet zone.
+	got = template()
+	require.NoError(t, r.Compose(got, qrc.Matrix(), Placement{Rect: rect, TransparentBackground: true}))
+	assert.Equal(t, red, got.RGBAAt(105, 105))
+	var black int
+	for x := rect.Min.X; x < rect.Max.X; x++ {
+		c := got.RGBAAt(x, 200)
+		assert.True(t, c == red || c == color_BLACK, "pixel (%d, 200) is %v", x, c)
+		if c == color_BLACK {
+			black++
+		}
+	}
+	assert.NotZero(t, black)
+}
@@ -0,0 +1,132 @@
+package standard
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// encoderRegistry keeps image encoders by file extension (".png") and MIME type
+// ("image/png"), New picks the encoder by the extension of filename.
+var encoderRegistry = struct {
+	sync.RWMutex
+
+	encoders map[string]ImageEncoder
+}{
+	encoders: map[string]ImageEncoder{
+		".jpg":          jpegEncoder{},
+		".jpeg":         jpegEncoder{},
+		"image/jpeg":    jpegEncoder{},
+		".png":          pngEncoder{},
+		"image/png":     pngEncoder{},
+		".svg":          svgEncoder{},
+		"image/svg+xml": svgEncoder{},
+		".gif":          gifEncoder{},
+		"image/gif":     gifEncoder{},
+		".bmp":          bmpEncoder{},
+		"image/bmp":     bmpEncoder{},
+		".tif":          tiffEncoder{},
+		".tiff":         tiffEncoder{},
+		"image/tiff":    tiffEncoder{},
+	},
+}
+
+// RegisterImageEncoder registers the encoder by file extension (with the leading dot,
+// such as ".webp") or MIME type (such as "image/webp"), it replaces the encoder
+// registered with the same name. Names are case-insensitive.
+func RegisterImageEncoder(name string, encoder ImageEncoder) {
+	if encoder == nil {
+		return
+	}
+
+	encoderRegistry.Lock()
+	defer encoderRegistry.Unlock()
+
+	encoderRegistry.encoders[strings.ToLower(name)] = encoder
+}
+
+// LookupImageEncoder returns the encoder registered by file extension or MIME type.
+// name could also be a list of media ranges as HTTP Accept header, the first one
+// registered in the order of quality is used, wildcards such as "image/*" are skipped.
+func LookupImageEncoder(name string) (ImageEncoder, error) {
+	encoderRegistry.RLock()
+	defer encoderRegistry.RUnlock()
+
+	for _, mediaType := range mediaRanges(name) {
+		if encoder, ok := encoderRegistry.encoders[mediaType]; ok {
+			return encoder, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no image encoder registered for %q", name)
+}
+
+// mediaRanges returns the lower cased media ranges in accept (such as
+// "image/webp,image/png;q=0.9,*/*;q=0.8") in the order of quality, parameters are
+// removed and ranges of quality 0 are dropped.
+func mediaRanges(accept string) []string {
+	type mediaRange struct {
+		name    string
+		quality float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{name: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		if r.name == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(param, "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				r.quality = q
+			}
+		}
+		if r.quality > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	names := make([]string, len(ranges))
+	for i, r := range ranges {
+		names[i] = r.name
+	}
+
+	return names
+}
+
+// WithImageEncoderFor uses the encoder registered by file extension or MIME type, for
+// example, WithImageEncoderFor(r.Header.Get("Accept")) in HTTP handler.
+func WithImageEncoderFor(name string) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		encoder, err := LookupImageEncoder(name)
+		if err != nil {
+			oo.fail(err)
+			return
+		}
+
+		oo.imageEncoder = encoder
+	})
+}
+
+// encoderForFile returns the option using the encoder registered by the extension of
+// filename.
+func encoderForFile(filename string) (ImageOption, error) {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return nil, fmt.Errorf("no extension in filename %q to decide the image format", filename)
+	}
+	if _, err := LookupImageEncoder(ext); err != nil {
+		return nil, err
+	}
+
+	return WithImageEncoderFor(ext), nil
+}
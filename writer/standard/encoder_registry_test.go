@@ -0,0 +1,116 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rawEncoder struct{}
+
+func (rawEncoder) Encode(w io.Writer, img image.Image) error {
+	_, err := w.Write([]byte("raw"))
+	return err
+}
+
+func Test_New_encoderByExtension(t *testing.T) {
+	mat := recordMatrix(t, "Test_New_encoderByExtension")
+	dir := t.TempDir()
+
+	magics := map[string]string{
+		"code.png":  "\x89PNG",
+		"code.JPG":  "\xff\xd8",
+		"code.gif":  "GIF8",
+		"code.bmp":  "BM",
+		"code.tiff": "II*\x00",
+		"code.svg":  "<?xml",
+	}
+	for name, magic := range magics {
+		w, err := New(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.NoError(t, w.Write(mat))
+		require.NoError(t, w.Close())
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, magic, string(data[:len(magic)]), name)
+	}
+
+	// options override the encoder decided by the extension.
+	w, err := New(filepath.Join(dir, "override.png"), WithBuiltinImageEncoder(JPEG_FORMAT))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(mat))
+	require.NoError(t, w.Close())
+	data, err := os.ReadFile(filepath.Join(dir, "override.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "\xff\xd8", string(data[:2]))
+
+	// unknown extensions are rejected before the file is created.
+	for _, name := range []string{"code.raw", "code"} {
+		_, err = New(filepath.Join(dir, name))
+		assert.Error(t, err)
+		_, err = os.Stat(filepath.Join(dir, name))
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	RegisterImageEncoder(".RAW", rawEncoder{})
+	w, err = New(filepath.Join(dir, "code.raw"))
+	require.NoError(t, err)
+	require.NoError(t, w.Write(mat))
+	require.NoError(t, w.Close())
+	data, err = os.ReadFile(filepath.Join(dir, "code.raw"))
+	require.NoError(t, err)
+	assert.Equal(t, "raw", string(data))
+}
+
+func Test_WithImageEncoderFor(t *testing.T) {
+	mat := recordMatrix(t, "Test_WithImageEncoderFor")
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, NewRenderer(WithImageEncoderFor("image/gif")).Encode(buf, mat))
+	assert.Equal(t, "GIF8", buf.String()[:4])
+
+	err := NewRenderer(WithImageEncoderFor("image/x-unknown")).Encode(buf, mat)
+	assert.Error(t, err)
+
+	// Accept header of browsers, the first registered media type by quality is used.
+	buf.Reset()
+	accept := "image/avif,image/webp,image/png;q=0.5,image/gif;q=0.9,*/*;q=0.8"
+	require.NoError(t, NewRenderer(WithImageEncoderFor(accept)).Encode(buf, mat))
+	assert.Equal(t, "GIF8", buf.String()[:4])
+
+	err = NewRenderer(WithImageEncoderFor("image/avif,image/webp,*/*;q=0.8")).Encode(buf, mat)
+	assert.Error(t, err)
+	err = NewRenderer(WithImageEncoderFor("image/gif;q=0")).Encode(buf, mat)
+	assert.Error(t, err)
+}
+
+func Test_mediaRanges(t *testing.T) {
+	assert.Equal(t, []string{".png"}, mediaRanges(".PNG"))
+	assert.Equal(t, []string{"image/webp", "image/png", "*/*"},
+		mediaRanges("image/png; q=0.9, image/webp, text/html;q=0, */*;q=0.8"))
+}
+
+func Test_optionErrors(t *testing.T) {
+	mat := recordMatrix(t, "Test_optionErrors")
+	missing := filepath.Join(t.TempDir(), "missing.png")
+
+	_, err := New(filepath.Join(t.TempDir(), "code.png"), WithHalftone(missing))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.png")
+
+	_, err = NewRenderer(WithLogoImageFile(missing)).Render(mat)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.png")
+
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)}, WithLogoImageFileJPEG(missing))
+	err = w.Write(mat)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.png")
+}
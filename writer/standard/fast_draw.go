@@ -0,0 +1,132 @@
+package standard
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// canDrawFast reports whether the QR code could be drawn by drawFast, which means
+// square modules in opaque solid colors, without gradient, logo, halftone image,
+// finder shape or module color function.
+func (oo *outputImageOptions) canDrawFast() bool {
+	if oo.getShape() != _shapeRectangle || oo.finderShape != nil ||
+		oo.qrGradient != nil || oo.moduleColorFunc != nil ||
+		oo.logo != nil || oo.logoSVG != nil || oo.halftoneImg != nil {
+		return false
+	}
+
+	if !oo.bgTransparent && oo.bgColor.A != 0xff {
+		return false
+	}
+
+	return oo.qrColors.getDataColor(oo.qrColor).A == 0xff &&
+		oo.qrColors.getFinderColor(oo.qrColor).A == 0xff
+}
+
+// drawFast draws the QR code straight into an image.Gray if all colors are opaque
+// gray, or an image.Paletted otherwise. Each row of modules is built once as a row of
+// pixels (runs of modules in the same color are filled at once) and copied blockW
+// times. The output is the same as draw with the same options.
+func drawFast(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
+	top, right, bottom, left := opt.borderWidths[0], opt.borderWidths[1], opt.borderWidths[2], opt.borderWidths[3]
+	blockW := opt.qrBlockWidth()
+	w := mat.Width()*blockW + left + right
+	h := mat.Height()*blockW + top + bottom
+
+	// module width and borders have been resolved to fit the resolution.
+	if opt.resolution != nil && *opt.resolution > 0 {
+		w, h = *opt.resolution, *opt.resolution
+	}
+
+	// palette index of light, data and finder modules.
+	palette := color.Palette{opt.backgroundColor(), opt.qrColors.getDataColor(opt.qrColor), opt.qrColors.getFinderColor(opt.qrColor)}
+
+	var (
+		pix    []uint8
+		stride int
+		values [3]uint8
+		img    image.Image
+	)
+	if isGrayPalette(palette) {
+		gray := image.NewGray(image.Rect(0, 0, w, h))
+		for i, c := range palette {
+			values[i] = c.(color.RGBA).R
+		}
+		pix, stride, img = gray.Pix, gray.Stride, gray
+	} else {
+		paletted := image.NewPaletted(image.Rect(0, 0, w, h), palette)
+		values = [3]uint8{0, 1, 2}
+		pix, stride, img = paletted.Pix, paletted.Stride, paletted
+	}
+
+	if opt.reversed {
+		fill(pix, values[1])
+	} else {
+		fill(pix, values[0])
+	}
+
+	// modules of each row in palette index.
+	dimension := mat.Width()
+	modules := make([]uint8, dimension*mat.Height())
+	mat.Iterate(qrcode.IterDirection_ROW, func(x, y int, v qrcode.QRValue) {
+		switch {
+		case !v.IsSet():
+			modules[y*dimension+x] = 0
+		case v.Type() == qrcode.QRType_FINDER:
+			modules[y*dimension+x] = 2
+		default:
+			modules[y*dimension+x] = 1
+		}
+	})
+
+	rowPix := make([]uint8, dimension*blockW)
+	for y := 0; y < mat.Height(); y++ {
+		row := modules[y*dimension : (y+1)*dimension]
+		for x0 := 0; x0 < dimension; {
+			x1 := x0 + 1
+			for x1 < dimension && row[x1] == row[x0] {
+				x1++
+			}
+			fill(rowPix[x0*blockW:x1*blockW], values[row[x0]])
+			x0 = x1
+		}
+
+		// modules out of the image (too small resolution) are clipped.
+		for i := 0; i < blockW && top+y*blockW+i < h; i++ {
+			offset := (top+y*blockW+i)*stride + left
+			copy(pix[offset:offset+w-left], rowPix)
+		}
+	}
+
+	if opt.frame != nil {
+		return opt.frame.drawAround(img, blockW, opt)
+	}
+
+	return img
+}
+
+// isGrayPalette reports whether all colors in palette are opaque gray.
+func isGrayPalette(palette color.Palette) bool {
+	for _, c := range palette {
+		rgba := c.(color.RGBA)
+		if rgba.A != 0xff || rgba.R != rgba.G || rgba.G != rgba.B {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fill sets all bytes in s to v, by doubling the filled part.
+func fill(s []uint8, v uint8) {
+	if len(s) == 0 {
+		return
+	}
+
+	s[0] = v
+	for filled := 1; filled < len(s); filled *= 2 {
+		copy(s[filled:], s[:filled])
+	}
+}
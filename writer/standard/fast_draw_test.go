@@ -0,0 +1,112 @@
+package standard
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func recordMatrix(t testing.TB, text string, opts ...qrcode.EncodeOption) qrcode.Matrix {
+	qrc, err := qrcode.NewWith(text, opts...)
+	require.NoError(t, err)
+	rec := &matrixRecorder{}
+	require.NoError(t, qrc.Save(rec))
+
+	return rec.mat
+}
+
+func Test_drawFast(t *testing.T) {
+	mat := recordMatrix(t, "Test_drawFast")
+	res := 317
+
+	cases := []struct {
+		name   string
+		opts   []ImageOption
+		isGray bool
+	}{
+		{"default", nil, true},
+		{"colors", []ImageOption{WithFgColorRGBHex("#1f3a93"), WithBgColorRGBHex("#f0e68c"),
+			WithQRColors(&QRColors{Finder: &color.RGBA{R: 0xc0, A: 0xff}})}, false},
+		{"transparent", []ImageOption{WithBgTransparent(), WithBuiltinImageEncoder(PNG_FORMAT)}, false},
+		{"resolution", []ImageOption{WithResolution(&res), WithBorderWidth(7, 3, 11, 5)}, true},
+		{"frame", []ImageOption{WithFrame(Frame{Text: "SCAN ME"})}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			oo := defaultOutputImageOption()
+			for _, opt := range c.opts {
+				opt.apply(oo)
+			}
+			oo = oo.resolve(mat.Width())
+			require.True(t, oo.canDrawFast())
+
+			fast := drawFast(mat, oo)
+			if c.isGray {
+				assert.IsType(t, &image.Gray{}, fast)
+			}
+			want, err := drawWithGG(mat, oo)
+			require.NoError(t, err)
+			assertSameImage(t, want, fast)
+		})
+	}
+
+	oo := defaultOutputImageOption()
+	WithCircleShape().apply(oo)
+	assert.False(t, oo.canDrawFast())
+}
+
+func assertSameImage(t *testing.T, want, got image.Image) {
+	t.Helper()
+	require.Equal(t, want.Bounds(), got.Bounds())
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			g := color.NRGBAModel.Convert(got.At(x, y))
+			if w.(color.NRGBA).A == 0 && g.(color.NRGBA).A == 0 {
+				continue
+			}
+			if !assert.Equal(t, w, g, "pixel (%d, %d)", x, y) {
+				return
+			}
+		}
+	}
+}
+
+func Benchmark_draw(b *testing.B) {
+	for _, ver := range []int{10, 40} {
+		// fill the version with numeric text (capacity at level M).
+		capacity := map[int]int{10: 513, 40: 5596}[ver]
+		mat := recordMatrix(b, strings.Repeat("7", capacity),
+			qrcode.WithVersion(ver), qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium))
+		oo := defaultOutputImageOption().resolve(mat.Width())
+		attr := oo.preCalculateAttribute(mat.Width())
+
+		for _, path := range []struct {
+			name string
+			draw func(qrcode.Matrix, *outputImageOptions) (image.Image, error)
+		}{
+			{"fast", func(m qrcode.Matrix, o *outputImageOptions) (image.Image, error) { return drawFast(m, o), nil }},
+			{"gg", drawWithGG},
+		} {
+			b.Run(fmt.Sprintf("v%d/%s", ver, path.name), func(b *testing.B) {
+				// throughput in pixels.
+				b.SetBytes(int64(attr.W * attr.H))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := path.draw(mat, oo); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package standard
+
+import (
+	"image/color"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// FinderPosition indicates which one of the three finder patterns is being drawn.
+type FinderPosition uint8
+
+const (
+	// FinderTopLeft is the finder pattern at the top-left corner.
+	FinderTopLeft FinderPosition = iota
+	// FinderTopRight is the finder pattern at the top-right corner.
+	FinderTopRight
+	// FinderBottomLeft is the finder pattern at the bottom-left corner.
+	FinderBottomLeft
+	// FinderBottomRight is the finder pattern at the bottom-right corner, only mirrored
+	// QR code has it (instead of FinderBottomLeft).
+	FinderBottomRight
+)
+
+func (p FinderPosition) String() string {
+	switch p {
+	case FinderTopLeft:
+		return "top-left"
+	case FinderTopRight:
+		return "top-right"
+	case FinderBottomLeft:
+		return "bottom-left"
+	case FinderBottomRight:
+		return "bottom-right"
+	}
+
+	return "unknown"
+}
+
+// IFinderShape draws a whole finder pattern (7x7 modules) at once, rather than
+// IShape.DrawFinder which is called for each module of finder patterns. If it is
+// set by WithFinderShape, IShape.DrawFinder would not be called any more.
+type IFinderShape interface {
+	// DrawFinderPattern draws the finder pattern in the area of ctx.
+	DrawFinderPattern(ctx *FinderDrawContext)
+}
+
+// FinderDrawContext is the square area of a finder pattern.
+type FinderDrawContext struct {
+	GraphicsContext
+
+	x, y     float64
+	size     float64
+	position FinderPosition
+
+	color color.Color
+}
+
+// UpperLeft returns the point which indicates the upper left position.
+func (fc *FinderDrawContext) UpperLeft() (dx, dy float64) {
+	return fc.x, fc.y
+}
+
+// Edge returns the edge length of the finder pattern.
+func (fc *FinderDrawContext) Edge() float64 {
+	return fc.size
+}
+
+// ModuleWidth returns the edge length of one module, 1/7 of Edge.
+func (fc *FinderDrawContext) ModuleWidth() float64 {
+	return fc.size / 7
+}
+
+// Position returns which finder pattern is being drawn, it is the corner where the
+// pattern is drawn, mirrored QR code included.
+func (fc *FinderDrawContext) Position() FinderPosition {
+	return fc.position
+}
+
+// Color returns the finder color which is set by WithFinderColor, WithFgColor and etc.
+func (fc *FinderDrawContext) Color() color.Color {
+	return fc.color
+}
+
+// finderDrawContexts returns the draw contexts of the three finder patterns in mat,
+// GraphicsContext should be filled by the caller.
+func finderDrawContexts(mat qrcode.Matrix, blockW, left, top int, opt *outputImageOptions) []*FinderDrawContext {
+	dimension := mat.Width()
+	if dimension < 7 {
+		return nil
+	}
+
+	positions := []struct {
+		x, y     int
+		position FinderPosition
+	}{
+		{0, 0, FinderTopLeft},
+		{dimension - 7, 0, FinderTopRight},
+		{0, dimension - 7, FinderBottomLeft},
+	}
+	if mat.IsMirrored() {
+		// the patterns are flipped horizontally, so are their corners.
+		mirrored := map[FinderPosition]FinderPosition{
+			FinderTopLeft:    FinderTopRight,
+			FinderTopRight:   FinderTopLeft,
+			FinderBottomLeft: FinderBottomRight,
+		}
+		for i := range positions {
+			positions[i].x = dimension - 7 - positions[i].x
+			positions[i].position = mirrored[positions[i].position]
+		}
+	}
+
+	contexts := make([]*FinderDrawContext, 0, len(positions))
+	for _, p := range positions {
+		// dark modules of finder patterns are light in reflectance reversed matrix.
+		c := opt.moduleColor(p.x, p.y, qrcode.QRValue_FINDER_V1)
+		if opt.reversed {
+			c = opt.backgroundColor()
+		}
+		contexts = append(contexts, &FinderDrawContext{
+			x:        float64(p.x*blockW + left),
+			y:        float64(p.y*blockW + top),
+			size:     float64(7 * blockW),
+			position: p.position,
+			color:    c,
+		})
+	}
+
+	return contexts
+}
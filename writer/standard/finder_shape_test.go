@@ -0,0 +1,94 @@
+package standard
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// recordFinderShape records the finder patterns and modules it is asked to draw.
+type recordFinderShape struct {
+	rectangle
+
+	finders  []FinderDrawContext
+	modules  int
+	patterns int
+}
+
+func (r *recordFinderShape) DrawFinder(ctx *DrawContext) {
+	r.modules++
+	r.rectangle.DrawFinder(ctx)
+}
+
+func (r *recordFinderShape) DrawFinderPattern(ctx *FinderDrawContext) {
+	r.patterns++
+	r.finders = append(r.finders, *ctx)
+	ctx.DrawRectangle(ctx.x, ctx.y, ctx.Edge(), ctx.Edge())
+	ctx.SetColor(ctx.Color())
+	ctx.Fill()
+}
+
+func Test_WithFinderShape(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithFinderShape")
+	require.NoError(t, err)
+
+	for _, format := range []formatTyp{PNG_FORMAT, SVG_FORMAT} {
+		shape := &recordFinderShape{}
+		w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+			WithBuiltinImageEncoder(format),
+			WithCustomShape(shape),
+			WithFinderShape(shape),
+			WithQRWidth(10),
+			WithBorderWidth(5),
+		)
+		require.NoError(t, qrc.Save(w))
+
+		assert.Equal(t, 0, shape.modules)
+		require.Equal(t, 3, shape.patterns)
+
+		dimension := float64(qrc.Dimension())
+		assert.Equal(t, FinderTopLeft, shape.finders[0].Position())
+		assert.Equal(t, FinderTopRight, shape.finders[1].Position())
+		assert.Equal(t, FinderBottomLeft, shape.finders[2].Position())
+		x, y := shape.finders[1].UpperLeft()
+		assert.Equal(t, (dimension-7)*10+5, x)
+		assert.Equal(t, 5.0, y)
+		assert.Equal(t, 70.0, shape.finders[1].Edge())
+		assert.Equal(t, 10.0, shape.finders[1].ModuleWidth())
+	}
+}
+
+func Test_WithFinderShape_Mirrored(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_WithFinderShape_Mirrored", qrcode.WithMirror())
+	require.NoError(t, err)
+
+	shape := &recordFinderShape{}
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+		WithFinderShape(shape),
+		WithQRWidth(10),
+		WithBorderWidth(5),
+	)
+	require.NoError(t, qrc.Save(w))
+	require.Equal(t, 3, shape.patterns)
+
+	// positions are the corners where patterns are drawn.
+	dimension := float64(qrc.Dimension())
+	right := (dimension-7)*10 + 5
+	for _, finder := range shape.finders {
+		x, y := finder.UpperLeft()
+		switch finder.Position() {
+		case FinderTopLeft:
+			assert.Equal(t, [2]float64{5, 5}, [2]float64{x, y})
+		case FinderTopRight:
+			assert.Equal(t, [2]float64{right, 5}, [2]float64{x, y})
+		case FinderBottomRight:
+			assert.Equal(t, [2]float64{right, right}, [2]float64{x, y})
+		default:
+			t.Errorf("unexpected position %s", finder.Position())
+		}
+	}
+}
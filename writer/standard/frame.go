@@ -0,0 +1,328 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font/sfnt"
+)
+
+// FrameTemplate is the template of the frame drawn around the QR code.
+type FrameTemplate uint8
+
+const (
+	// FrameRoundedCard puts the QR code on a rounded card, the caption is
+	// placed on the card under the QR code.
+	FrameRoundedCard FrameTemplate = iota + 1
+	// FrameSpeechBubble surrounds the QR code with a rounded border, the caption
+	// is placed in a bubble under it, whose tail points to the QR code.
+	FrameSpeechBubble
+	// FrameBottomBanner surrounds the QR code with a border, the caption is placed
+	// in a banner attached to the bottom.
+	FrameBottomBanner
+	// FrameTopBottomLabels surrounds the QR code with a border, Text is placed in
+	// the top label and SubText is placed in the bottom label.
+	FrameTopBottomLabels
+)
+
+// Frame describes a call-to-action frame with caption text around the QR code.
+// The frame is added outside the borders (quiet zone) of the QR code, so the output
+// image grows to hold it.
+type Frame struct {
+	Template FrameTemplate
+
+	// Text is the main caption, such as "SCAN ME".
+	Text string
+	// SubText is the second caption in smaller size, such as a human-readable URL.
+	SubText string
+
+	// Color is the color of the frame, the foreground color is used if nil.
+	Color color.Color
+	// TextColor is the color of caption text, if nil black or white is chosen
+	// depending on which one contrasts with Color better.
+	TextColor color.Color
+
+	// Font is the content of a TTF/OTF font file to draw caption text, the embedded
+	// Go Bold font is used if empty. Text size is chosen automatically to fit the frame.
+	Font []byte
+
+	// TextAsOutlines draws text as paths rather than <text> elements in SVG output,
+	// so that the output does not depend on the fonts of viewers.
+	TextAsOutlines bool
+}
+
+// frameLabel is a box to place a line of caption text.
+type frameLabel struct {
+	text       string
+	x, y, w, h float64
+}
+
+// frameLayout describes where the QR code and the captions are placed in the
+// image which is expanded to hold the frame.
+type frameLayout struct {
+	width, height int
+	// qrX, qrY is the upper-left position of the QR code image (with its borders).
+	qrX, qrY   int
+	qrW, qrH   int
+	unit       float64
+	labelColor color.Color
+	labels     []frameLabel
+}
+
+// widths returns the widths added by frame in order of top, right, bottom, left.
+func (l *frameLayout) widths() [4]int {
+	return [4]int{l.qrY, l.width - l.qrX - l.qrW, l.height - l.qrY - l.qrH, l.qrX}
+}
+
+// layout calculates the layout of frame around a QR code image of qrW x qrH pixels,
+// unit is the width of one module.
+func (f *Frame) layout(qrW, qrH, unit int, opt *outputImageOptions) *frameLayout {
+	u := float64(unit)
+	l := &frameLayout{qrW: qrW, qrH: qrH, unit: u}
+
+	// main caption takes 3 modules height, sub caption takes 2 modules.
+	mainH, subH := 3*unit, 2*unit
+	labelH := 0
+	if f.Text != "" {
+		labelH += mainH
+	}
+	if f.SubText != "" {
+		labelH += subH
+	}
+
+	addLabels := func(x, y, w int) {
+		if f.Text != "" {
+			l.labels = append(l.labels, frameLabel{text: f.Text, x: float64(x), y: float64(y), w: float64(w), h: float64(mainH)})
+			y += mainH
+		}
+		if f.SubText != "" {
+			l.labels = append(l.labels, frameLabel{text: f.SubText, x: float64(x), y: float64(y), w: float64(w), h: float64(subH)})
+		}
+	}
+
+	switch f.Template {
+	case FrameSpeechBubble:
+		l.qrX, l.qrY = unit, unit
+		l.width = qrW + 2*unit
+		l.height = qrH + 2*unit
+		if labelH > 0 {
+			// gap and tail of the bubble
+			l.height += 2*unit + labelH
+			addLabels(unit, qrH+4*unit, qrW)
+		}
+	case FrameBottomBanner:
+		l.qrX, l.qrY = unit, unit
+		l.width = qrW + 2*unit
+		l.height = qrH + 2*unit + labelH
+		addLabels(unit, qrH+2*unit, qrW)
+	case FrameTopBottomLabels:
+		topH, bottomH := unit, unit
+		if f.Text != "" {
+			topH = mainH
+			l.labels = append(l.labels, frameLabel{text: f.Text, x: u, y: 0, w: float64(qrW), h: float64(mainH)})
+		}
+		if f.SubText != "" {
+			bottomH = mainH
+			l.labels = append(l.labels, frameLabel{text: f.SubText, x: u, y: float64(topH + qrH), w: float64(qrW), h: float64(mainH)})
+		}
+		l.qrX, l.qrY = unit, topH
+		l.width = qrW + 2*unit
+		l.height = topH + qrH + bottomH
+	default:
+		// FrameRoundedCard
+		l.qrX, l.qrY = unit, unit
+		l.width = qrW + 2*unit
+		l.height = qrH + 2*unit + labelH
+		addLabels(unit, qrH+unit, qrW)
+	}
+
+	l.labelColor = f.TextColor
+	if l.labelColor == nil {
+		l.labelColor = color_WHITE
+		if luma(parseFromColor(f.frameColor(opt))) > 128 {
+			l.labelColor = color_BLACK
+		}
+	}
+
+	return l
+}
+
+func (f *Frame) frameColor(opt *outputImageOptions) color.Color {
+	if f.Color != nil {
+		return f.Color
+	}
+
+	return opt.qrColor
+}
+
+// font returns the font to draw captions, the default font is used if Font is empty.
+func (f *Frame) font() (*sfnt.Font, error) {
+	if len(f.Font) != 0 {
+		return sfnt.Parse(f.Font)
+	}
+
+	return defaultFont()
+}
+
+// drawShapes draws the frame shapes (without text) into gc.
+func (f *Frame) drawShapes(gc GraphicsContext, l *frameLayout, opt *outputImageOptions) {
+	var (
+		u      = l.unit
+		w, h   = float64(l.width), float64(l.height)
+		qrX    = float64(l.qrX)
+		qrY    = float64(l.qrY)
+		qrW    = float64(l.qrW)
+		qrH    = float64(l.qrH)
+		radius = 2 * u
+	)
+
+	gc.SetColor(f.frameColor(opt))
+	switch f.Template {
+	case FrameSpeechBubble:
+		ringH := qrH + 2*u
+		gc.SetFillRuleEvenOdd()
+		frameRoundedRectangle(gc, 0, 0, w, ringH, radius)
+		gc.DrawRectangle(qrX, qrY, qrW, qrH)
+		gc.Fill()
+		gc.SetFillRuleWinding()
+		if len(l.labels) == 0 {
+			return
+		}
+
+		bubbleY := ringH + 2*u
+		frameRoundedRectangle(gc, 0, bubbleY, w, h-bubbleY, radius)
+		gc.Fill()
+		// tail
+		gc.MoveTo(w/2-u, bubbleY)
+		gc.LineTo(w/2, bubbleY-u)
+		gc.LineTo(w/2+u, bubbleY)
+		gc.ClosePath()
+		gc.Fill()
+	case FrameBottomBanner, FrameTopBottomLabels:
+		gc.SetFillRuleEvenOdd()
+		gc.DrawRectangle(0, 0, w, h)
+		gc.DrawRectangle(qrX, qrY, qrW, qrH)
+		gc.Fill()
+		gc.SetFillRuleWinding()
+	default:
+		frameRoundedRectangle(gc, 0, 0, w, h, radius)
+		gc.Fill()
+	}
+}
+
+// drawLabels draws caption text as outlines into gc.
+func (f *Frame) drawLabels(gc GraphicsContext, l *frameLayout) {
+	ft, err := f.font()
+	if err != nil {
+		return
+	}
+
+	gc.SetColor(l.labelColor)
+	gc.SetFillRuleWinding()
+	for _, label := range l.labels {
+		size := fitTextSize(ft, label.text, label.w, label.h)
+		baseline := label.y + label.h/2 + capHeight(ft, size)/2
+		drawTextOutline(gc, ft, label.text, size, label.x+label.w/2, baseline)
+		gc.Fill()
+	}
+}
+
+// drawAround draws the frame around qr image and returns the expanded image.
+func (f *Frame) drawAround(qr image.Image, unit int, opt *outputImageOptions) image.Image {
+	l := f.layout(qr.Bounds().Dx(), qr.Bounds().Dy(), unit, opt)
+
+	dc := gg.NewContext(l.width, l.height)
+	dc.SetColor(opt.backgroundColor())
+	dc.DrawRectangle(0, 0, float64(l.width), float64(l.height))
+	dc.Fill()
+
+	gc := &GGContextWrapper{Context: dc}
+	f.drawShapes(gc, l, opt)
+	dc.DrawImage(qr, l.qrX, l.qrY)
+	f.drawLabels(gc, l)
+
+	return dc.Image()
+}
+
+// writeSVGShapes writes the frame shapes into SVG.
+func (f *Frame) writeSVGShapes(w io.Writer, l *frameLayout, opt *outputImageOptions) error {
+	recorder := NewSVGPathRecorder(false)
+	f.drawShapes(recorder, l, opt)
+	_, err := fmt.Fprintf(w, "<g>%s</g>\n", recorder.toSVGPath())
+	return err
+}
+
+// writeSVGLabels writes caption text into SVG, as <text> elements or as outlines.
+func (f *Frame) writeSVGLabels(w io.Writer, l *frameLayout) error {
+	if len(l.labels) == 0 {
+		return nil
+	}
+
+	if f.TextAsOutlines {
+		recorder := NewSVGPathRecorder(false)
+		f.drawLabels(recorder, l)
+		_, err := fmt.Fprintf(w, "<g>%s</g>\n", recorder.toSVGPath())
+		return err
+	}
+
+	ft, err := f.font()
+	if err != nil {
+		return err
+	}
+
+	family := `Go, Helvetica, Arial, sans-serif`
+	if len(f.Font) != 0 {
+		// embed the custom font, so that the text is rendered as expected.
+		family = `qrcode-frame`
+		_, err := fmt.Fprintf(w, "<defs><style>@font-face{font-family:%s;src:url(data:font/ttf;base64,%s);}</style></defs>\n",
+			family, base64.StdEncoding.EncodeToString(f.Font))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, label := range l.labels {
+		size := fitTextSize(ft, label.text, label.w, label.h)
+		baseline := label.y + label.h/2 + capHeight(ft, size)/2
+
+		var text bytes.Buffer
+		if err := xml.EscapeText(&text, []byte(label.text)); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, `<text x="%s" y="%s" font-family="%s" font-weight="bold" font-size="%s" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			svgNumber(label.x+label.w/2), svgNumber(baseline), family, svgNumber(size),
+			colorToHex(l.labelColor), text.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// frameRoundedRectangle adds a rounded rectangle path into gc.
+func frameRoundedRectangle(gc GraphicsContext, x, y, w, h, r float64) {
+	if r > w/2 {
+		r = w / 2
+	}
+	if r > h/2 {
+		r = h / 2
+	}
+
+	gc.MoveTo(x+r, y)
+	gc.LineTo(x+w-r, y)
+	gc.QuadraticTo(x+w, y, x+w, y+r)
+	gc.LineTo(x+w, y+h-r)
+	gc.QuadraticTo(x+w, y+h, x+w-r, y+h)
+	gc.LineTo(x+r, y+h)
+	gc.QuadraticTo(x, y+h, x, y+h-r)
+	gc.LineTo(x, y+r)
+	gc.QuadraticTo(x, y, x+r, y)
+	gc.ClosePath()
+}
@@ -0,0 +1,80 @@
+package standard
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_WithFrame(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithFrame")
+	require.NoError(t, err)
+
+	templates := []FrameTemplate{
+		FrameRoundedCard, FrameSpeechBubble, FrameBottomBanner, FrameTopBottomLabels,
+	}
+	for _, template := range templates {
+		buf := bytes.NewBuffer(nil)
+		w := NewWithWriter(nopCloser{Writer: buf},
+			WithBuiltinImageEncoder(PNG_FORMAT),
+			WithQRWidth(10),
+			WithBorderWidth(40),
+			WithFrame(Frame{Template: template, Text: "SCAN ME", SubText: "example.com"}),
+		)
+		require.NoError(t, qrc.Save(w))
+
+		img, err := png.Decode(buf)
+		require.NoError(t, err)
+		attr := w.Attribute(qrc.Dimension())
+		assert.Equal(t, attr.W, img.Bounds().Dx())
+		assert.Equal(t, attr.H, img.Bounds().Dy())
+		assert.Greater(t, attr.H, qrc.Dimension()*10+80)
+
+		// the quiet zone of the QR code is not covered by the frame.
+		left, top := attr.FrameWidths[3], attr.FrameWidths[0]
+		for i := 0; i < 40; i++ {
+			r, g, b, _ := img.At(left+i, top+i).RGBA()
+			assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+		}
+	}
+}
+
+func Test_WithFrame_SVG(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithFrame_SVG")
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithFrame(Frame{Template: FrameBottomBanner, Text: "SCAN <ME>"}),
+	)
+	require.NoError(t, qrc.Save(w))
+	assert.Contains(t, buf.String(), `>SCAN &lt;ME&gt;</text>`)
+	assert.Contains(t, buf.String(), `<g transform="translate(`)
+
+	buf.Reset()
+	w = NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithFrame(Frame{Template: FrameBottomBanner, Text: "SCAN ME", TextAsOutlines: true}),
+	)
+	require.NoError(t, qrc.Save(w))
+	assert.False(t, strings.Contains(buf.String(), "<text"))
+}
+
+func Test_WithFrame_BadFont(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithFrame_BadFont")
+	require.NoError(t, err)
+
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+		WithFrame(Frame{Text: "SCAN ME", Font: []byte("not a font")}),
+	)
+	err = qrc.Save(w)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parse frame font")
+}
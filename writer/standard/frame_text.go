@@ -0,0 +1,168 @@
+package standard
+
+import (
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	_defaultFontOnce sync.Once
+	_defaultFont     *sfnt.Font
+	_defaultFontErr  error
+)
+
+// defaultFont returns the embedded Go Bold font which is used to draw caption text
+// if no font is specified.
+func defaultFont() (*sfnt.Font, error) {
+	_defaultFontOnce.Do(func() {
+		_defaultFont, _defaultFontErr = sfnt.Parse(gobold.TTF)
+	})
+
+	return _defaultFont, _defaultFontErr
+}
+
+// _measureSize is the font size to measure text, the width of text is linear to the font size.
+const _measureSize = 100
+
+func toFixed(size float64) fixed.Int26_6 {
+	return fixed.Int26_6(size * 64)
+}
+
+func fromFixed(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// textWidth returns the advance width of text in font size.
+func textWidth(f *sfnt.Font, text string, size float64) float64 {
+	var (
+		buf   sfnt.Buffer
+		ppem  = toFixed(size)
+		width fixed.Int26_6
+		prev  sfnt.GlyphIndex
+	)
+
+	for i, r := range []rune(text) {
+		idx, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			if kern, err := f.Kern(&buf, prev, idx, ppem, font.HintingNone); err == nil {
+				width += kern
+			}
+		}
+		if advance, err := f.GlyphAdvance(&buf, idx, ppem, font.HintingNone); err == nil {
+			width += advance
+		}
+		prev = idx
+	}
+
+	return fromFixed(width)
+}
+
+// capHeight returns the height of uppercase letters in font size.
+func capHeight(f *sfnt.Font, size float64) float64 {
+	var buf sfnt.Buffer
+	metrics, err := f.Metrics(&buf, toFixed(size), font.HintingNone)
+	if err != nil || metrics.CapHeight <= 0 {
+		return size * 0.7
+	}
+
+	return fromFixed(metrics.CapHeight)
+}
+
+// fitTextSize returns the largest font size which makes text fit in the box
+// (boxW x boxH), with a little room around.
+func fitTextSize(f *sfnt.Font, text string, boxW, boxH float64) float64 {
+	size := boxH * 0.6
+	if w := textWidth(f, text, _measureSize); w > 0 {
+		if byWidth := boxW * 0.9 * _measureSize / w; byWidth < size {
+			size = byWidth
+		}
+	}
+
+	return size
+}
+
+// drawTextOutline adds the outlines of text into gc as paths, text is centered at cx
+// horizontally and placed on baseline y. The caller should fill the paths.
+func drawTextOutline(gc GraphicsContext, f *sfnt.Font, text string, size, cx, baseline float64) {
+	var (
+		buf  sfnt.Buffer
+		ppem = toFixed(size)
+		prev sfnt.GlyphIndex
+		x    = cx - textWidth(f, text, size)/2
+	)
+
+	for i, r := range []rune(text) {
+		idx, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			continue
+		}
+		if i > 0 {
+			if kern, err := f.Kern(&buf, prev, idx, ppem, font.HintingNone); err == nil {
+				x += fromFixed(kern)
+			}
+		}
+		prev = idx
+
+		segments, err := f.LoadGlyph(&buf, idx, ppem, nil)
+		if err == nil {
+			drawGlyphSegments(gc, segments, x, baseline)
+		}
+		if advance, err := f.GlyphAdvance(&buf, idx, ppem, font.HintingNone); err == nil {
+			x += fromFixed(advance)
+		}
+	}
+}
+
+// drawGlyphSegments adds the segments of a glyph whose origin is (ox, oy) into gc.
+// Cubic curves are approximated by lines since GraphicsContext does not support them.
+func drawGlyphSegments(gc GraphicsContext, segments sfnt.Segments, ox, oy float64) {
+	point := func(p fixed.Point26_6) (float64, float64) {
+		return ox + fromFixed(p.X), oy + fromFixed(p.Y)
+	}
+
+	var (
+		started    bool
+		curX, curY float64
+	)
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			if started {
+				gc.ClosePath()
+			}
+			curX, curY = point(seg.Args[0])
+			gc.MoveTo(curX, curY)
+			started = true
+		case sfnt.SegmentOpLineTo:
+			curX, curY = point(seg.Args[0])
+			gc.LineTo(curX, curY)
+		case sfnt.SegmentOpQuadTo:
+			cx, cy := point(seg.Args[0])
+			curX, curY = point(seg.Args[1])
+			gc.QuadraticTo(cx, cy, curX, curY)
+		case sfnt.SegmentOpCubeTo:
+			x1, y1 := point(seg.Args[0])
+			x2, y2 := point(seg.Args[1])
+			x3, y3 := point(seg.Args[2])
+			const steps = 8
+			x0, y0 := curX, curY
+			for i := 1; i <= steps; i++ {
+				t := float64(i) / steps
+				mt := 1 - t
+				curX = mt*mt*mt*x0 + 3*mt*mt*t*x1 + 3*mt*t*t*x2 + t*t*t*x3
+				curY = mt*mt*mt*y0 + 3*mt*mt*t*y1 + 3*mt*t*t*y2 + t*t*t*y3
+				gc.LineTo(curX, curY)
+			}
+		}
+	}
+	if started {
+		gc.ClosePath()
+	}
+}
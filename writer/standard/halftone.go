@@ -0,0 +1,183 @@
+package standard
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/yeqown/go-qrcode/v2"
+	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
+	drawpkg "golang.org/x/image/draw"
+)
+
+const (
+	_defaultHalftoneGrid      = 3
+	_defaultHalftoneThreshold = 60
+	_minHalftoneGrid          = 2
+	_maxHalftoneGrid          = 5
+
+	// _halftoneDarkLuminance and _halftoneLightLuminance are the relative luminance
+	// the centers of dark and light modules are forced to in color halftone.
+	_halftoneDarkLuminance  = 0.1
+	_halftoneLightLuminance = 0.6
+)
+
+// halftoneOptions decides how the halftone image is drawn into data modules.
+type halftoneOptions struct {
+	// grid is the number of sub-modules in each row and column of a module.
+	grid int
+	// dither and threshold decide the black and white sub-modules.
+	dither    imgkit.DitherMethod
+	threshold uint8
+	// colorful keeps the colors of the halftone image, only the luminance of the
+	// module centers is adjusted.
+	colorful bool
+}
+
+// halftone is the halftone image prepared for a QR code, each pixel of it is a
+// sub-module.
+type halftone struct {
+	halftoneOptions
+
+	// mask is the dithered image, white pixels are light sub-modules.
+	mask *image.Gray
+	// source is the scaled halftone image, for color halftone.
+	source *image.RGBA
+	// protected marks function patterns, which are never halftoned.
+	protected [][]bool
+
+	bgColor       color.RGBA
+	bgTransparent bool
+}
+
+// prepareHalftone scales and dithers the halftone image for mat, each module is
+// blockW pixels. It returns nil if halftone is not enabled or modules are too small
+// to be split.
+func (oo *outputImageOptions) prepareHalftone(mat qrcode.Matrix, blockW int) *halftone {
+	grid := oo.halftone.grid
+	if oo.halftoneImg == nil || blockW < grid {
+		return nil
+	}
+
+	// the quiet zone is not covered by the halftone image.
+	rect := image.Rect(0, 0, mat.Width()*grid, mat.Height()*grid)
+	scaled := imgkit.Scale(oo.halftoneImg, rect, nil).(*image.RGBA)
+
+	h := &halftone{
+		halftoneOptions: oo.halftone,
+		protected:       mat.FunctionPatterns(),
+		bgColor:         oo.bgColor,
+		bgTransparent:   oo.bgTransparent,
+	}
+	// transparent pixels are dithered as the background.
+	flattened := image.NewRGBA(rect)
+	drawpkg.Draw(flattened, rect, &image.Uniform{C: h.opaqueBackground()}, image.Point{}, drawpkg.Src)
+	drawpkg.Draw(flattened, rect, scaled, image.Point{}, drawpkg.Over)
+	h.mask = imgkit.Dither(flattened, h.dither, h.threshold)
+	if h.colorful {
+		h.source = scaled
+	}
+
+	return h
+}
+
+// applies reports whether the module (x, y) is split into sub-modules.
+func (h *halftone) applies(x, y int) bool {
+	return h != nil && !h.protected[y][x]
+}
+
+// cell returns the pixels of sub-module (i, j) of the module which starts at
+// (left, top). The sub-modules tile the module without gaps.
+func (h *halftone) cell(left, top, blockW, i, j int) image.Rectangle {
+	return image.Rect(
+		left+i*blockW/h.grid, top+j*blockW/h.grid,
+		left+(i+1)*blockW/h.grid, top+(j+1)*blockW/h.grid,
+	)
+}
+
+// isCenter reports whether the i-th sub-module (in row or column) touches the center
+// of the module, where scanners sample. With even grid, the middle two are centers.
+func (h *halftone) isCenter(i int) bool {
+	if h.grid%2 == 1 {
+		return 2*i+1 == h.grid
+	}
+
+	return 2*i+2 == h.grid || 2*i == h.grid
+}
+
+// color returns the color of sub-module (i, j) of module (x, y). module is the color
+// of the module and dark reports whether the module is dark.
+func (h *halftone) color(x, y, i, j int, module color.Color, dark bool) color.Color {
+	center := h.isCenter(i) && h.isCenter(j)
+	px, py := x*h.grid+i, y*h.grid+j
+
+	if h.colorful {
+		c := h.source.RGBAAt(px, py)
+		if center {
+			return withLuminance(over(c, h.opaqueBackground()), dark)
+		}
+		if h.bgTransparent {
+			return c
+		}
+		return over(c, h.bgColor)
+	}
+
+	if center {
+		return module
+	}
+	if h.mask.GrayAt(px, py).Y == 255 {
+		if h.bgTransparent {
+			return color.RGBA{}
+		}
+		return color_WHITE
+	}
+
+	return color_BLACK
+}
+
+// opaqueBackground is the background the halftone image is laid on, transparent
+// background is taken as white.
+func (h *halftone) opaqueBackground() color.RGBA {
+	if h.bgTransparent {
+		return color_WHITE
+	}
+
+	c := h.bgColor
+	c.A = 0xff
+	return c
+}
+
+// over composites the premultiplied color c over the opaque color bg.
+func over(c, bg color.RGBA) color.RGBA {
+	rest := 255 - uint32(c.A)
+	return color.RGBA{
+		R: c.R + uint8(uint32(bg.R)*rest/255),
+		G: c.G + uint8(uint32(bg.G)*rest/255),
+		B: c.B + uint8(uint32(bg.B)*rest/255),
+		A: 0xff,
+	}
+}
+
+// withLuminance keeps the hue of the opaque color c, but darkens it under
+// _halftoneDarkLuminance if dark, or lightens it over _halftoneLightLuminance
+// otherwise. Channels are mixed with black or white in linear RGB.
+func withLuminance(c color.RGBA, dark bool) color.RGBA {
+	l := relativeLuminance(c)
+	var mix func(v float64) float64
+	switch {
+	case dark && l > _halftoneDarkLuminance:
+		k := _halftoneDarkLuminance / l
+		mix = func(v float64) float64 { return v * k }
+	case !dark && l < _halftoneLightLuminance:
+		k := (1 - _halftoneLightLuminance) / (1 - l)
+		mix = func(v float64) float64 { return 1 - (1-v)*k }
+	default:
+		return c
+	}
+
+	return color.RGBA{
+		R: srgbChannel(mix(linearChannel(c.R))),
+		G: srgbChannel(mix(linearChannel(c.G))),
+		B: srgbChannel(mix(linearChannel(c.B))),
+		A: 0xff,
+	}
+}
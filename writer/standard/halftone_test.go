@@ -0,0 +1,151 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
+)
+
+// matrixRecorder is a qrcode.Writer which keeps the matrix.
+type matrixRecorder struct {
+	mat qrcode.Matrix
+}
+
+func (r *matrixRecorder) Write(mat qrcode.Matrix) error {
+	r.mat = mat
+	return nil
+}
+
+func (r *matrixRecorder) Close() error { return nil }
+
+func Test_halftone_cell(t *testing.T) {
+	for grid := _minHalftoneGrid; grid <= _maxHalftoneGrid; grid++ {
+		h := &halftone{halftoneOptions: halftoneOptions{grid: grid}}
+
+		// sub-modules tile the module without gaps.
+		area := 0
+		for i := 0; i < grid; i++ {
+			for j := 0; j < grid; j++ {
+				area += h.cell(10, 10, 17, i, j).Dx() * h.cell(10, 10, 17, i, j).Dy()
+			}
+		}
+		assert.Equal(t, 17*17, area, "grid=%d", grid)
+		assert.Equal(t, image.Pt(27, 27), h.cell(10, 10, 17, grid-1, grid-1).Max)
+
+		var centers []int
+		for i := 0; i < grid; i++ {
+			if h.isCenter(i) {
+				centers = append(centers, i)
+			}
+		}
+		if grid%2 == 1 {
+			assert.Equal(t, []int{grid / 2}, centers)
+		} else {
+			assert.Equal(t, []int{grid/2 - 1, grid / 2}, centers)
+		}
+	}
+}
+
+func Test_withLuminance(t *testing.T) {
+	orange := color.RGBA{R: 0xff, G: 0x99, B: 0x33, A: 0xff}
+
+	dark := withLuminance(orange, true)
+	assert.InDelta(t, _halftoneDarkLuminance, relativeLuminance(dark), 0.01)
+	assert.True(t, dark.R > dark.G && dark.G > dark.B, "hue is kept: %v", dark)
+
+	navy := color.RGBA{B: 0x80, A: 0xff}
+	light := withLuminance(navy, false)
+	assert.InDelta(t, _halftoneLightLuminance, relativeLuminance(light), 0.01)
+	assert.True(t, light.B > light.R, "hue is kept: %v", light)
+
+	// colors already dark or light enough are not changed.
+	assert.Equal(t, navy, withLuminance(navy, true))
+	assert.Equal(t, color_WHITE, withLuminance(color_WHITE, false))
+}
+
+func Test_WithHalftoneImage(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_WithHalftoneImage", qrcode.WithVersion(3))
+	require.NoError(t, err)
+
+	gray := solidImage(10, 10, color.Gray{Y: 0x80})
+	oo := defaultOutputImageOption()
+	WithHalftoneImage(gray).apply(oo)
+	WithHalftoneGrid(9).apply(oo)
+	WithHalftoneDither(imgkit.DitherBayer, 128).apply(oo)
+	assert.Equal(t, _maxHalftoneGrid, oo.halftone.grid)
+
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(PNG_FORMAT),
+		WithQRWidth(10),
+		WithBorderWidth(40),
+		WithHalftoneImage(gray),
+		WithHalftoneGrid(5),
+		WithHalftoneDither(imgkit.DitherBayer, 128),
+	)
+	require.NoError(t, qrc.Save(w))
+	img, err := png.Decode(buf)
+	require.NoError(t, err)
+
+	isBlack := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r == 0
+	}
+	// the quiet zone and function patterns are not halftoned. The alignment pattern
+	// of version 3 is centered at (22, 22), its white ring is at (21, 22).
+	assert.False(t, isBlack(5, 5))
+	assert.True(t, isBlack(40+1, 40+1))
+	assert.False(t, isBlack(40+21*10+1, 40+22*10+1))
+	assert.True(t, isBlack(40+22*10+1, 40+22*10+1))
+
+	// data modules are dithered: 50% gray gives both black and white sub-modules.
+	var black, white int
+	for x := 40 + 9*10; x < 40+20*10; x++ {
+		if isBlack(x, 40+10*10+1) {
+			black++
+		} else {
+			white++
+		}
+	}
+	assert.NotZero(t, black)
+	assert.NotZero(t, white)
+}
+
+func Test_WithHalftoneColor(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithHalftoneColor")
+	require.NoError(t, err)
+
+	orange := color.RGBA{R: 0xff, G: 0x99, B: 0x33, A: 0xff}
+	oo := defaultOutputImageOption()
+	WithHalftoneImage(solidImage(10, 10, orange)).apply(oo)
+	WithHalftoneColor().apply(oo)
+
+	rec := &matrixRecorder{}
+	require.NoError(t, qrc.Save(rec))
+	h := oo.prepareHalftone(rec.mat, 9)
+	require.NotNil(t, h)
+
+	// the center of dark modules is darkened, light ones lightened, others are kept.
+	dark := color.RGBAModel.Convert(h.color(10, 10, 1, 1, color_BLACK, true)).(color.RGBA)
+	assert.LessOrEqual(t, relativeLuminance(dark), _halftoneDarkLuminance+0.01)
+	light := color.RGBAModel.Convert(h.color(10, 10, 1, 1, color_WHITE, false)).(color.RGBA)
+	assert.GreaterOrEqual(t, relativeLuminance(light), _halftoneLightLuminance-0.01)
+	assert.Equal(t, color.Color(orange), h.color(10, 10, 0, 1, color_BLACK, true))
+
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithHalftoneImage(solidImage(10, 10, orange)),
+		WithHalftoneColor(),
+	)
+	require.NoError(t, qrc.Save(w))
+	assert.Contains(t, buf.String(), colorToHex(orange))
+}
@@ -11,11 +11,11 @@ import (
 	"image/png"
 	"io"
 	"math"
+	"strconv"
 	"strings"
 
 	"github.com/fogleman/gg"
 	"github.com/yeqown/go-qrcode/v2"
-	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
 )
 
 type formatTyp uint8
@@ -24,6 +24,13 @@ const (
 	JPEG_FORMAT formatTyp = iota
 	PNG_FORMAT
 	SVG_FORMAT
+	GIF_FORMAT
+	BMP_FORMAT
+	// TIFF_FORMAT writes QR code in two colors as bilevel TIFF compressed by CCITT G4.
+	TIFF_FORMAT
+	// PNG_PALETTED_FORMAT writes PNG in the smallest palette (1, 2, 4 or 8-bit) which
+	// the colors of image fit in.
+	PNG_PALETTED_FORMAT
 )
 
 // ImageEncoder is an interface which describes the rule how to encode image.Image into io.Writer
@@ -49,12 +56,30 @@ func (j jpegEncoder) Encode(w io.Writer, img image.Image) error {
 	return jpeg.Encode(w, img, nil)
 }
 
+// EncodeWithOptions writes the DPI into JFIF segment if it's set.
+func (j jpegEncoder) EncodeWithOptions(w io.Writer, img image.Image, opts *outputImageOptions) error {
+	if opts == nil || opts.dpi <= 0 {
+		return j.Encode(w, img)
+	}
+
+	return encodeJPEGWithDPI(w, img, opts.dpi)
+}
+
 type pngEncoder struct{}
 
 func (j pngEncoder) Encode(w io.Writer, img image.Image) error {
 	return png.Encode(w, img)
 }
 
+// EncodeWithOptions writes the DPI into pHYs chunk if it's set.
+func (j pngEncoder) EncodeWithOptions(w io.Writer, img image.Image, opts *outputImageOptions) error {
+	if opts == nil || opts.dpi <= 0 {
+		return j.Encode(w, img)
+	}
+
+	return encodePNGWithDPI(w, img, opts.dpi)
+}
+
 // SVGShape interface for generating SVG path elements
 type SVGShape interface {
 	GenerateSVGPath(ctx *DrawContext, hasGradient bool) string
@@ -301,6 +326,72 @@ func colorToHex(c color.Color) string {
 	return fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B)
 }
 
+// svgFillGroups collects the SVG elements of modules by their fill, so that modules
+// sharing a color are written into one group (rectangles into one path) to keep
+// the SVG file small.
+type svgFillGroups struct {
+	fills    []string
+	elements map[string]*strings.Builder
+	paths    map[string]*strings.Builder
+}
+
+func newSVGFillGroups() *svgFillGroups {
+	return &svgFillGroups{
+		elements: make(map[string]*strings.Builder),
+		paths:    make(map[string]*strings.Builder),
+	}
+}
+
+func (g *svgFillGroups) ensure(fill string) {
+	if _, ok := g.elements[fill]; ok {
+		return
+	}
+
+	g.fills = append(g.fills, fill)
+	g.elements[fill] = &strings.Builder{}
+	g.paths[fill] = &strings.Builder{}
+}
+
+// add appends an SVG element into the group of fill.
+func (g *svgFillGroups) add(fill, element string) {
+	if element == "" {
+		return
+	}
+
+	g.ensure(fill)
+	g.elements[fill].WriteString(element)
+}
+
+// addRect appends a rectangle as sub-path into the shared path of fill.
+func (g *svgFillGroups) addRect(fill string, x, y, w, h float64) {
+	g.ensure(fill)
+	fmt.Fprintf(g.paths[fill], "M%s %sh%sv%sh-%sz",
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), svgNumber(w))
+}
+
+func (g *svgFillGroups) writeTo(w io.Writer) error {
+	for _, fill := range g.fills {
+		if _, err := fmt.Fprintf(w, "<g fill=\"%s\">", fill); err != nil {
+			return err
+		}
+		if d := g.paths[fill].String(); d != "" {
+			if _, err := fmt.Fprintf(w, `<path d="%s"/>`, d); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s</g>\n", g.elements[fill].String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// svgNumber formats f in the shortest way, integers without decimals.
+func svgNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
 func embedLogoAsPNG(w io.Writer, logo image.Image, width, height, logoWidth, logoHeight int) error {
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, logo); err != nil {
@@ -558,6 +649,7 @@ func (s svgEncoder) EncodeMatrix(w io.Writer, mat qrcode.Matrix, opts *outputIma
 	if opts == nil {
 		opts = defaultOutputImageOption()
 	}
+	opts = opts.forMatrix(mat)
 
 	// Optimization: Buffered Writer
 	bw := bufio.NewWriter(w)
@@ -572,24 +664,39 @@ func (s svgEncoder) EncodeMatrix(w io.Writer, mat qrcode.Matrix, opts *outputIma
 	if opts.resolution != nil && *opts.resolution > 0 {
 		svgWidth, svgHeight = *opts.resolution, *opts.resolution
 	}
-	hasLogo := opts.logo != nil
-	var logoValid bool
-	var logoWidth, logoHeight int
-	if hasLogo {
-		bound := opts.logo.Bounds()
-		logoWidth = bound.Max.X - bound.Min.X
-		logoHeight = bound.Max.Y - bound.Min.Y
-		logoValid = validLogoImage(width, height, logoWidth, logoHeight, opts.logoSizeMultiplier)
+	logo, err := opts.layoutLogo(width, height, blockW, left, top, mat.Width())
+	if err != nil {
+		return err
+	}
+	if logo != nil && opts.logoCheck {
+		if err := opts.checkLogo(mat, logo, blockW, left, top); err != nil {
+			return err
+		}
 	}
 
 	svgShape := getSVGShape(opts.getShape())
 
-	var err error
-	if opts.resolution != nil && *opts.resolution > 0 {
+	// the frame expands the SVG, and the QR code is translated into it.
+	var frame *frameLayout
+	viewWidth, viewHeight := width, height
+	if opts.frame != nil {
+		frame = opts.frame.layout(width, height, blockW, opts)
+		viewWidth, viewHeight = frame.width, frame.height
+		svgHeight = svgHeight * viewHeight / height
+		svgWidth = svgWidth * viewWidth / width
+	}
+
+	switch {
+	case opts.dpi > 0:
+		// physical size, the pixels are converted into millimetre.
+		_, err = fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>
+<svg width="%smm" height="%smm" viewBox="0 0 %d %d" shape-rendering="crispEdges" xmlns="http://www.w3.org/2000/svg">
+`, svgNumber(pixelsToMM(viewWidth, opts.dpi)), svgNumber(pixelsToMM(viewHeight, opts.dpi)), viewWidth, viewHeight)
+	case opts.resolution != nil && *opts.resolution > 0:
 		_, err = fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>
 <svg width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges" xmlns="http://www.w3.org/2000/svg">
-`, svgWidth, svgHeight, width, height)
-	} else {
+`, svgWidth, svgHeight, viewWidth, viewHeight)
+	default:
 		_, err = fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>
 <svg width="%d" height="%d" shape-rendering="crispEdges" xmlns="http://www.w3.org/2000/svg">
 `, svgWidth, svgHeight)
@@ -604,8 +711,24 @@ func (s svgEncoder) EncodeMatrix(w io.Writer, mat qrcode.Matrix, opts *outputIma
 		}
 	}
 
-	backgroundColor := opts.backgroundColor()
+	backgroundColor := opts.quietZoneColor()
 	r, g, b, a := backgroundColor.RGBA()
+	if frame != nil {
+		if frameColor := opts.backgroundColor(); frameColor.A != 0 {
+			_, err = fmt.Fprintf(bw, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+				frame.width, frame.height, colorToHex(frameColor))
+			if err != nil {
+				return err
+			}
+		}
+		if err = opts.frame.writeSVGShapes(bw, frame, opts); err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(bw, "<g transform=\"translate(%d %d)\">\n", frame.qrX, frame.qrY); err != nil {
+			return err
+		}
+	}
+
 	if a != 0 {
 		hexColor := fmt.Sprintf("#%02x%02x%02x", uint8(r>>8), uint8(g>>8), uint8(b>>8))
 		_, err = fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>\n`, width, height, hexColor)
@@ -629,101 +752,91 @@ func (s svgEncoder) EncodeMatrix(w io.Writer, mat qrcode.Matrix, opts *outputIma
 		bitmap[y][x] = v.IsSet()
 	})
 
-	if logoValid && opts.logoSafeZone {
-		mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
-			if blockOverlapsLogo(x, y, blockW, left, top, width, height, logoWidth, logoHeight) {
-				bitmap[y][x] = false
-			}
-		})
-	}
+	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
+		if logo.hides(x, y, blockW, left, top) {
+			bitmap[y][x] = false
+		}
+	})
 
-	hasHalftone := opts.halftoneImg != nil
-	var halftoneImg image.Image
-	halftoneW := float64(blockW) / 3.0
+	// halftone is nil if there is no halftone image.
+	halftone := opts.prepareHalftone(mat, blockW)
 
-	if hasHalftone {
-		halftoneImg = imgkit.Binaryzation(
-			imgkit.Scale(opts.halftoneImg, image.Rect(0, 0, mat.Width()*3, mat.Height()*3), nil),
-			60,
-			false,
-		)
-	}
+	groups := newSVGFillGroups()
+	_, isRectangle := svgShape.(svgRectangle)
 
 	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
-		if logoValid && opts.logoSafeZone &&
-			blockOverlapsLogo(x, y, blockW, left, top, width, height, logoWidth, logoHeight) {
+		if logo.hides(x, y, blockW, left, top) {
+			return
+		}
+		if opts.finderShape != nil && v.Type() == qrcode.QRType_FINDER {
 			return
 		}
 
 		blockX := x*blockW + left
 		blockY := y*blockW + top
 		neighbours := getNeighbours(bitmap, x, y)
+		moduleColor, custom := opts.resolveModuleColor(x, y, v)
 		drawCtx := &DrawContext{
 			x:          float64(blockX),
 			y:          float64(blockY),
 			w:          blockW,
 			h:          blockW,
-			color:      opts.translateToRGBA(v),
+			color:      moduleColor,
 			neighbours: neighbours,
 		}
 		// Handle halftone for data modules
-		if hasHalftone && v.Type() == qrcode.QRType_DATA {
-			for i := 0; i < 3; i++ {
-				for j := 0; j < 3; j++ {
-					subX := float64(blockX) + float64(i)*halftoneW
-					subY := float64(blockY) + float64(j)*halftoneW
-
-					var subColor color.Color
-					if i == 1 && j == 1 {
-						subColor = drawCtx.color
-					} else {
-						subColor = halftoneColor(halftoneImg, opts.bgTransparent, x*3+i, y*3+j)
+		if v.Type() == qrcode.QRType_DATA && halftone.applies(x, y) {
+			for i := 0; i < halftone.grid; i++ {
+				for j := 0; j < halftone.grid; j++ {
+					cell := halftone.cell(blockX, blockY, blockW, i, j)
+					subColor := halftone.color(x, y, i, j, drawCtx.color, v.IsSet())
+
+					// sub-blocks in background color are covered by the background.
+					c := color.RGBAModel.Convert(subColor).(color.RGBA)
+					if c.A == 0 || (a != 0 && c == backgroundColor) {
+						continue
 					}
+					subFillStr := colorToHex(c)
 
-					// Get the fill string for this sub-block
-					r, g, b, a := subColor.RGBA()
-					r8, g8, b8, a8 := uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8)
-
-					// Skip fully transparent pixels
-					if a8 == 0 || (r8 == 255 && g8 == 255 && b8 == 255) {
+					if isRectangle {
+						groups.addRect(subFillStr, float64(cell.Min.X), float64(cell.Min.Y),
+							float64(cell.Dx()), float64(cell.Dy()))
 						continue
 					}
 
-					subFillStr := fmt.Sprintf("#%02x%02x%02x", r8, g8, b8)
-
 					// Create a DrawContext for this sub-block
 					ctx2 := &DrawContext{
 						GraphicsContext: drawCtx.GraphicsContext,
-						x:               subX,
-						y:               subY,
-						w:               int(halftoneW),
-						h:               int(halftoneW),
+						x:               float64(cell.Min.X),
+						y:               float64(cell.Min.Y),
+						w:               cell.Dx(),
+						h:               cell.Dy(),
 						color:           subColor,
 						neighbours:      drawCtx.neighbours,
 					}
-
-					// Generate the SVG path for this sub-block using the shape
-					pathData := svgShape.GenerateSVGPath(ctx2, false)
-
-					// Write the sub-block
-					fmt.Fprintf(bw, `<g fill="%s">%s</g>\n`, subFillStr, pathData)
+					groups.add(subFillStr, svgShape.GenerateSVGPath(ctx2, false))
 				}
 			}
 			return
 		}
 
-		if !v.IsSet() {
+		// light modules are covered by the background, unless the module color
+		// function gives them a color of their own, or the quiet zone is dark.
+		if !v.IsSet() && !opts.reversed && !custom {
 			return
 		}
 
 		// Normal block rendering
 		var fillStr string
-		if opts.qrGradient != nil {
+		if opts.qrGradient != nil && v.IsSet() {
 			fillStr = "url(#qrGradient)"
 		} else {
-			blockColor := opts.translateToRGBA(v)
-			r, g, b, _ := blockColor.RGBA()
-			fillStr = fmt.Sprintf("#%02x%02x%02x", uint8(r>>8), uint8(g>>8), uint8(b>>8))
+			fillStr = colorToHex(drawCtx.color)
+		}
+
+		if isRectangle {
+			groups.addRect(fillStr, drawCtx.x, drawCtx.y, float64(blockW), float64(blockW))
+			return
 		}
 
 		var pathData string
@@ -743,21 +856,51 @@ func (s svgEncoder) EncodeMatrix(w io.Writer, mat qrcode.Matrix, opts *outputIma
 				fmt.Fprintf(bw, `<g>%s</g>\n`, pathData)
 			}
 		} else {
-			fmt.Fprintf(bw, `<g fill="%s">%s</g>\n`, fillStr, pathData)
+			groups.add(fillStr, pathData)
 		}
 	})
 
+	if err = groups.writeTo(bw); err != nil {
+		return err
+	}
+
+	if opts.finderShape != nil {
+		for _, fctx := range finderDrawContexts(mat, blockW, left, top, opts) {
+			recorder := NewSVGPathRecorder(false)
+			fctx.GraphicsContext = recorder
+			opts.finderShape.DrawFinderPattern(fctx)
+			pathData := recorder.toSVGPath()
+			// same as raster output, the gradient only replaces the foreground color.
+			if opts.qrGradient != nil {
+				pathData = strings.ReplaceAll(pathData,
+					`fill="`+colorToHex(opts.qrColor)+`"`, `fill="url(#qrGradient)"`)
+			}
+			if _, err = fmt.Fprintf(bw, "<g>%s</g>\n", pathData); err != nil {
+				return err
+			}
+		}
+	}
+
 	_, err = fmt.Fprintf(bw, `</g>\n`)
 	if err != nil {
 		return err
 	}
 
-	if logoValid {
-		if err := embedLogoAsPNG(bw, opts.logo, width, height, logoWidth, logoHeight); err != nil {
-			// Skip logo if encoding fails
+	if logo != nil {
+		if err = opts.writeSVGLogo(bw, logo); err != nil {
+			return err
+		}
+	}
+
+	if frame != nil {
+		if _, err = fmt.Fprint(bw, "</g>\n"); err != nil {
+			return err
+		}
+		if err = opts.frame.writeSVGLabels(bw, frame); err != nil {
+			return err
 		}
 	}
 
 	_, err = fmt.Fprintf(bw, `</svg>`)
 	return err
-}
\ No newline at end of file
+}
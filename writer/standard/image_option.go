@@ -6,6 +6,7 @@ import (
 	"image/color"
 
 	"github.com/yeqown/go-qrcode/v2"
+	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
 )
 
 // QRColors represents the color configuration for QR code elements.
@@ -75,6 +76,12 @@ func defaultOutputImageOption() *outputImageOptions {
 		imageEncoder:       jpegEncoder{},
 		borderWidths:       [4]int{_defaultPadding, _defaultPadding, _defaultPadding, _defaultPadding},
 		resolution:         nil,
+		quietZone:          -1,
+		halftone: halftoneOptions{
+			grid:      _defaultHalftoneGrid,
+			dither:    imgkit.DitherThreshold,
+			threshold: _defaultHalftoneThreshold,
+		},
 	}
 }
 
@@ -84,6 +91,12 @@ type outputImageOptions struct {
 	bgColor color.RGBA
 	// bgTransparent only affects on PNG_FORMAT
 	bgTransparent bool
+	// reversed is set by forMatrix if the matrix is reflectance reversed.
+	reversed bool
+
+	// err is the first error of options, such as a logo file could not be read, it's
+	// returned when the writer is created or draws.
+	err error
 
 	// qrColor is the foreground color of the QR code.
 	qrColor color.RGBA
@@ -95,6 +108,10 @@ type outputImageOptions struct {
 	// qrGradient is an optional linear gradient to apply to QR modules instead of a solid color.
 	qrGradient *LinearGradient
 
+	// moduleColorFunc decides the color of each module, it takes precedence over
+	// qrColor, qrColors and bgColor. nil means not set.
+	moduleColorFunc ModuleColorFunc
+
 	// logo this icon image would be put the center of QR Code image
 	// NOTE: logo only should have 1 / logoSizeMultiplier size of QRCode image
 	logo image.Image
@@ -105,12 +122,39 @@ type outputImageOptions struct {
 	// preventing QR code blocks from being drawn underneath it.
 	logoSafeZone bool
 
+	// logoSVG is the logo in SVG format, it's embedded as vector in SVG output,
+	// and logo is used as the fallback in raster output.
+	logoSVG *logoSVG
+
+	// logoPlate is drawn under the logo, nil means no plate.
+	logoPlate *logoPlate
+
+	// logoCrop crops the logo into circle or rounded rectangle.
+	logoCrop LogoShape
+
+	// logoScale scales the logo to the fraction of the QR code width, 0 means
+	// the logo is drawn in its original size.
+	logoScale float64
+
+	// logoCheck refuses to render if the logo damages more codewords than the
+	// error correction capacity.
+	logoCheck bool
+
+	// lintDPI is the target DPI to check the printed module size, 0 means not to check.
+	lintDPI int
+
+	// strictLint refuses to render if Lint finds any error.
+	strictLint bool
+
 	// qrWidth width of each qr block
 	qrWidth int
 
 	// shape means how to draw the shape of each cell.
 	shape IShape
 
+	// finderShape draws the whole finder patterns instead of shape.DrawFinder.
+	finderShape IFinderShape
+
 	// imageEncoder specify which file format would be encoded the QR image.
 	imageEncoder ImageEncoder
 
@@ -118,43 +162,115 @@ type outputImageOptions struct {
 	// top, right, bottom, left same as the WithBorder
 	borderWidths [4]int
 
+	// quietZone is the border width in modules on all sides, it overrides borderWidths
+	// if it's not negative.
+	quietZone int
+
+	// physicalSize is the target width (quiet zone included) in millimetre at dpi,
+	// 0 means not set.
+	physicalSize float64
+
+	// dpi is written into PNG and JPEG as metadata, and decides the physical size of
+	// SVG. 0 means not set.
+	dpi int
+
+	// frame is drawn around the QR code (out of borders) with caption text.
+	frame *Frame
+
 	// halftoneImg is the halftone image for the output image.
 	halftoneImg image.Image
+	// halftone decides the sub-module grid, dithering and colors of halftoneImg.
+	halftone halftoneOptions
 
 	// resolution: for raster (PNG/JPEG) the QR is drawn at that size natively (block size derived to fit res×res) for sharp output; for SVG the element is res×res with a viewBox.
 	resolution *int
+
+	// resolvedDimension is the dimension which module width and borders have been
+	// resolved for, 0 means not resolved.
+	resolvedDimension int
 }
 
+// backgroundColor returns the background color, options are not modified so that
+// they could be shared by concurrent renderings.
 func (oo *outputImageOptions) backgroundColor() color.RGBA {
 	if oo == nil {
 		return color_WHITE
 	}
 
 	if oo.bgTransparent {
-		(&oo.bgColor).A = 0x00
+		// color.RGBA is pre-multiplied by alpha, so RGB are 0 when fully transparent.
+		return color.RGBA{}
 	}
 
 	return oo.bgColor
 }
 
-func (oo *outputImageOptions) logoImage() image.Image {
-	if oo == nil || oo.logo == nil {
-		return nil
+// fail records the error of an option, only the first one is kept.
+func (oo *outputImageOptions) fail(err error) {
+	if oo.err == nil {
+		oo.err = err
+	}
+}
+
+// quietZoneColor returns the color of the quiet zone, it is the dark color (data color)
+// for reflectance reversed matrix, and the background color otherwise.
+func (oo *outputImageOptions) quietZoneColor() color.RGBA {
+	if oo != nil && oo.reversed {
+		return oo.qrColors.getDataColor(oo.qrColor)
+	}
+
+	return oo.backgroundColor()
+}
+
+// forMatrix returns options to draw mat. Light modules of reflectance reversed matrix
+// are drawn over the dark quiet zone, so the background could not be transparent.
+func (oo *outputImageOptions) forMatrix(mat qrcode.Matrix) *outputImageOptions {
+	if !mat.IsReflectanceReversed() || oo.reversed {
+		return oo
 	}
 
-	return oo.logo
+	cp := *oo
+	cp.reversed = true
+	cp.bgTransparent = false
+	return &cp
 }
 
-// qrBlockWidth returns the pixel size of each QR module. The 255 cap matches WithQRWidth(uint8).
-// When block size is derived from resolution in draw(), it is not limited to 255.
+// qrBlockWidth returns the pixel size of each QR module. WithQRWidth(uint8) limits it to 255,
+// but the block size derived from physical size or resolution in resolve() is not limited.
 func (oo *outputImageOptions) qrBlockWidth() int {
-	if oo == nil || (oo.qrWidth <= 0 || oo.qrWidth > 255) {
+	if oo == nil || oo.qrWidth <= 0 {
 		return 20
 	}
 
 	return oo.qrWidth
 }
 
+// drawBlockWidth returns the pixel size of each module in raster output, it is derived
+// from resolution (if set) to fit the QR code of dimension modules in resolution×resolution.
+func (oo *outputImageOptions) drawBlockWidth(dimension int) int {
+	blockW := oo.qrBlockWidth()
+	if oo.resolution == nil || *oo.resolution <= 0 || dimension <= 0 {
+		return blockW
+	}
+
+	top, right, bottom, left := oo.borderWidths[0], oo.borderWidths[1], oo.borderWidths[2], oo.borderWidths[3]
+	availableW := *oo.resolution - left - right
+	availableH := *oo.resolution - top - bottom
+	if availableW <= 0 || availableH <= 0 {
+		return blockW
+	}
+
+	blockW = availableW / dimension
+	if blockH := availableH / dimension; blockH < blockW {
+		blockW = blockH
+	}
+	if blockW < 1 {
+		blockW = 1
+	}
+
+	return blockW
+}
+
 func (oo *outputImageOptions) getShape() IShape {
 	if oo == nil || oo.shape == nil {
 		return _shapeRectangle
@@ -169,13 +285,30 @@ func (oo *outputImageOptions) preCalculateAttribute(dimension int) *Attribute {
 		return nil
 	}
 
+	oo = oo.resolve(dimension)
 	top, right, bottom, left := oo.borderWidths[0], oo.borderWidths[1], oo.borderWidths[2], oo.borderWidths[3]
-	return &Attribute{
+	attr := &Attribute{
 		W:          dimension*oo.qrBlockWidth() + right + left,
 		H:          dimension*oo.qrBlockWidth() + top + bottom,
 		Borders:    oo.borderWidths,
 		BlockWidth: oo.qrBlockWidth(),
+		DPI:        oo.dpi,
+	}
+	if oo.isRaster() && oo.resolution != nil && *oo.resolution > 0 {
+		attr.W, attr.H = *oo.resolution, *oo.resolution
 	}
+
+	if oo.frame != nil {
+		layout := oo.frame.layout(attr.W, attr.H, attr.BlockWidth, oo)
+		attr.W, attr.H = layout.width, layout.height
+		attr.FrameWidths = layout.widths()
+	}
+
+	attr.WidthMM = pixelsToMM(attr.W, oo.dpi)
+	attr.HeightMM = pixelsToMM(attr.H, oo.dpi)
+	attr.ModuleMM = pixelsToMM(attr.BlockWidth, oo.dpi)
+
+	return attr
 }
 
 var (
@@ -204,15 +337,7 @@ func (oo *outputImageOptions) translateToRGBA(v qrcode.QRValue) (rgba color.RGBA
 	// or some special flag.
 
 	if !v.IsSet() {
-		if oo.bgTransparent {
-			(&oo.bgColor).A = 0x00
-			// color.RGBA is pre-multiplied by alpha, so set RGB to 0 when fully transparent.
-			(&oo.bgColor).R = 0x00
-			(&oo.bgColor).G = 0x00
-			(&oo.bgColor).B = 0x00
-		}
-		rgba = oo.bgColor
-		return rgba
+		return oo.backgroundColor()
 	}
 
 	if v.Type() == qrcode.QRType_FINDER {
@@ -224,8 +349,37 @@ func (oo *outputImageOptions) translateToRGBA(v qrcode.QRValue) (rgba color.RGBA
 	return rgba
 }
 
+// moduleColor returns the color of the module at (x, y). moduleColorFunc is preferred,
+// translateToRGBA is used if it is not set or returns nil.
+func (oo *outputImageOptions) moduleColor(x, y int, v qrcode.QRValue) color.RGBA {
+	c, _ := oo.resolveModuleColor(x, y, v)
+	return c
+}
+
+// resolveModuleColor is moduleColor which also reports whether the color is given by
+// moduleColorFunc, so that callers needn't call the function again.
+func (oo *outputImageOptions) resolveModuleColor(x, y int, v qrcode.QRValue) (color.RGBA, bool) {
+	if oo.moduleColorFunc != nil {
+		if c := oo.moduleColorFunc(x, y, v.Type(), v.IsSet()); c != nil {
+			return parseFromColor(c), true
+		}
+	}
+
+	return oo.translateToRGBA(v), false
+}
+
 // parseFromHex convert hex string into color.RGBA
 func parseFromHex(s string) color.RGBA {
+	c, err := parseHex(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// parseHex parses "#rrggbb" or "#rgb" into color.RGBA.
+func parseHex(s string) (color.RGBA, error) {
 	c := color.RGBA{
 		R: 0,
 		G: 0,
@@ -246,11 +400,8 @@ func parseFromHex(s string) color.RGBA {
 	default:
 		err = fmt.Errorf("invalid length, must be 7 or 4")
 	}
-	if err != nil {
-		panic(err)
-	}
 
-	return c
+	return c, err
 }
 
 func parseFromColor(c color.Color) color.RGBA {
@@ -153,6 +153,15 @@ func WithQRColorRGBHex(hex string) ImageOption {
 	})
 }
 
+// WithModuleColorFunc sets a function to decide the color of each module by its
+// position and type, such as taking colors from an image (ModuleColorsFromImage).
+// It takes precedence over WithFgColor, WithBgColor and WithQRColors.
+func WithModuleColorFunc(fn ModuleColorFunc) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.moduleColorFunc = fn
+	})
+}
+
 // WithFgGradient QR gradient
 func WithFgGradient(g *LinearGradient) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
@@ -181,14 +190,14 @@ func WithLogoImageFileJPEG(f string) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
 		fd, err := os.Open(f)
 		if err != nil {
-			fmt.Printf("could not open file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("open logo file(%s): %w", f, err))
 			return
 		}
 		defer fd.Close()
 
 		img, err := jpeg.Decode(fd)
 		if err != nil {
-			fmt.Printf("could not open file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("decode logo file(%s) as JPEG: %w", f, err))
 			return
 		}
 
@@ -202,14 +211,14 @@ func WithLogoImageFilePNG(f string) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
 		fd, err := os.Open(f)
 		if err != nil {
-			fmt.Printf("Open file(%s) failed: %v\n", f, err)
+			oo.fail(fmt.Errorf("open logo file(%s): %w", f, err))
 			return
 		}
 		defer fd.Close()
 
 		img, err := png.Decode(fd)
 		if err != nil {
-			fmt.Printf("Decode file(%s) as PNG failed: %v\n", f, err)
+			oo.fail(fmt.Errorf("decode logo file(%s) as PNG: %w", f, err))
 			return
 		}
 
@@ -225,13 +234,13 @@ func WithLogoImageAdaptiveFileJPEG(f string, logoSizeMultiplier int, qrWidth uin
 	return newFuncOption(func(oo *outputImageOptions) {
 		fd, err := os.Open(f)
 		if err != nil {
-			fmt.Printf("could not open file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("open logo file(%s): %w", f, err))
 			return
 		}
 		defer fd.Close()
 		img, err := jpeg.Decode(fd)
 		if err != nil {
-			fmt.Printf("could not decode JPEG file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("decode logo file(%s) as JPEG: %w", f, err))
 			return
 		}
 
@@ -279,13 +288,13 @@ func WithLogoImageAdaptiveFilePNG(f string, logoSizeMultiplier int, qrWidth uint
 	return newFuncOption(func(oo *outputImageOptions) {
 		fd, err := os.Open(f)
 		if err != nil {
-			fmt.Printf("could not open file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("open logo file(%s): %w", f, err))
 			return
 		}
 		defer fd.Close()
 		img, err := png.Decode(fd)
 		if err != nil {
-			fmt.Printf("could not decode PNG file(%s), error=%v\n", f, err)
+			oo.fail(fmt.Errorf("decode logo file(%s) as PNG: %w", f, err))
 			return
 		}
 
@@ -345,9 +354,20 @@ func WithCustomShape(shape IShape) ImageOption {
 	})
 }
 
-// WithBuiltinImageEncoder option includes: JPEG_FORMAT as default, PNG_FORMAT, SVG_FORMAT.
+// WithFinderShape draws each finder pattern as a whole with shape, such as the
+// eyes in shapes package, instead of drawing finder modules one by one.
+func WithFinderShape(shape IFinderShape) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.finderShape = shape
+	})
+}
+
+// WithBuiltinImageEncoder option includes: JPEG_FORMAT as default, PNG_FORMAT, SVG_FORMAT,
+// GIF_FORMAT, BMP_FORMAT, TIFF_FORMAT and PNG_PALETTED_FORMAT.
 // This works like WithBuiltinImageEncoder, the different between them is
-// formatTyp is enumerated in (JPEG_FORMAT, PNG_FORMAT, SVG_FORMAT)
+// formatTyp is enumerated in (JPEG_FORMAT, PNG_FORMAT, SVG_FORMAT, GIF_FORMAT, BMP_FORMAT,
+// TIFF_FORMAT, PNG_PALETTED_FORMAT). GIF, BMP, TIFF and paletted PNG are written in the
+// palette of colors used in the image, so that plain QR code is written as 1-bit image.
 func WithBuiltinImageEncoder(format formatTyp) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
 		var encoder ImageEncoder
@@ -358,6 +378,14 @@ func WithBuiltinImageEncoder(format formatTyp) ImageOption {
 			encoder = pngEncoder{}
 		case SVG_FORMAT:
 			encoder = svgEncoder{}
+		case GIF_FORMAT:
+			encoder = gifEncoder{}
+		case BMP_FORMAT:
+			encoder = bmpEncoder{}
+		case TIFF_FORMAT:
+			encoder = tiffEncoder{}
+		case PNG_PALETTED_FORMAT:
+			encoder = pngPalettedEncoder{}
 		default:
 			panic("Not supported file format")
 		}
@@ -406,12 +434,30 @@ func WithBorderWidth(widths ...int) ImageOption {
 	})
 }
 
-// WithHalftone ...
+// WithFrame draws a call-to-action frame with caption text around the QR code,
+// such as a "SCAN ME" banner. The output image grows to hold the frame, the borders
+// (quiet zone) of the QR code are kept. It fails if Font could not be parsed.
+func WithFrame(frame Frame) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if frame.Template == 0 {
+			frame.Template = FrameRoundedCard
+		}
+		if _, err := frame.font(); err != nil {
+			oo.fail(fmt.Errorf("parse frame font: %w", err))
+			return
+		}
+
+		oo.frame = &frame
+	})
+}
+
+// WithHalftone reads the image at path and draws it into data modules as halftone,
+// see WithHalftoneImage.
 func WithHalftone(path string) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
 		srcImg, err := imgkit.Read(path)
 		if err != nil {
-			fmt.Println("Read halftone image failed: ", err)
+			oo.fail(fmt.Errorf("read halftone image(%s): %w", path, err))
 			return
 		}
 
@@ -419,6 +465,50 @@ func WithHalftone(path string) ImageOption {
 	})
 }
 
+// WithHalftoneImage draws img into data modules as halftone: each module is split
+// into sub-modules which follow the image, except the center ones which keep the
+// module color for scanners. Function patterns and the quiet zone are not halftoned.
+func WithHalftoneImage(img image.Image) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.halftoneImg = img
+	})
+}
+
+// WithHalftoneGrid splits each module into n×n sub-modules, n is from 2 to 5 and 3
+// by default. With even n, the middle 2×2 sub-modules are the center.
+func WithHalftoneGrid(n int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if n < _minHalftoneGrid {
+			n = _minHalftoneGrid
+		}
+		if n > _maxHalftoneGrid {
+			n = _maxHalftoneGrid
+		}
+
+		oo.halftone.grid = n
+	})
+}
+
+// WithHalftoneDither decides how the halftone image is turned into black and white
+// sub-modules, threshold (0-255) is the gray level between black and white. The
+// default is imgkit.DitherThreshold at 60, imgkit.DitherBayer, imgkit.DitherFloydSteinberg
+// and imgkit.DitherAtkinson look smoother with threshold 128.
+func WithHalftoneDither(method imgkit.DitherMethod, threshold uint8) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.halftone.dither = method
+		oo.halftone.threshold = threshold
+	})
+}
+
+// WithHalftoneColor keeps the colors of the halftone image in sub-modules, the center
+// sub-modules are darkened or lightened (hue kept) to the luminance of dark or light
+// modules.
+func WithHalftoneColor() ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.halftone.colorful = true
+	})
+}
+
 // WithLogoSizeMultiplier used in Writer in validLogoImage method to validate logo size
 func WithLogoSizeMultiplier(multiplier int) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
@@ -433,6 +523,148 @@ func WithLogoSafeZone() ImageOption {
 	})
 }
 
+// WithLogoImageFile loads the logo from file, the file could be in any format
+// registered in image package (JPEG, PNG, GIF, BMP, TIFF and WebP are registered
+// by this package) or SVG (file with .svg extension).
+func WithLogoImageFile(f string) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		img, svg, err := readLogoFile(f)
+		if err != nil {
+			oo.fail(fmt.Errorf("load logo file(%s): %w", f, err))
+			return
+		}
+
+		if svg != nil {
+			oo.logoSVG = svg
+			return
+		}
+		oo.logo = img
+	})
+}
+
+// WithLogoSVG uses the SVG source as the logo, it's embedded as vector in SVG
+// output. The fallback image is drawn in raster output (PNG, JPEG and etc.) since
+// SVG could not be rasterized, it could be nil if only SVG output is needed.
+func WithLogoSVG(svg []byte, fallback image.Image) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		logo, err := parseLogoSVG(svg)
+		if err != nil {
+			oo.fail(fmt.Errorf("parse logo svg: %w", err))
+			return
+		}
+
+		oo.logoSVG = logo
+		if fallback != nil {
+			oo.logo = fallback
+		}
+	})
+}
+
+// WithLogoPlate draws a plate in shape and color under the logo, padding is the
+// pixels the plate extends out of the logo. If the safe zone is enabled, the plate
+// covers the whole safe zone. The opaque background color is used if c is nil.
+func WithLogoPlate(shape LogoShape, c color.Color, padding int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if padding < 0 {
+			padding = 0
+		}
+
+		oo.logoPlate = &logoPlate{shape: shape, color: c, padding: padding}
+	})
+}
+
+// WithLogoCrop crops the logo into LogoCircle or LogoRounded, LogoSquare means
+// no crop.
+func WithLogoCrop(shape LogoShape) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.logoCrop = shape
+	})
+}
+
+// WithLogoScale scales the logo (keeping the aspect ratio) so that its longer edge
+// is fraction of the QR code width, fraction should be in (0, 1). The scaled logo
+// is not limited by WithLogoSizeMultiplier, so keep an eye on the error correction
+// level.
+func WithLogoScale(fraction float64) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if fraction <= 0 || fraction >= 1 {
+			return
+		}
+
+		oo.logoScale = fraction
+	})
+}
+
+// WithLogoCheck makes Writer refuse to render (returns an error) if the logo is
+// not safe, which means it covers more codewords than the error correction
+// capacity of some block, or covers the finder patterns, timing patterns or format
+// info. Use CheckLogo to get the details and the suggested logo size.
+func WithLogoCheck() ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.logoCheck = true
+	})
+}
+
+// WithLintDPI sets the target DPI which the QR code would be printed at, Lint checks
+// whether the printed modules are large enough to be scanned.
+func WithLintDPI(dpi int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if dpi <= 0 {
+			return
+		}
+
+		oo.lintDPI = dpi
+	})
+}
+
+// WithStrictLint makes Writer refuse to render (returns an error) if Lint finds any
+// error level problem, such as too low contrast or no quiet zone.
+func WithStrictLint() ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		oo.strictLint = true
+	})
+}
+
+// WithQuietZone sets the border width of all sides in modules, it overrides
+// WithBorderWidth. ISO/IEC 18004 requires at least 4 modules.
+func WithQuietZone(modules int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if modules < 0 {
+			return
+		}
+
+		oo.quietZone = modules
+	})
+}
+
+// WithPhysicalSize sets the target width (quiet zone included) in millimetre when
+// printed at dpi, for example, WithPhysicalSize(25, 300) for 25mm at 300 DPI. The
+// integer pixels per module which fits the target size is picked, so the real size
+// may be a little smaller, check Writer.Attribute for it. The quiet zone is 4 modules
+// if WithQuietZone is not set. It also sets the DPI as WithDPI does.
+func WithPhysicalSize(mm float64, dpi int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if mm <= 0 || dpi <= 0 {
+			return
+		}
+
+		oo.physicalSize = mm
+		oo.dpi = dpi
+	})
+}
+
+// WithDPI writes the DPI into output as metadata: pHYs chunk of PNG, JFIF density of
+// JPEG, and the width and height in millimetre of SVG.
+func WithDPI(dpi int) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		if dpi <= 0 {
+			return
+		}
+
+		oo.dpi = dpi
+	})
+}
+
 // WithResolution sets the output image size to resolution×resolution. For PNG/JPEG the QR is drawn at that size natively (sharp). For SVG the element is res×res with a viewBox.
 func WithResolution(resolution *int) ImageOption {
 	return newFuncOption(func(oo *outputImageOptions) {
@@ -0,0 +1,111 @@
+package imgkit
+
+import (
+	"image"
+	"image/color"
+)
+
+// DitherMethod decides how gray levels are turned into black and white pixels.
+type DitherMethod uint8
+
+const (
+	// DitherThreshold sets pixels brighter than the threshold to white, others to black.
+	DitherThreshold DitherMethod = iota
+	// DitherBayer is the ordered dithering with 4x4 Bayer matrix.
+	DitherBayer
+	// DitherFloydSteinberg diffuses the whole quantization error to 4 neighbours.
+	DitherFloydSteinberg
+	// DitherAtkinson diffuses 3/4 of the quantization error to 6 neighbours, which
+	// keeps more contrast than Floyd–Steinberg.
+	DitherAtkinson
+)
+
+// bayer4 is the 4x4 Bayer threshold matrix, values are from 0 to 15.
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// diffusion is an error diffusion kernel, weights are divided by divisor.
+type diffusion struct {
+	divisor float64
+	offsets []struct {
+		dx, dy int
+		weight float64
+	}
+}
+
+var (
+	floydSteinberg = diffusion{
+		divisor: 16,
+		offsets: []struct {
+			dx, dy int
+			weight float64
+		}{{1, 0, 7}, {-1, 1, 3}, {0, 1, 5}, {1, 1, 1}},
+	}
+
+	atkinson = diffusion{
+		divisor: 8,
+		offsets: []struct {
+			dx, dy int
+			weight float64
+		}{{1, 0, 1}, {2, 0, 1}, {-1, 1, 1}, {0, 1, 1}, {1, 1, 1}, {0, 2, 1}},
+	}
+)
+
+// Dither converts src into a black and white image with method. threshold (0-255) is
+// the gray level between black and white, other methods than DitherThreshold spread
+// the pixels around it. The returned image has the same bounds as src.
+func Dither(src image.Image, method DitherMethod, threshold uint8) *image.Gray {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := image.NewGray(bounds)
+
+	// gray levels in row order, error diffusion updates them in place.
+	levels := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.GrayModel.Convert(src.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			levels[y*width+x] = float64(c.Y)
+		}
+	}
+
+	var kernel *diffusion
+	switch method {
+	case DitherFloydSteinberg:
+		kernel = &floydSteinberg
+	case DitherAtkinson:
+		kernel = &atkinson
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			level, t := levels[y*width+x], float64(threshold)
+			if method == DitherBayer {
+				t += ((bayer4[y%4][x%4]+0.5)/16 - 0.5) * 255
+			}
+
+			out := 0.0
+			if level > t {
+				out = 255
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(out)
+
+			if kernel == nil {
+				continue
+			}
+			quantErr := (level - out) / kernel.divisor
+			for _, o := range kernel.offsets {
+				nx, ny := x+o.dx, y+o.dy
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+				levels[ny*width+nx] += quantErr * o.weight
+			}
+		}
+	}
+
+	return dst
+}
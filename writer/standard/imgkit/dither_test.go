@@ -0,0 +1,45 @@
+package imgkit_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
+)
+
+func TestDither(t *testing.T) {
+	// 25% gray, with non-zero origin.
+	src := image.NewGray(image.Rect(5, 5, 37, 37))
+	for i := range src.Pix {
+		src.Pix[i] = 0x40
+	}
+
+	whites := func(img *image.Gray) int {
+		n := 0
+		for _, p := range img.Pix {
+			assert.Contains(t, []uint8{0, 255}, p)
+			if p == 255 {
+				n++
+			}
+		}
+		return n
+	}
+
+	out := imgkit.Dither(src, imgkit.DitherThreshold, 60)
+	assert.Equal(t, src.Bounds(), out.Bounds())
+	assert.Equal(t, 32*32, whites(out))
+	assert.Zero(t, whites(imgkit.Dither(src, imgkit.DitherThreshold, 128)))
+
+	// the others keep the average gray level.
+	for _, method := range []imgkit.DitherMethod{imgkit.DitherBayer, imgkit.DitherFloydSteinberg} {
+		out = imgkit.Dither(src, method, 128)
+		assert.InDelta(t, 32*32/4, whites(out), 32, "method=%d", method)
+	}
+	// Atkinson loses 1/4 of the error, so the light pixels are fewer.
+	out = imgkit.Dither(src, imgkit.DitherAtkinson, 128)
+	assert.Greater(t, whites(out), 32*32/8)
+	assert.Equal(t, color.Gray{Y: 0}, out.GrayAt(5, 5))
+}
@@ -0,0 +1,285 @@
+package standard
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// LintSeverity is the severity of a lint finding.
+type LintSeverity uint8
+
+const (
+	// LintInfo is a hint which does not affect scanning in most cases.
+	LintInfo LintSeverity = iota
+	// LintWarning means some scanners may fail to read the QR code.
+	LintWarning
+	// LintError means most scanners would fail to read the QR code.
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+
+	return "unknown"
+}
+
+// LintFinding is a readability problem found by Lint.
+type LintFinding struct {
+	Severity LintSeverity
+	// Check is the name of the check, such as "contrast" and "quiet-zone".
+	Check string
+	// Message describes the problem and how to fix it.
+	Message string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", f.Severity, f.Check, f.Message)
+}
+
+const (
+	// _lintMinContrast and _lintGoodContrast are the luminance contrast ratios,
+	// codes under the minimum are hardly scanned.
+	_lintMinContrast  = 2.0
+	_lintGoodContrast = 4.0
+	// _lintQuietZone is the quiet zone in modules required by ISO/IEC 18004.
+	_lintQuietZone = 4
+	// _lintMinModuleMM and _lintGoodModuleMM are the printed module sizes in millimeter.
+	_lintMinModuleMM  = 0.25
+	_lintGoodModuleMM = 0.33
+	// _lintJPEGModulePixels is the module size under which JPEG artifacts and chroma
+	// subsampling (4:2:0) blur the module edges.
+	_lintJPEGModulePixels = 4
+)
+
+// Lint evaluates the final option set (as Writer would use) for qrc and returns the
+// readability problems found, such as low contrast, narrow quiet zone and modules
+// too small. It returns nil if there is no problem.
+func Lint(qrc *qrcode.QRCode, opts ...ImageOption) []LintFinding {
+	if qrc == nil {
+		return []LintFinding{{Severity: LintError, Check: "qrcode", Message: "QR code is nil"}}
+	}
+
+	oo := defaultOutputImageOption()
+	for _, opt := range opts {
+		opt.apply(oo)
+	}
+
+	return oo.lint(qrc.Matrix())
+}
+
+// lint runs all checks against mat.
+func (oo *outputImageOptions) lint(mat qrcode.Matrix) []LintFinding {
+	oo = oo.resolve(mat.Width()).forMatrix(mat)
+
+	var findings []LintFinding
+	add := func(severity LintSeverity, check, format string, args ...interface{}) {
+		findings = append(findings, LintFinding{
+			Severity: severity,
+			Check:    check,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	oo.lintColors(add)
+	oo.lintQuietZone(add)
+	oo.lintModuleSize(add)
+
+	return findings
+}
+
+type lintAddFunc func(severity LintSeverity, check, format string, args ...interface{})
+
+// lintColors checks the contrast between foreground colors (including gradient
+// stops) and background color, and whether the reflectance is inverted by colors or
+// by the matrix.
+func (oo *outputImageOptions) lintColors(add lintAddFunc) {
+	bg := oo.bgColor
+	if oo.bgTransparent {
+		// contrast is checked against a white page.
+		bg = color_WHITE
+		add(LintWarning, "transparent-background",
+			"the background is transparent, the code is unreadable on dark pages, fill the background if possible")
+	}
+	if oo.moduleColorFunc != nil {
+		add(LintInfo, "contrast", "module colors come from a color function, contrast is not checked")
+		return
+	}
+
+	foregrounds := []struct {
+		name  string
+		color color.RGBA
+	}{
+		{"foreground", oo.qrColors.getDataColor(oo.qrColor)},
+		{"finder", oo.qrColors.getFinderColor(oo.qrColor)},
+	}
+	if oo.qrGradient != nil {
+		for i, stop := range oo.qrGradient.Stops {
+			foregrounds = append(foregrounds, struct {
+				name  string
+				color color.RGBA
+			}{fmt.Sprintf("gradient stop %d (at %.2f)", i, stop.T), stop.Color})
+		}
+	}
+
+	inverted := oo.reversed
+	for _, fg := range foregrounds {
+		ratio := contrastRatio(fg.color, bg)
+		switch {
+		case ratio < _lintMinContrast:
+			add(LintError, "contrast", "%s %s on background %s has contrast ratio %.2f:1, at least %.0f:1 is required",
+				fg.name, colorToHex(fg.color), colorToHex(bg), ratio, _lintMinContrast)
+		case ratio < _lintGoodContrast:
+			add(LintWarning, "contrast", "%s %s on background %s has contrast ratio %.2f:1, %.0f:1 or higher is recommended",
+				fg.name, colorToHex(fg.color), colorToHex(bg), ratio, _lintGoodContrast)
+		}
+
+		if relativeLuminance(fg.color) > relativeLuminance(bg) {
+			inverted = true
+		}
+	}
+
+	if inverted {
+		add(LintWarning, "inverted-reflectance",
+			"foreground is lighter than background (light modules on dark background), many scanners could not read inverted codes")
+	}
+}
+
+// lintQuietZone checks the quiet zone (borders) in modules.
+func (oo *outputImageOptions) lintQuietZone(add lintAddFunc) {
+	blockW := oo.qrBlockWidth()
+	minBorder := oo.borderWidths[0]
+	for _, b := range oo.borderWidths[1:] {
+		if b < minBorder {
+			minBorder = b
+		}
+	}
+
+	modules := float64(minBorder) / float64(blockW)
+	switch {
+	case modules < _lintQuietZone/2:
+		add(LintError, "quiet-zone", "quiet zone is %.1f modules, at least %d modules are required",
+			modules, _lintQuietZone)
+	case modules < _lintQuietZone:
+		add(LintWarning, "quiet-zone", "quiet zone is %.1f modules, %d modules are recommended",
+			modules, _lintQuietZone)
+	}
+}
+
+// lintModuleSize checks the module size in pixels, in millimeter if target DPI is
+// set, and the risk of JPEG compression.
+func (oo *outputImageOptions) lintModuleSize(add lintAddFunc) {
+	blockW := oo.qrBlockWidth()
+	dpi := oo.lintDPI
+	if dpi <= 0 {
+		dpi = oo.dpi
+	}
+	if dpi > 0 {
+		mm := pixelsToMM(blockW, dpi)
+		switch {
+		case mm < _lintMinModuleMM:
+			add(LintError, "module-size", "module is %.2fmm at %d DPI, at least %.2fmm is required",
+				mm, dpi, _lintMinModuleMM)
+		case mm < _lintGoodModuleMM:
+			add(LintWarning, "module-size", "module is %.2fmm at %d DPI, %.2fmm or larger is recommended",
+				mm, dpi, _lintGoodModuleMM)
+		}
+	}
+
+	if _, ok := oo.imageEncoder.(jpegEncoder); !ok {
+		return
+	}
+	switch {
+	case blockW < 2:
+		add(LintError, "jpeg", "module is %dpx in JPEG, compression artifacts destroy the modules, use PNG or larger modules",
+			blockW)
+	case blockW < _lintJPEGModulePixels && oo.isChromatic():
+		add(LintWarning, "jpeg", "module is %dpx in JPEG with colors, chroma subsampling blurs the module edges, use PNG or larger modules",
+			blockW)
+	}
+}
+
+// isChromatic reports whether any of the colors is not gray.
+func (oo *outputImageOptions) isChromatic() bool {
+	colors := []color.RGBA{oo.qrColors.getDataColor(oo.qrColor), oo.qrColors.getFinderColor(oo.qrColor)}
+	if !oo.bgTransparent {
+		colors = append(colors, oo.bgColor)
+	}
+	if oo.qrGradient != nil {
+		for _, stop := range oo.qrGradient.Stops {
+			colors = append(colors, stop.Color)
+		}
+	}
+
+	for _, c := range colors {
+		if c.R != c.G || c.G != c.B {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lintError returns an error which lists the error findings, nil if there is none.
+func lintError(findings []LintFinding) error {
+	var messages []string
+	for _, f := range findings {
+		if f.Severity == LintError {
+			messages = append(messages, f.String())
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("lint failed: %s", strings.Join(messages, "; "))
+}
+
+// relativeLuminance returns the relative luminance of c, ref to WCAG 2.x.
+func relativeLuminance(c color.RGBA) float64 {
+	return 0.2126*linearChannel(c.R) + 0.7152*linearChannel(c.G) + 0.0722*linearChannel(c.B)
+}
+
+// linearChannel converts the sRGB channel v into linear RGB, from 0 to 1.
+func linearChannel(v uint8) float64 {
+	f := float64(v) / 255
+	if f <= 0.03928 {
+		return f / 12.92
+	}
+
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+// srgbChannel converts the linear RGB channel f (from 0 to 1) into sRGB.
+func srgbChannel(f float64) uint8 {
+	switch {
+	case f <= 0:
+		return 0
+	case f >= 1:
+		return 255
+	case f <= 0.03928/12.92:
+		return uint8(math.Round(f * 12.92 * 255))
+	}
+
+	return uint8(math.Round((1.055*math.Pow(f, 1/2.4) - 0.055) * 255))
+}
+
+// contrastRatio returns the luminance contrast ratio of c1 and c2, from 1 to 21.
+func contrastRatio(c1, c2 color.RGBA) float64 {
+	l1, l2 := relativeLuminance(c1), relativeLuminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05)
+}
@@ -0,0 +1,78 @@
+package standard
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func lintChecks(findings []LintFinding, severity LintSeverity) []string {
+	var checks []string
+	for _, f := range findings {
+		if f.Severity == severity {
+			checks = append(checks, f.Check)
+		}
+	}
+
+	return checks
+}
+
+func Test_Lint(t *testing.T) {
+	qrc, err := qrcode.New("Test_Lint")
+	require.NoError(t, err)
+
+	// default options have no errors, but the quiet zone is only 2 modules.
+	assert.Empty(t, lintChecks(Lint(qrc), LintError))
+	assert.Equal(t, []string{"quiet-zone"}, lintChecks(Lint(qrc), LintWarning))
+	assert.Empty(t, Lint(qrc, WithBorderWidth(80)))
+	assert.Equal(t, []string{"qrcode"}, lintChecks(Lint(nil), LintError))
+
+	reversed, err := qrcode.NewWith("Test_Lint", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	assert.Contains(t, lintChecks(Lint(reversed, WithBorderWidth(80)), LintWarning), "inverted-reflectance")
+
+	findings := Lint(qrc, WithFgColorRGBHex("#cccccc"), WithBorderWidth(0))
+	assert.ElementsMatch(t, []string{"contrast", "contrast", "quiet-zone"}, lintChecks(findings, LintError))
+
+	findings = Lint(qrc, WithFgColorRGBHex("#ffffff"), WithBgColorRGBHex("#000000"))
+	assert.Contains(t, lintChecks(findings, LintWarning), "inverted-reflectance")
+	assert.Empty(t, lintChecks(findings, LintError))
+
+	findings = Lint(qrc, WithBorderWidth(40), WithQRWidth(1))
+	assert.Equal(t, []string{"jpeg"}, lintChecks(findings, LintError))
+
+	findings = Lint(qrc, WithQRWidth(2), WithLintDPI(600))
+	assert.Contains(t, lintChecks(findings, LintError), "module-size")
+}
+
+func Test_Lint_Gradient(t *testing.T) {
+	qrc, err := qrcode.New("Test_Lint_Gradient")
+	require.NoError(t, err)
+
+	findings := Lint(qrc, WithBorderWidth(80), WithFgGradient(NewGradient(0,
+		ColorStop{T: 0, Color: color.RGBA{A: 0xff}},
+		ColorStop{T: 1, Color: color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}},
+	)))
+	require.Len(t, findings, 1)
+	assert.Equal(t, LintError, findings[0].Severity)
+	assert.Contains(t, findings[0].Message, "gradient stop 1")
+}
+
+func Test_WithStrictLint(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithStrictLint")
+	require.NoError(t, err)
+
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+		WithFgColorRGBHex("#eeeeee"),
+		WithStrictLint(),
+	)
+	assert.Error(t, qrc.Save(w))
+
+	w = NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)}, WithStrictLint())
+	assert.NoError(t, qrc.Save(w))
+}
@@ -0,0 +1,337 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+	_ "golang.org/x/image/bmp"
+	drawpkg "golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// LogoShape is the shape of the logo plate or the logo crop.
+type LogoShape uint8
+
+const (
+	// LogoSquare is a square (rectangle) shape.
+	LogoSquare LogoShape = iota + 1
+	// LogoRounded is a square (rectangle) shape with rounded corners.
+	LogoRounded
+	// LogoCircle is a circle shape.
+	LogoCircle
+)
+
+// logoPlate is the background plate drawn under the logo.
+type logoPlate struct {
+	shape   LogoShape
+	color   color.Color
+	padding int
+}
+
+// plateColor returns the color of plate, the opaque background color is used if
+// color is not set.
+func (p *logoPlate) plateColor(oo *outputImageOptions) color.Color {
+	if p.color != nil {
+		return p.color
+	}
+
+	c := oo.bgColor
+	c.A = 0xff
+	return c
+}
+
+// logoSVG is a logo in SVG format, it would be embedded as vector in SVG output.
+type logoSVG struct {
+	content       []byte
+	width, height float64
+}
+
+// logoLayout describes how to draw the logo in the QR code image.
+type logoLayout struct {
+	// img is the logo image which has been scaled and cropped, it may be nil if
+	// only SVG logo is provided.
+	img image.Image
+	// rect is where the logo is drawn.
+	rect image.Rectangle
+	// plate is where the logo plate is drawn, it's empty if no plate.
+	plate image.Rectangle
+	// zone is the safe zone, modules in it would not be drawn. it's empty if
+	// safe zone is disabled.
+	zone image.Rectangle
+}
+
+// hides reports whether the module (x, y) is in the safe zone.
+func (l *logoLayout) hides(x, y, blockW, left, top int) bool {
+	if l == nil || l.zone.Empty() {
+		return false
+	}
+
+	block := image.Rect(x*blockW+left, y*blockW+top, (x+1)*blockW+left, (y+1)*blockW+top)
+	return block.Overlaps(l.zone)
+}
+
+// layoutLogo calculates the logo layout in an image of w x h pixels, the QR code
+// (dimension x dimension modules) starts at (left, top). It returns nil if there is
+// no logo, and an error if the logo is too large.
+func (oo *outputImageOptions) layoutLogo(w, h, blockW, left, top, dimension int) (*logoLayout, error) {
+	if oo.logo == nil && oo.logoSVG == nil {
+		return nil, nil
+	}
+
+	logoW, logoH := oo.logoSize()
+	if oo.logoScale > 0 {
+		// scale the logo to the target fraction of the QR code.
+		target := oo.logoScale * float64(dimension*blockW)
+		k := target / math.Max(logoW, logoH)
+		logoW, logoH = logoW*k, logoH*k
+	}
+
+	lw, lh := int(math.Round(logoW)), int(math.Round(logoH))
+	if lw < 1 || lh < 1 {
+		return nil, nil
+	}
+	if oo.logoScale <= 0 && !validLogoImage(w, h, lw, lh, oo.logoSizeMultiplier) {
+		return nil, fmt.Errorf("logo %dx%d is larger than 1/%d of QR code %dx%d, use WithLogoScale or a smaller logo",
+			lw, lh, oo.logoSizeMultiplier, w, h)
+	}
+
+	l := &logoLayout{
+		rect: image.Rect((w-lw)/2, (h-lh)/2, (w-lw)/2+lw, (h-lh)/2+lh),
+	}
+	if oo.logo != nil {
+		l.img = prepareLogo(oo.logo, lw, lh, oo.logoCrop)
+	}
+
+	area := l.rect
+	if oo.logoPlate != nil {
+		area = area.Inset(-oo.logoPlate.padding)
+	}
+	if oo.logoSafeZone {
+		// snap the safe zone to whole modules, so that no module is cut by half.
+		qrArea := image.Rect(left, top, left+dimension*blockW, top+dimension*blockW)
+		l.zone = image.Rect(
+			left+floorDiv(area.Min.X-left, blockW)*blockW,
+			top+floorDiv(area.Min.Y-top, blockW)*blockW,
+			left+ceilDiv(area.Max.X-left, blockW)*blockW,
+			top+ceilDiv(area.Max.Y-top, blockW)*blockW,
+		).Intersect(qrArea)
+		area = l.zone
+	}
+	if oo.logoPlate != nil {
+		l.plate = area
+	}
+
+	return l, nil
+}
+
+// logoSize returns the original size of the logo.
+func (oo *outputImageOptions) logoSize() (float64, float64) {
+	if oo.logo != nil {
+		b := oo.logo.Bounds()
+		return float64(b.Dx()), float64(b.Dy())
+	}
+
+	return oo.logoSVG.width, oo.logoSVG.height
+}
+
+func floorDiv(a, b int) int {
+	return int(math.Floor(float64(a) / float64(b)))
+}
+
+func ceilDiv(a, b int) int {
+	return int(math.Ceil(float64(a) / float64(b)))
+}
+
+// prepareLogo scales img into w x h and crops it into shape.
+func prepareLogo(img image.Image, w, h int, crop LogoShape) image.Image {
+	if b := img.Bounds(); b.Dx() != w || b.Dy() != h {
+		resized := image.NewNRGBA(image.Rect(0, 0, w, h))
+		drawpkg.CatmullRom.Scale(resized, resized.Bounds(), img, b, drawpkg.Over, nil)
+		img = resized
+	}
+
+	if crop != LogoCircle && crop != LogoRounded {
+		return img
+	}
+
+	dc := gg.NewContext(w, h)
+	addLogoShapePath(dc, crop, 0, 0, float64(w), float64(h))
+	dc.Clip()
+	dc.DrawImage(img, -img.Bounds().Min.X, -img.Bounds().Min.Y)
+	return dc.Image()
+}
+
+// addLogoShapePath adds the path of shape in rectangle (x, y, w, h) into dc.
+func addLogoShapePath(dc *gg.Context, shape LogoShape, x, y, w, h float64) {
+	switch shape {
+	case LogoCircle:
+		dc.DrawCircle(x+w/2, y+h/2, math.Min(w, h)/2)
+	case LogoRounded:
+		dc.DrawRoundedRectangle(x, y, w, h, logoCornerRadius(w, h))
+	default:
+		dc.DrawRectangle(x, y, w, h)
+	}
+}
+
+func logoCornerRadius(w, h float64) float64 {
+	return math.Min(w, h) / 5
+}
+
+// drawLogo draws the logo plate and the logo into dc, SVG logo could not be drawn
+// without a fallback image.
+func (oo *outputImageOptions) drawLogo(dc *gg.Context, l *logoLayout) error {
+	if l.img == nil {
+		return errors.New("SVG logo could not be drawn in raster image without a fallback image")
+	}
+
+	if !l.plate.Empty() {
+		p := l.plate
+		dc.SetColor(oo.logoPlate.plateColor(oo))
+		addLogoShapePath(dc, oo.logoPlate.shape,
+			float64(p.Min.X), float64(p.Min.Y), float64(p.Dx()), float64(p.Dy()))
+		dc.Fill()
+	}
+
+	dc.DrawImage(l.img, l.rect.Min.X, l.rect.Min.Y)
+	return nil
+}
+
+// writeSVGLogo writes the logo plate and the logo into SVG, SVG logo is embedded as
+// vector, and the crop is done by clipPath.
+func (oo *outputImageOptions) writeSVGLogo(w io.Writer, l *logoLayout) error {
+	if !l.plate.Empty() {
+		if _, err := fmt.Fprintf(w, "%s\n", svgLogoShape(oo.logoPlate.shape, l.plate,
+			fmt.Sprintf(`fill="%s"`, colorToHex(oo.logoPlate.plateColor(oo))))); err != nil {
+			return err
+		}
+	}
+
+	var href string
+	switch {
+	case oo.logoSVG != nil:
+		href = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(oo.logoSVG.content)
+	case l.img != nil:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, l.img); err != nil {
+			return err
+		}
+		href = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	default:
+		return nil
+	}
+
+	clip := ""
+	if oo.logoSVG != nil && (oo.logoCrop == LogoCircle || oo.logoCrop == LogoRounded) {
+		if _, err := fmt.Fprintf(w, "<defs><clipPath id=\"logoClip\">%s</clipPath></defs>\n",
+			svgLogoShape(oo.logoCrop, l.rect, "")); err != nil {
+			return err
+		}
+		clip = ` clip-path="url(#logoClip)"`
+	}
+
+	r := l.rect
+	_, err := fmt.Fprintf(w, "<image x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" preserveAspectRatio=\"none\"%s href=\"%s\"/>\n",
+		r.Min.X, r.Min.Y, r.Dx(), r.Dy(), clip, href)
+	return err
+}
+
+// svgLogoShape returns the SVG element of shape in rectangle r.
+func svgLogoShape(shape LogoShape, r image.Rectangle, attrs string) string {
+	x, y, w, h := float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy())
+	switch shape {
+	case LogoCircle:
+		return fmt.Sprintf(`<circle cx="%s" cy="%s" r="%s" %s/>`,
+			svgNumber(x+w/2), svgNumber(y+h/2), svgNumber(math.Min(w, h)/2), attrs)
+	case LogoRounded:
+		radius := svgNumber(logoCornerRadius(w, h))
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" rx="%s" ry="%s" %s/>`,
+			r.Min.X, r.Min.Y, r.Dx(), r.Dy(), radius, radius, attrs)
+	default:
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" %s/>`,
+			r.Min.X, r.Min.Y, r.Dx(), r.Dy(), attrs)
+	}
+}
+
+// parseLogoSVG parses the size of SVG logo from the attributes (width, height and
+// viewBox) of the root element.
+func parseLogoSVG(content []byte) (*logoSVG, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("could not find svg element: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return nil, fmt.Errorf("root element is <%s>, not <svg>", start.Name.Local)
+		}
+
+		logo := &logoSVG{content: content}
+		var viewBox []string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "width":
+				logo.width = parseSVGLength(attr.Value)
+			case "height":
+				logo.height = parseSVGLength(attr.Value)
+			case "viewBox":
+				viewBox = strings.Fields(strings.ReplaceAll(attr.Value, ",", " "))
+			}
+		}
+		if (logo.width <= 0 || logo.height <= 0) && len(viewBox) == 4 {
+			logo.width = parseSVGLength(viewBox[2])
+			logo.height = parseSVGLength(viewBox[3])
+		}
+		if logo.width <= 0 || logo.height <= 0 {
+			return nil, fmt.Errorf("could not decide the size of svg logo")
+		}
+
+		return logo, nil
+	}
+}
+
+// parseSVGLength parses SVG length such as "100", "100px" and "100.5", other units
+// are not supported and return 0.
+func parseSVGLength(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "px"), 64)
+	if err != nil {
+		return 0
+	}
+
+	return v
+}
+
+// readLogoFile reads a logo from file, it could be any registered image format or SVG.
+func readLogoFile(path string) (image.Image, *logoSVG, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		svg, err := parseLogoSVG(content)
+		return nil, svg, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	return img, nil, err
+}
@@ -0,0 +1,141 @@
+package standard
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// LogoCheck is the result of CheckLogo.
+type LogoCheck struct {
+	// Report is the damage of each Reed-Solomon block caused by the logo.
+	Report *qrcode.DamageReport
+
+	// Safe reports whether the QR code could still be decoded with the logo, which
+	// means every block is in its correction capacity and no finder patterns, timing
+	// patterns or format info are covered.
+	Safe bool
+
+	// Margin is how many more codewords the weakest block could lose, negative
+	// means the logo is too large.
+	Margin int
+
+	// SuggestedSize is the edge length (in modules) of the largest centered logo in
+	// the same shape which is still safe for the version and error correction level.
+	SuggestedSize int
+}
+
+// CheckLogo checks whether the QR code could still be decoded when the modules
+// covered by a logo in shape are lost. rect is in modules, for example,
+// image.Rect(10, 10, 15, 15) covers modules from (10, 10) to (14, 14).
+func CheckLogo(qrc *qrcode.QRCode, rect image.Rectangle, shape LogoShape) (*LogoCheck, error) {
+	if qrc == nil {
+		return nil, fmt.Errorf("nil qrcode")
+	}
+
+	area := moduleArea{
+		minX: float64(rect.Min.X), minY: float64(rect.Min.Y),
+		maxX: float64(rect.Max.X), maxY: float64(rect.Max.Y),
+	}
+	report, err := qrc.Damage(area.covers(shape))
+	if err != nil {
+		return nil, err
+	}
+
+	check := &LogoCheck{
+		Report: report,
+		Safe:   report.Safe(),
+		Margin: report.Margin(),
+	}
+
+	// grow the centered logo module by module until it's not safe.
+	dimension := qrc.Dimension()
+	for size := 1; size <= dimension; size++ {
+		min := float64(dimension-size) / 2
+		centered := moduleArea{minX: min, minY: min, maxX: min + float64(size), maxY: min + float64(size)}
+		r, err := qrc.Damage(centered.covers(shape))
+		if err != nil || !r.Safe() {
+			break
+		}
+		check.SuggestedSize = size
+	}
+
+	return check, nil
+}
+
+// moduleArea is an area in module coordinates.
+type moduleArea struct {
+	minX, minY, maxX, maxY float64
+}
+
+// pixelsToModules converts the rectangle r in pixels into module coordinates.
+func pixelsToModules(r image.Rectangle, blockW, left, top int) moduleArea {
+	w := float64(blockW)
+	return moduleArea{
+		minX: float64(r.Min.X-left) / w,
+		minY: float64(r.Min.Y-top) / w,
+		maxX: float64(r.Max.X-left) / w,
+		maxY: float64(r.Max.Y-top) / w,
+	}
+}
+
+// covers returns a function which reports whether the module (x, y) overlaps the
+// shape drawn in the area, the shape is the same as addLogoShapePath draws.
+func (a moduleArea) covers(shape LogoShape) func(x, y int) bool {
+	w, h := a.maxX-a.minX, a.maxY-a.minY
+	if w <= 0 || h <= 0 {
+		return func(x, y int) bool { return false }
+	}
+
+	// the rounded shapes are the inner rectangle extended by radius.
+	inner, radius := a, 0.0
+	switch shape {
+	case LogoCircle:
+		radius = math.Min(w, h) / 2
+		cx, cy := a.minX+w/2, a.minY+h/2
+		inner = moduleArea{minX: cx, minY: cy, maxX: cx, maxY: cy}
+	case LogoRounded:
+		radius = logoCornerRadius(w, h)
+		inner = moduleArea{minX: a.minX + radius, minY: a.minY + radius, maxX: a.maxX - radius, maxY: a.maxY - radius}
+	}
+
+	return func(x, y int) bool {
+		fx, fy := float64(x), float64(y)
+		if radius == 0 {
+			return fx < a.maxX && fx+1 > a.minX && fy < a.maxY && fy+1 > a.minY
+		}
+
+		dx := math.Max(0, math.Max(inner.minX-(fx+1), fx-inner.maxX))
+		dy := math.Max(0, math.Max(inner.minY-(fy+1), fy-inner.maxY))
+		return dx*dx+dy*dy < radius*radius
+	}
+}
+
+// checkLogo returns an error if the logo in layout is not safe for mat, the QR code
+// starts at (left, top) and each module is blockW pixels.
+func (oo *outputImageOptions) checkLogo(mat qrcode.Matrix, l *logoLayout, blockW, left, top int) error {
+	// modules in the safe zone are removed, otherwise modules are covered by the
+	// plate or the logo (transparent corners of the cropped logo are not counted).
+	var covers func(x, y int) bool
+	switch {
+	case !l.zone.Empty():
+		covers = pixelsToModules(l.zone, blockW, left, top).covers(LogoSquare)
+	case !l.plate.Empty():
+		covers = pixelsToModules(l.plate, blockW, left, top).covers(oo.logoPlate.shape)
+	default:
+		covers = pixelsToModules(l.rect, blockW, left, top).covers(oo.logoCrop)
+	}
+
+	report, err := mat.Damage(covers)
+	if err != nil {
+		return fmt.Errorf("check logo failed: %v", err)
+	}
+	if !report.Safe() {
+		return fmt.Errorf("logo is not safe: margin=%d, damaged function modules=%d",
+			report.Margin(), report.FunctionModules)
+	}
+
+	return nil
+}
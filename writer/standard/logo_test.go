@@ -0,0 +1,174 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func solidImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func Test_layoutLogo_SafeZoneSnapped(t *testing.T) {
+	oo := defaultOutputImageOption()
+	WithLogoImage(solidImage(33, 27, color.Black)).apply(oo)
+	WithLogoSafeZone().apply(oo)
+	WithLogoPlate(LogoRounded, color.White, 3).apply(oo)
+
+	// 25 modules, 10 pixels per module and 20 pixels border.
+	l, err := oo.layoutLogo(290, 290, 10, 20, 20, 25)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	assert.Equal(t, 33, l.rect.Dx())
+	assert.Equal(t, 27, l.rect.Dy())
+
+	// the safe zone covers the logo and its plate, and it's aligned to modules.
+	assert.True(t, l.rect.Inset(-3).In(l.zone))
+	for _, v := range []int{l.zone.Min.X, l.zone.Min.Y, l.zone.Max.X, l.zone.Max.Y} {
+		assert.Equal(t, 0, (v-20)%10)
+	}
+	assert.Equal(t, l.zone, l.plate)
+}
+
+func Test_layoutLogo_Scale(t *testing.T) {
+	oo := defaultOutputImageOption()
+	WithLogoImage(solidImage(400, 200, color.Black)).apply(oo)
+	WithLogoScale(0.2).apply(oo)
+
+	l, err := oo.layoutLogo(290, 290, 10, 20, 20, 25)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	assert.Equal(t, 50, l.rect.Dx())
+	assert.Equal(t, 25, l.rect.Dy())
+	assert.Equal(t, l.rect.Size(), l.img.Bounds().Size())
+	assert.True(t, l.zone.Empty())
+}
+
+func Test_WithLogoCrop(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_WithLogoCrop", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(PNG_FORMAT),
+		WithQRWidth(10),
+		WithLogoImage(solidImage(40, 40, color.RGBA{R: 0xff, A: 0xff})),
+		WithLogoCrop(LogoCircle),
+	)
+	require.NoError(t, qrc.Save(w))
+
+	img, err := png.Decode(buf)
+	require.NoError(t, err)
+	cx, cy := img.Bounds().Dx()/2, img.Bounds().Dy()/2
+	r, g, b, _ := img.At(cx, cy).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0, 0}, [3]uint32{r, g, b})
+	// the corner of the logo is cropped.
+	r, g, b, _ = img.At(cx-19, cy-19).RGBA()
+	assert.NotEqual(t, [3]uint32{0xffff, 0, 0}, [3]uint32{r, g, b})
+}
+
+func Test_WithLogoSVG(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithLogoSVG")
+	require.NoError(t, err)
+
+	logo := []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10"><rect width="20" height="10"/></svg>`)
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithLogoSVG(logo, nil),
+		WithLogoScale(0.25),
+		WithLogoPlate(LogoCircle, nil, 4),
+	)
+	require.NoError(t, qrc.Save(w))
+	assert.Contains(t, buf.String(), `href="data:image/svg+xml;base64,`)
+	assert.Contains(t, buf.String(), `<circle `)
+}
+
+func Test_parseLogoSVG(t *testing.T) {
+	logo, err := parseLogoSVG([]byte(`<?xml version="1.0"?><svg width="64px" height="32" viewBox="0 0 2 1"></svg>`))
+	require.NoError(t, err)
+	assert.Equal(t, 64.0, logo.width)
+	assert.Equal(t, 32.0, logo.height)
+
+	_, err = parseLogoSVG([]byte(`<html></html>`))
+	assert.Error(t, err)
+}
+
+func Test_CheckLogo(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_CheckLogo",
+		qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest), qrcode.WithVersion(5))
+	require.NoError(t, err)
+
+	check, err := CheckLogo(qrc, image.Rect(16, 16, 21, 21), LogoCircle)
+	require.NoError(t, err)
+	assert.True(t, check.Safe)
+	assert.GreaterOrEqual(t, check.Margin, 0)
+	assert.GreaterOrEqual(t, check.SuggestedSize, 5)
+	assert.Less(t, check.SuggestedSize, qrc.Dimension())
+
+	check, err = CheckLogo(qrc, image.Rect(5, 5, 32, 32), LogoSquare)
+	require.NoError(t, err)
+	assert.False(t, check.Safe)
+	assert.Less(t, check.Margin, 0)
+}
+
+func Test_moduleArea_covers(t *testing.T) {
+	area := moduleArea{minX: 2, minY: 2, maxX: 6, maxY: 6}
+
+	square := area.covers(LogoSquare)
+	assert.True(t, square(2, 2))
+	assert.True(t, square(5, 5))
+	assert.False(t, square(6, 5))
+	assert.False(t, square(1, 3))
+
+	circle := moduleArea{minX: 0, minY: 0, maxX: 10, maxY: 10}.covers(LogoCircle)
+	assert.True(t, circle(5, 0))
+	assert.False(t, circle(0, 0))
+	assert.False(t, circle(9, 9))
+}
+
+func Test_WithLogoCheck(t *testing.T) {
+	qrc, err := qrcode.NewWith("Test_WithLogoCheck",
+		qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionLow))
+	require.NoError(t, err)
+
+	for _, format := range []formatTyp{PNG_FORMAT, SVG_FORMAT} {
+		w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+			WithBuiltinImageEncoder(format),
+			WithLogoImage(solidImage(10, 10, color.Black)),
+			WithLogoScale(0.5),
+			WithLogoCheck(),
+		)
+		assert.Error(t, qrc.Save(w))
+	}
+}
+
+func Test_logoErrors(t *testing.T) {
+	mat := recordMatrix(t, "Test_logoErrors")
+
+	// the logo is larger than 1/5 of the QR code.
+	_, err := NewRenderer(WithQRWidth(4), WithLogoImage(solidImage(80, 80, color.Black))).Render(mat)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logo 80x80 is larger")
+
+	// SVG logo without fallback image could not be drawn in raster image.
+	logo := []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10"><rect width="20" height="10"/></svg>`)
+	_, err = NewRenderer(WithLogoSVG(logo, nil), WithLogoScale(0.2)).Render(mat)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fallback image")
+}
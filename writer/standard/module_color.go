@@ -0,0 +1,76 @@
+package standard
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+// ModuleColorFunc decides the color of the module at (x, y) of the matrix, t is the
+// type of the module and dark reports whether the module is set. Returning nil means
+// the default color (bgColor, qrColor or QRColors) would be used.
+type ModuleColorFunc func(x, y int, t qrcode.QRType, dark bool) color.Color
+
+const (
+	// _darkLumaMax is the max luma of a dark module picked by ModuleColorsFromImage.
+	_darkLumaMax = 80
+	// _lightLumaMin is the min luma of a light module picked by ModuleColorsFromImage.
+	_lightLumaMin = 190
+)
+
+// ModuleColorsFromImage returns a ModuleColorFunc which takes colors from img, img is
+// stretched over the QR code whose width is dimension modules (use qrc.Dimension()).
+// The hue of each sampled color is kept, but dark modules are darkened and light
+// modules are lightened if necessary, so that the luminance contrast is still enough
+// to be scanned.
+func ModuleColorsFromImage(img image.Image, dimension int) ModuleColorFunc {
+	if img == nil || dimension <= 0 {
+		return nil
+	}
+
+	bounds := img.Bounds()
+	return func(x, y int, _ qrcode.QRType, dark bool) color.Color {
+		px := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*dimension)
+		py := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*dimension)
+		c := color.RGBAModel.Convert(img.At(px, py)).(color.RGBA)
+		c.A = 0xff
+
+		if dark {
+			return darken(c, _darkLumaMax)
+		}
+
+		return lighten(c, _lightLumaMin)
+	}
+}
+
+// luma returns the perceived brightness of c in range [0, 255].
+func luma(c color.RGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+// darken scales c down until its luma is not greater than max.
+func darken(c color.RGBA, max float64) color.RGBA {
+	l := luma(c)
+	if l <= max {
+		return c
+	}
+
+	k := max / l
+	return color.RGBA{
+		R: uint8(float64(c.R) * k),
+		G: uint8(float64(c.G) * k),
+		B: uint8(float64(c.B) * k),
+		A: c.A,
+	}
+}
+
+// lighten blends c with white until its luma is not less than min.
+func lighten(c color.RGBA, min float64) color.RGBA {
+	l := luma(c)
+	if l >= min {
+		return c
+	}
+
+	return blendColors(c, color_WHITE, (min-l)/(255-l))
+}
@@ -0,0 +1,99 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_WithModuleColorFunc(t *testing.T) {
+	oo := defaultOutputImageOption()
+	assert.Nil(t, oo.moduleColorFunc)
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	WithModuleColorFunc(func(x, y int, typ qrcode.QRType, dark bool) color.Color {
+		if x == 0 && dark {
+			return red
+		}
+		return nil
+	}).apply(oo)
+
+	assert.Equal(t, red, oo.moduleColor(0, 0, qrcode.QRValue_DATA_V1))
+	assert.Equal(t, color_BLACK, oo.moduleColor(1, 0, qrcode.QRValue_DATA_V1))
+	assert.Equal(t, color_WHITE, oo.moduleColor(0, 0, qrcode.QRValue_DATA_V0))
+}
+
+func Test_ModuleColorsFromImage(t *testing.T) {
+	// left half is light yellow, right half is dark blue.
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			if x < 10 {
+				img.Set(x, y, color.RGBA{R: 0xff, G: 0xee, B: 0x88, A: 0xff})
+			} else {
+				img.Set(x, y, color.RGBA{R: 0x10, G: 0x20, B: 0x60, A: 0xff})
+			}
+		}
+	}
+
+	fn := ModuleColorsFromImage(img, 2)
+	require.NotNil(t, fn)
+
+	darkOnLight := parseFromColor(fn(0, 0, qrcode.QRType_DATA, true))
+	assert.LessOrEqual(t, luma(darkOnLight), float64(_darkLumaMax))
+	lightOnDark := parseFromColor(fn(1, 0, qrcode.QRType_DATA, false))
+	assert.GreaterOrEqual(t, luma(lightOnDark), float64(_lightLumaMin)-1)
+	// colors already satisfy the contrast are kept as they are.
+	assert.Equal(t, color.RGBA{R: 0x10, G: 0x20, B: 0x60, A: 0xff}, fn(1, 1, qrcode.QRType_DATA, true))
+}
+
+func Test_svgEncoder_groupsModulesByColor(t *testing.T) {
+	qrc, err := qrcode.New("https://github.com/yeqown/go-qrcode")
+	require.NoError(t, err)
+
+	colors := []string{"#ff0000", "#00ff00", "#0000ff"}
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithModuleColorFunc(func(x, y int, _ qrcode.QRType, dark bool) color.Color {
+			if !dark {
+				return nil
+			}
+			return parseFromHex(colors[(x+y)%len(colors)])
+		}),
+	)
+	require.NoError(t, qrc.Save(w))
+
+	out := buf.String()
+	for _, c := range colors {
+		assert.Equal(t, 1, strings.Count(out, `<g fill="`+c+`">`))
+	}
+}
+
+func Test_svgEncoder_callsModuleColorFuncOnce(t *testing.T) {
+	qrc, err := qrcode.New("https://github.com/yeqown/go-qrcode")
+	require.NoError(t, err)
+
+	calls := make(map[[2]int]int)
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)},
+		WithBuiltinImageEncoder(SVG_FORMAT),
+		WithModuleColorFunc(func(x, y int, _ qrcode.QRType, _ bool) color.Color {
+			calls[[2]int{x, y}]++
+			return nil
+		}),
+	)
+	require.NoError(t, qrc.Save(w))
+
+	dimension := qrc.Dimension()
+	assert.Len(t, calls, dimension*dimension)
+	for p, n := range calls {
+		assert.Equal(t, 1, n, "module %v", p)
+	}
+}
@@ -0,0 +1,165 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+)
+
+const (
+	_mmPerInch = 25.4
+	// _defaultQuietZone is the quiet zone in modules used by physical sizing if
+	// WithQuietZone is not set, as ISO/IEC 18004 requires.
+	_defaultQuietZone = 4
+)
+
+// isRaster reports whether the output is drawn into pixels, rather than vector.
+func (oo *outputImageOptions) isRaster() bool {
+	_, ok := oo.imageEncoder.(ImageEncoderWithMatrix)
+	return !ok
+}
+
+// resolve returns a copy of options whose module width and borders are decided
+// for QR code of dimension modules, according to the physical size, the quiet zone
+// in modules and the resolution (raster output only). The copy is used to draw, so
+// that options are not modified. Resolved options are returned as is, so resolving
+// more than once gives the same geometry.
+func (oo *outputImageOptions) resolve(dimension int) *outputImageOptions {
+	if dimension > 0 && oo.resolvedDimension == dimension {
+		return oo
+	}
+
+	cp := *oo
+	if dimension <= 0 {
+		return &cp
+	}
+	cp.resolvedDimension = dimension
+
+	quietZone := cp.quietZone
+	if cp.physicalSize > 0 && cp.dpi > 0 {
+		if quietZone < 0 {
+			quietZone = _defaultQuietZone
+		}
+		// pick the integer pixels per module which fits the target size.
+		pixels := cp.physicalSize / _mmPerInch * float64(cp.dpi)
+		cp.qrWidth = int(math.Floor(pixels / float64(dimension+2*quietZone)))
+		if cp.qrWidth < 1 {
+			cp.qrWidth = 1
+		}
+	}
+
+	resolution := cp.isRaster() && cp.resolution != nil && *cp.resolution > 0
+	if resolution {
+		if quietZone >= 0 {
+			// the quiet zone is counted in modules, so that borders follow the block size.
+			cp.qrWidth = *cp.resolution / (dimension + 2*quietZone)
+			if cp.qrWidth < 1 {
+				cp.qrWidth = 1
+			}
+		} else {
+			cp.qrWidth = cp.drawBlockWidth(dimension)
+		}
+	}
+	if quietZone >= 0 {
+		border := quietZone * cp.qrBlockWidth()
+		cp.borderWidths = [4]int{border, border, border, border}
+	}
+
+	if resolution {
+		// spread the pixels left by integer rounding to both sides evenly.
+		res, size := *cp.resolution, dimension*cp.qrWidth
+		if extra := res - size - cp.borderWidths[1] - cp.borderWidths[3]; extra > 0 {
+			cp.borderWidths[3] += extra / 2
+			cp.borderWidths[1] += extra - extra/2
+		}
+		if extra := res - size - cp.borderWidths[0] - cp.borderWidths[2]; extra > 0 {
+			cp.borderWidths[0] += extra / 2
+			cp.borderWidths[2] += extra - extra/2
+		}
+	}
+
+	return &cp
+}
+
+// pixelsToMM converts pixels into millimetre at dpi.
+func pixelsToMM(pixels, dpi int) float64 {
+	if dpi <= 0 {
+		return 0
+	}
+
+	return float64(pixels) / float64(dpi) * _mmPerInch
+}
+
+// encodePNGWithDPI encodes img as PNG with a pHYs chunk which records the DPI.
+func encodePNGWithDPI(w io.Writer, img image.Image, dpi int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	// signature(8) + IHDR chunk: length(4) + type(4) + data(13) + crc(4)
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	data := buf.Bytes()
+	if len(data) < ihdrEnd || string(data[12:16]) != "IHDR" {
+		return errors.New("unexpected PNG layout")
+	}
+
+	// pixels per metre in both directions, unit is metre.
+	ppm := uint32(math.Round(float64(dpi) / _mmPerInch * 1000))
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	for _, part := range [][]byte{data[:ihdrEnd], chunk, data[ihdrEnd:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeJPEGWithDPI encodes img as JPEG with a JFIF APP0 segment which records the DPI.
+func encodeJPEGWithDPI(w io.Writer, img image.Image, dpi int) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return errors.New("unexpected JPEG layout")
+	}
+
+	density := uint16(dpi)
+	if dpi > math.MaxUint16 {
+		density = math.MaxUint16
+	}
+	// APP0 marker, length(16), "JFIF\0", version 1.01, units(1: dots per inch),
+	// X and Y density, no thumbnail.
+	app0 := []byte{
+		0xff, 0xe0, 0x00, 0x10,
+		'J', 'F', 'I', 'F', 0x00,
+		0x01, 0x01, 0x01,
+		byte(density >> 8), byte(density), byte(density >> 8), byte(density),
+		0x00, 0x00,
+	}
+
+	for _, part := range [][]byte{data[:2], app0, data[2:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_resolve(t *testing.T) {
+	oo := defaultOutputImageOption()
+	WithPhysicalSize(25, 300).apply(oo)
+
+	// 21 modules and 4 modules quiet zone on both sides: 295px / 29 = 10px per module.
+	resolved := oo.resolve(21)
+	assert.Equal(t, 10, resolved.qrBlockWidth())
+	assert.Equal(t, [4]int{40, 40, 40, 40}, resolved.borderWidths)
+	// options are not modified.
+	assert.Equal(t, 20, oo.qrBlockWidth())
+
+	oo = defaultOutputImageOption()
+	WithQuietZone(2).apply(oo)
+	WithQRWidth(8).apply(oo)
+	assert.Equal(t, [4]int{16, 16, 16, 16}, oo.resolve(21).borderWidths)
+
+	// the pixels left by resolution are spread to both sides.
+	res := 300
+	oo = defaultOutputImageOption()
+	WithResolution(&res).apply(oo)
+	resolved = oo.resolve(21)
+	assert.Equal(t, 10, resolved.qrBlockWidth())
+	assert.Equal(t, [4]int{45, 45, 45, 45}, resolved.borderWidths)
+	// resolving again gives the same geometry.
+	assert.Equal(t, resolved, resolved.resolve(21))
+}
+
+func Test_resolve_QuietZoneResolution(t *testing.T) {
+	// 21 modules and 4 modules quiet zone on both sides: 290px / 29 = 10px per module.
+	res := 290
+	oo := defaultOutputImageOption()
+	WithQuietZone(4).apply(oo)
+	WithResolution(&res).apply(oo)
+	resolved := oo.resolve(21)
+	assert.Equal(t, 10, resolved.qrBlockWidth())
+	assert.Equal(t, [4]int{40, 40, 40, 40}, resolved.borderWidths)
+	assert.Equal(t, resolved, resolved.resolve(21))
+
+	// the pixels left by rounding are spread to both sides, less than a module each.
+	res = 300
+	resolved = oo.resolve(21)
+	block := resolved.qrBlockWidth()
+	assert.Equal(t, 10, block)
+	for _, border := range resolved.borderWidths {
+		assert.GreaterOrEqual(t, border, 4*block)
+		assert.Less(t, border, 5*block)
+	}
+	again := resolved.resolve(21)
+	assert.Equal(t, resolved.qrWidth, again.qrWidth)
+	assert.Equal(t, resolved.borderWidths, again.borderWidths)
+}
+
+func Test_Attribute_Physical(t *testing.T) {
+	w := NewWithWriter(nopCloser{Writer: bytes.NewBuffer(nil)}, WithPhysicalSize(25, 300))
+	attr := w.Attribute(21)
+	assert.Equal(t, 290, attr.W)
+	assert.Equal(t, 300, attr.DPI)
+	assert.InDelta(t, 24.55, attr.WidthMM, 0.01)
+	assert.InDelta(t, 0.85, attr.ModuleMM, 0.01)
+}
+
+func Test_WithDPI(t *testing.T) {
+	qrc, err := qrcode.New("Test_WithDPI")
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	w := NewWithWriter(nopCloser{Writer: buf}, WithBuiltinImageEncoder(PNG_FORMAT), WithDPI(300))
+	require.NoError(t, qrc.Save(w))
+	data := buf.Bytes()
+	// pHYs follows IHDR
+	assert.Equal(t, "pHYs", string(data[37:41]))
+	assert.Equal(t, uint32(11811), binary.BigEndian.Uint32(data[41:45]))
+	_, err = png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	buf.Reset()
+	w = NewWithWriter(nopCloser{Writer: buf}, WithBuiltinImageEncoder(JPEG_FORMAT), WithDPI(300))
+	require.NoError(t, qrc.Save(w))
+	data = buf.Bytes()
+	assert.Equal(t, []byte{0xff, 0xe0}, data[2:4])
+	assert.Equal(t, "JFIF", string(data[6:10]))
+	assert.Equal(t, uint16(300), binary.BigEndian.Uint16(data[14:16]))
+	_, err = jpeg.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	buf.Reset()
+	w = NewWithWriter(nopCloser{Writer: buf}, WithBuiltinImageEncoder(SVG_FORMAT), WithPhysicalSize(25, 300))
+	require.NoError(t, qrc.Save(w))
+	assert.Contains(t, buf.String(), `mm" viewBox="0 0 `)
+}
@@ -0,0 +1,144 @@
+package standard
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// gifEncoder encodes QR code to GIF, the image is drawn in the palette of colors in it,
+// or quantized by image/gif if there are more than 256 colors.
+type gifEncoder struct{}
+
+func (e gifEncoder) Encode(w io.Writer, img image.Image) error {
+	if p := reducePalette(img, 256); p != nil {
+		img = p
+	}
+
+	return gif.Encode(w, img, nil)
+}
+
+// bmpEncoder encodes QR code to BMP, 8-bit paletted if there are at most 256 colors.
+type bmpEncoder struct{}
+
+func (e bmpEncoder) Encode(w io.Writer, img image.Image) error {
+	if p := reducePalette(img, 256); p != nil {
+		img = p
+	}
+
+	return bmp.Encode(w, img)
+}
+
+// tiffEncoder encodes QR code to TIFF. The image in two opaque colors is written as
+// bilevel image compressed by CCITT Group 4 (the darker color is black), which is
+// preferred by fax and print workflows. Others are compressed by deflate.
+type tiffEncoder struct{}
+
+func (e tiffEncoder) Encode(w io.Writer, img image.Image) error {
+	return e.EncodeWithOptions(w, img, nil)
+}
+
+// EncodeWithOptions writes the DPI into resolution tags of bilevel image.
+func (e tiffEncoder) EncodeWithOptions(w io.Writer, img image.Image, opts *outputImageOptions) error {
+	p := reducePalette(img, 256)
+	if p == nil {
+		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate})
+	}
+	if rows, ok := bilevel(p); ok {
+		dpi := 0
+		if opts != nil {
+			dpi = opts.dpi
+		}
+		return writeTIFFG4(w, rows, p.Rect.Dx(), dpi)
+	}
+
+	return tiff.Encode(w, p, &tiff.Options{Compression: tiff.Deflate})
+}
+
+// pngPalettedEncoder encodes QR code to paletted PNG if there are at most 256 colors,
+// the bit depth (1, 2, 4 or 8) is the smallest one the palette fits in.
+type pngPalettedEncoder struct{}
+
+func (e pngPalettedEncoder) Encode(w io.Writer, img image.Image) error {
+	return e.EncodeWithOptions(w, img, nil)
+}
+
+// EncodeWithOptions writes the DPI into pHYs chunk if it's set.
+func (e pngPalettedEncoder) EncodeWithOptions(w io.Writer, img image.Image, opts *outputImageOptions) error {
+	if p := reducePalette(img, 256); p != nil {
+		img = p
+	}
+
+	if opts == nil || opts.dpi <= 0 {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		return encoder.Encode(w, img)
+	}
+
+	return encodePNGWithDPI(w, img, opts.dpi)
+}
+
+// reducePalette redraws img into a paletted image of the colors used in it, in the
+// order they appear. It returns nil if there are more than maxColors colors.
+func reducePalette(img image.Image, maxColors int) *image.Paletted {
+	bounds := img.Bounds()
+	dst := image.NewPaletted(bounds, nil)
+	index := make(map[color.RGBA]uint8, maxColors)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := dst.PixOffset(bounds.Min.X, y)
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i, ok := index[c]
+			if !ok {
+				if len(dst.Palette) == maxColors {
+					return nil
+				}
+				i = uint8(len(dst.Palette))
+				index[c] = i
+				dst.Palette = append(dst.Palette, c)
+			}
+			dst.Pix[offset+x-bounds.Min.X] = i
+		}
+	}
+
+	return dst
+}
+
+// bilevel converts p in at most two opaque colors into rows of pixels, true means the
+// darker color.
+func bilevel(p *image.Paletted) ([][]bool, bool) {
+	if len(p.Palette) > 2 {
+		return nil, false
+	}
+
+	dark := make([]bool, len(p.Palette))
+	for i, c := range p.Palette {
+		if c.(color.RGBA).A != 0xff {
+			return nil, false
+		}
+		dark[i] = relativeLuminance(c.(color.RGBA)) < 0.18 // about the middle gray
+	}
+	if len(p.Palette) == 2 {
+		darker := 0
+		if relativeLuminance(p.Palette[1].(color.RGBA)) < relativeLuminance(p.Palette[0].(color.RGBA)) {
+			darker = 1
+		}
+		dark[darker], dark[1-darker] = true, false
+	}
+
+	rows := make([][]bool, p.Rect.Dy())
+	for y := range rows {
+		rows[y] = make([]bool, p.Rect.Dx())
+		offset := y * p.Stride
+		for x := range rows[y] {
+			rows[y][x] = dark[p.Pix[offset+x]]
+		}
+	}
+
+	return rows, true
+}
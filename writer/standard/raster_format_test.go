@@ -0,0 +1,81 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/ccitt"
+	"golang.org/x/image/tiff"
+)
+
+func Test_rasterFormats(t *testing.T) {
+	mat := recordMatrix(t, "Test_rasterFormats")
+	want, err := NewRenderer(WithQRWidth(3)).Render(mat)
+	require.NoError(t, err)
+
+	decoders := map[formatTyp]func(buf *bytes.Buffer) (image.Image, error){
+		GIF_FORMAT:          func(buf *bytes.Buffer) (image.Image, error) { return gif.Decode(buf) },
+		BMP_FORMAT:          func(buf *bytes.Buffer) (image.Image, error) { return bmp.Decode(buf) },
+		TIFF_FORMAT:         func(buf *bytes.Buffer) (image.Image, error) { return tiff.Decode(buf) },
+		PNG_PALETTED_FORMAT: func(buf *bytes.Buffer) (image.Image, error) { return png.Decode(buf) },
+	}
+	for format, decode := range decoders {
+		buf := bytes.NewBuffer(nil)
+		r := NewRenderer(WithQRWidth(3), WithBuiltinImageEncoder(format))
+		require.NoError(t, r.Encode(buf, mat))
+
+		got, err := decode(buf)
+		require.NoError(t, err, format)
+		assertSameImage(t, want, got)
+	}
+
+	// plain QR code is written as 1-bit PNG.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, NewRenderer(WithBuiltinImageEncoder(PNG_PALETTED_FORMAT)).Encode(buf, mat))
+	cfg, err := png.DecodeConfig(buf)
+	require.NoError(t, err)
+	assert.Len(t, cfg.ColorModel.(color.Palette), 2)
+}
+
+func Test_encodeG4(t *testing.T) {
+	// runs cross make-up codes, changes are near and far from the line above.
+	width := 2000
+	rows := make([][]bool, 6)
+	for y := range rows {
+		rows[y] = make([]bool, width)
+		for x := range rows[y] {
+			rows[y][x] = (x/(7+y*3))%2 == 1 || (y == 3 && x > 100) || (y == 4 && x > 1900)
+		}
+	}
+
+	got := image.NewGray(image.Rect(0, 0, width, len(rows)))
+	err := ccitt.DecodeIntoGray(got, bytes.NewReader(encodeG4(rows, width)), ccitt.MSB, ccitt.Group4, nil)
+	require.NoError(t, err)
+	for y := range rows {
+		for x := range rows[y] {
+			if (got.GrayAt(x, y).Y == 0) != rows[y][x] {
+				t.Fatalf("pixel (%d, %d) is not decoded", x, y)
+			}
+		}
+	}
+}
+
+func Test_reducePalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.RGBA{R: uint8(x), A: 0xff})
+	}
+
+	assert.Nil(t, reducePalette(img, 3))
+	p := reducePalette(img, 4)
+	require.NotNil(t, p)
+	assert.Len(t, p.Palette, 4)
+	assert.Equal(t, img.At(2, 0), p.At(2, 0))
+}
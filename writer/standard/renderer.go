@@ -0,0 +1,132 @@
+package standard
+
+import (
+	"image"
+	"io"
+
+	"github.com/yeqown/go-qrcode/v2"
+	drawpkg "golang.org/x/image/draw"
+)
+
+// Renderer renders QR code matrix with a set of options. Unlike Writer, it is not
+// bound to any output, so it could be built once to render many QR codes in the same
+// style. Renderer is safe for concurrent use.
+type Renderer struct {
+	option *outputImageOptions
+}
+
+// NewRenderer creates a Renderer with options, options are applied once here. Errors
+// of options (such as a logo file could not be read) are returned when rendering.
+func NewRenderer(opts ...ImageOption) *Renderer {
+	dst := defaultOutputImageOption()
+	for _, opt := range opts {
+		opt.apply(dst)
+	}
+
+	return &Renderer{option: dst}
+}
+
+// Render draws mat into an image without encoding it. The image is always raster,
+// even if the image encoder is SVG.
+func (r *Renderer) Render(mat qrcode.Matrix) (image.Image, error) {
+	if r.option.err != nil {
+		return nil, r.option.err
+	}
+
+	option := r.rasterOption().resolve(mat.Width())
+	if option.strictLint {
+		if err := lintError(option.lint(mat)); err != nil {
+			return nil, err
+		}
+	}
+
+	return draw(mat, option)
+}
+
+// Encode draws mat and encodes it into w by the image encoder, w is not closed.
+func (r *Renderer) Encode(w io.Writer, mat qrcode.Matrix) error {
+	return drawTo(w, mat, r.option)
+}
+
+// DrawOnto draws mat into rect of dst. The QR code is drawn natively at the size of
+// the shorter edge of rect (as WithResolution does) and centered, the frame (if any)
+// is scaled down to fit rect. Transparent background keeps the pixels of dst.
+func (r *Renderer) DrawOnto(dst drawpkg.Image, rect image.Rectangle, mat qrcode.Matrix) error {
+	img, target, err := r.renderFit(rect.Intersect(dst.Bounds()), mat, r.option)
+	if err != nil || img == nil {
+		return err
+	}
+
+	if target.Size() == img.Bounds().Size() {
+		drawpkg.Draw(dst, target, img, img.Bounds().Min, drawpkg.Over)
+		return nil
+	}
+
+	drawpkg.ApproxBiLinear.Scale(dst, target, img, img.Bounds(), drawpkg.Over, nil)
+	return nil
+}
+
+// renderFit renders mat with option at the size of the shorter edge of rect, and
+// returns the centered rectangle in rect which the image should be drawn into. The
+// image is nil if rect is empty.
+func (r *Renderer) renderFit(rect image.Rectangle, mat qrcode.Matrix, option *outputImageOptions) (
+	image.Image, image.Rectangle, error) {
+	if option.err != nil {
+		return nil, image.Rectangle{}, option.err
+	}
+
+	size := rect.Dx()
+	if rect.Dy() < size {
+		size = rect.Dy()
+	}
+	if size <= 0 {
+		return nil, image.Rectangle{}, nil
+	}
+
+	cp := *option
+	if !cp.isRaster() {
+		cp.imageEncoder = pngEncoder{}
+	}
+	cp.resolution = &size
+	option = cp.resolve(mat.Width())
+	if option.strictLint {
+		if err := lintError(option.lint(mat)); err != nil {
+			return nil, image.Rectangle{}, err
+		}
+	}
+
+	img, err := draw(mat, option)
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+
+	// fit the image (larger than size with frame) into rect, keep the aspect ratio.
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w > rect.Dx() || h > rect.Dy() {
+		if w*rect.Dy() > h*rect.Dx() {
+			w, h = rect.Dx(), h*rect.Dx()/w
+		} else {
+			w, h = w*rect.Dy()/h, rect.Dy()
+		}
+	}
+	at := rect.Min.Add(image.Pt((rect.Dx()-w)/2, (rect.Dy()-h)/2))
+
+	return img, image.Rectangle{Min: at, Max: at.Add(image.Pt(w, h))}, nil
+}
+
+// Attribute returns the size of the image which would be rendered for QR code of
+// dimension modules.
+func (r *Renderer) Attribute(dimension int) *Attribute {
+	return r.option.preCalculateAttribute(dimension)
+}
+
+// rasterOption returns a copy of options, with PNG encoder if the encoder is not raster,
+// so that the layout is decided as raster.
+func (r *Renderer) rasterOption() *outputImageOptions {
+	option := *r.option
+	if !option.isRaster() {
+		option.imageEncoder = pngEncoder{}
+	}
+
+	return &option
+}
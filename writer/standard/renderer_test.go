@@ -0,0 +1,78 @@
+package standard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_Renderer(t *testing.T) {
+	qrc, err := qrcode.New("Test_Renderer")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	r := NewRenderer(WithBuiltinImageEncoder(PNG_FORMAT), WithQRWidth(10))
+	img, err := r.Render(mat)
+	require.NoError(t, err)
+	attr := r.Attribute(mat.Width())
+	assert.Equal(t, image.Rect(0, 0, attr.W, attr.H), img.Bounds())
+
+	// the renderer is reused and shared by goroutines, the output is the same.
+	want := bytes.NewBuffer(nil)
+	require.NoError(t, r.Encode(want, mat))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := bytes.NewBuffer(nil)
+			assert.NoError(t, r.Encode(buf, mat))
+			assert.Equal(t, want.Bytes(), buf.Bytes())
+		}()
+	}
+	wg.Wait()
+
+	// Writer is an adapter of Renderer.
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, qrc.Save(NewWithRenderer(nopCloser{Writer: buf}, r)))
+	assert.Equal(t, want.Bytes(), buf.Bytes())
+
+	// Render is raster even if the encoder is SVG.
+	svgRenderer := NewRenderer(WithBuiltinImageEncoder(SVG_FORMAT))
+	img, err = svgRenderer.Render(mat)
+	require.NoError(t, err)
+	assert.Equal(t, svgRenderer.Attribute(mat.Width()).W, img.Bounds().Dx())
+}
+
+func Test_Renderer_DrawOnto(t *testing.T) {
+	qrc, err := qrcode.New("Test_Renderer_DrawOnto")
+	require.NoError(t, err)
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	dst := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for i := 0; i < len(dst.Pix); i += 4 {
+		copy(dst.Pix[i:], []uint8{red.R, red.G, red.B, red.A})
+	}
+
+	r := NewRenderer(WithBgTransparent())
+	require.NoError(t, r.DrawOnto(dst, image.Rect(100, 50, 350, 250), qrc.Matrix()))
+
+	// 200x200 centered in rect, transparent background keeps dst.
+	assert.Equal(t, red, dst.RGBAAt(124, 150))
+	assert.Equal(t, red, dst.RGBAAt(126, 150))
+	assert.Equal(t, red, dst.RGBAAt(326, 150))
+
+	// the top-left finder pattern.
+	size := 200
+	option := r.rasterOption()
+	option.resolution = &size
+	borders := option.resolve(qrc.Dimension()).borderWidths
+	assert.Equal(t, color_BLACK, dst.RGBAAt(125+borders[3]+1, 50+borders[0]+1))
+}
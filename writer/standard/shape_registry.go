@@ -0,0 +1,138 @@
+package standard
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// shapeRegistry keeps shapes by name, so that they could be chosen by string, such
+// as in Style. The shapes package registers its shapes when it is imported.
+var shapeRegistry = struct {
+	sync.RWMutex
+
+	blocks   map[string]func(ctx *DrawContext)
+	finders  map[string]func(ctx *DrawContext)
+	patterns map[string]IFinderShape
+}{
+	blocks: map[string]func(ctx *DrawContext){
+		"rectangle": rectangle{}.Draw,
+		"circle":    circle{}.Draw,
+	},
+	finders: map[string]func(ctx *DrawContext){
+		"rectangle": rectangle{}.DrawFinder,
+		"circle":    circle{}.DrawFinder,
+	},
+	patterns: map[string]IFinderShape{},
+}
+
+// RegisterBlockShape registers the function drawing data modules by name, it replaces
+// the shape registered with the same name.
+func RegisterBlockShape(name string, draw func(ctx *DrawContext)) {
+	shapeRegistry.Lock()
+	defer shapeRegistry.Unlock()
+
+	shapeRegistry.blocks[name] = draw
+}
+
+// RegisterFinderShape registers the function drawing finder modules (one module each
+// time, as IShape.DrawFinder) by name.
+func RegisterFinderShape(name string, draw func(ctx *DrawContext)) {
+	shapeRegistry.Lock()
+	defer shapeRegistry.Unlock()
+
+	shapeRegistry.finders[name] = draw
+}
+
+// RegisterFinderPattern registers the shape drawing finder patterns as a whole (as
+// WithFinderShape) by name.
+func RegisterFinderPattern(name string, shape IFinderShape) {
+	shapeRegistry.Lock()
+	defer shapeRegistry.Unlock()
+
+	shapeRegistry.patterns[name] = shape
+}
+
+// LookupShape assembles the IShape from the registered block and finder shapes, empty
+// name means "rectangle".
+func LookupShape(block, finder string) (IShape, error) {
+	if block == "" {
+		block = "rectangle"
+	}
+	if finder == "" {
+		finder = "rectangle"
+	}
+
+	// built-in shapes are kept as they are, so that they are drawn in the fast way.
+	if block == finder {
+		switch block {
+		case "rectangle":
+			return _shapeRectangle, nil
+		case "circle":
+			return _shapeCircle, nil
+		}
+	}
+
+	shapeRegistry.RLock()
+	defer shapeRegistry.RUnlock()
+
+	drawBlock, ok := shapeRegistry.blocks[block]
+	if !ok {
+		return nil, fmt.Errorf("unknown block shape %q", block)
+	}
+	drawFinder, ok := shapeRegistry.finders[finder]
+	if !ok {
+		return nil, fmt.Errorf("unknown finder shape %q", finder)
+	}
+
+	return namedShape{draw: drawBlock, drawFinder: drawFinder}, nil
+}
+
+// LookupFinderPattern returns the registered finder pattern shape.
+func LookupFinderPattern(name string) (IFinderShape, error) {
+	shapeRegistry.RLock()
+	defer shapeRegistry.RUnlock()
+
+	shape, ok := shapeRegistry.patterns[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown finder pattern %q", name)
+	}
+
+	return shape, nil
+}
+
+// ShapeNames returns the sorted names of registered block shapes, finder shapes and
+// finder patterns.
+func ShapeNames() (blocks, finders, patterns []string) {
+	shapeRegistry.RLock()
+	defer shapeRegistry.RUnlock()
+
+	for name := range shapeRegistry.blocks {
+		blocks = append(blocks, name)
+	}
+	for name := range shapeRegistry.finders {
+		finders = append(finders, name)
+	}
+	for name := range shapeRegistry.patterns {
+		patterns = append(patterns, name)
+	}
+	sort.Strings(blocks)
+	sort.Strings(finders)
+	sort.Strings(patterns)
+
+	return blocks, finders, patterns
+}
+
+// namedShape is the IShape assembled by LookupShape.
+type namedShape struct {
+	draw       func(ctx *DrawContext)
+	drawFinder func(ctx *DrawContext)
+}
+
+func (s namedShape) Draw(ctx *DrawContext) {
+	s.draw(ctx)
+}
+
+func (s namedShape) DrawFinder(ctx *DrawContext) {
+	s.drawFinder(ctx)
+}
@@ -0,0 +1,201 @@
+package shapes
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/yeqown/go-qrcode/writer/standard"
+)
+
+// EyeFrame is the style of the outer ring (7x7 modules) of a finder pattern.
+type EyeFrame uint8
+
+const (
+	// FrameSquare is the standard square ring.
+	FrameSquare EyeFrame = iota
+	// FrameRounded is a square ring with rounded corners.
+	FrameRounded
+	// FrameCircle is a circle ring.
+	FrameCircle
+	// FrameLeaf is a ring with two opposite rounded corners, the outer and the inner
+	// corner of the code, so that it looks like a leaf.
+	FrameLeaf
+	// FrameDotted is a ring of dots, one dot per module.
+	FrameDotted
+)
+
+// EyePupil is the style of the center (3x3 modules) of a finder pattern.
+type EyePupil uint8
+
+const (
+	// PupilSquare is the standard square pupil.
+	PupilSquare EyePupil = iota
+	// PupilCircle is a circle pupil.
+	PupilCircle
+	// PupilDiamond is a square pupil rotated 45 degrees.
+	PupilDiamond
+	// PupilStar is a five-pointed star pupil.
+	PupilStar
+)
+
+var _ standard.IFinderShape = (*EyeShape)(nil)
+
+// EyeShape implements standard.IFinderShape, it draws the frame and the pupil
+// of finder patterns in separate styles and colors.
+type EyeShape struct {
+	Frame EyeFrame
+	Pupil EyePupil
+
+	// FrameColor and PupilColor are optional, the finder color is used if nil.
+	FrameColor color.Color
+	PupilColor color.Color
+}
+
+// Eye creates an EyeShape with frame and pupil styles, the colors are optional,
+// nil means to use the finder color (WithFinderColor, WithFgColor and etc).
+// Use it with standard.WithFinderShape.
+func Eye(frame EyeFrame, pupil EyePupil, frameColor, pupilColor color.Color) *EyeShape {
+	return &EyeShape{
+		Frame:      frame,
+		Pupil:      pupil,
+		FrameColor: frameColor,
+		PupilColor: pupilColor,
+	}
+}
+
+// DrawFinderPattern implements standard.IFinderShape.
+func (e *EyeShape) DrawFinderPattern(ctx *standard.FinderDrawContext) {
+	frameColor, pupilColor := e.FrameColor, e.PupilColor
+	if frameColor == nil {
+		frameColor = ctx.Color()
+	}
+	if pupilColor == nil {
+		pupilColor = ctx.Color()
+	}
+
+	ctx.SetColor(frameColor)
+	e.drawFrame(ctx)
+	ctx.SetColor(pupilColor)
+	e.drawPupil(ctx)
+}
+
+func (e *EyeShape) drawFrame(ctx *standard.FinderDrawContext) {
+	x, y := ctx.UpperLeft()
+	size, m := ctx.Edge(), ctx.ModuleWidth()
+	cx, cy := x+size/2, y+size/2
+
+	switch e.Frame {
+	case FrameRounded:
+		ctx.SetFillRuleEvenOdd()
+		roundedRectangle(ctx, x, y, size, size, uniformRadii(2*m))
+		roundedRectangle(ctx, x+m, y+m, size-2*m, size-2*m, uniformRadii(m))
+		ctx.Fill()
+	case FrameCircle:
+		ctx.SetFillRuleEvenOdd()
+		ctx.DrawCircle(cx, cy, size/2)
+		ctx.DrawCircle(cx, cy, size/2-m)
+		ctx.Fill()
+	case FrameLeaf:
+		outer, inner := leafRadii(ctx.Position(), 3*m), leafRadii(ctx.Position(), 2*m)
+		ctx.SetFillRuleEvenOdd()
+		roundedRectangle(ctx, x, y, size, size, outer)
+		roundedRectangle(ctx, x+m, y+m, size-2*m, size-2*m, inner)
+		ctx.Fill()
+	case FrameDotted:
+		r := m * 0.4
+		for i := 0; i < 7; i++ {
+			for j := 0; j < 7; j++ {
+				if i != 0 && i != 6 && j != 0 && j != 6 {
+					continue
+				}
+				ctx.DrawCircle(x+(float64(i)+0.5)*m, y+(float64(j)+0.5)*m, r)
+			}
+		}
+		ctx.Fill()
+	default:
+		ctx.SetFillRuleEvenOdd()
+		ctx.DrawRectangle(x, y, size, size)
+		ctx.DrawRectangle(x+m, y+m, size-2*m, size-2*m)
+		ctx.Fill()
+	}
+
+	ctx.SetFillRuleWinding()
+}
+
+func (e *EyeShape) drawPupil(ctx *standard.FinderDrawContext) {
+	x, y := ctx.UpperLeft()
+	size, m := ctx.Edge(), ctx.ModuleWidth()
+	cx, cy := x+size/2, y+size/2
+	half := 1.5 * m
+
+	switch e.Pupil {
+	case PupilCircle:
+		ctx.DrawCircle(cx, cy, half)
+	case PupilDiamond:
+		ctx.MoveTo(cx, cy-half)
+		ctx.LineTo(cx+half, cy)
+		ctx.LineTo(cx, cy+half)
+		ctx.LineTo(cx-half, cy)
+		ctx.ClosePath()
+	case PupilStar:
+		const points = 5
+		for i := 0; i < 2*points; i++ {
+			r := half
+			if i%2 == 1 {
+				r = half * 0.45
+			}
+			angle := -math.Pi/2 + float64(i)*math.Pi/points
+			px, py := cx+r*math.Cos(angle), cy+r*math.Sin(angle)
+			if i == 0 {
+				ctx.MoveTo(px, py)
+				continue
+			}
+			ctx.LineTo(px, py)
+		}
+		ctx.ClosePath()
+	default:
+		ctx.DrawRectangle(cx-half, cy-half, 2*half, 2*half)
+	}
+
+	ctx.Fill()
+}
+
+// uniformRadii returns the same radius for 4 corners.
+func uniformRadii(r float64) [4]float64 {
+	return [4]float64{r, r, r, r}
+}
+
+// leafRadii rounds the corner which is far away from the code center and the
+// opposite one, in the order of top-left, top-right, bottom-right, bottom-left.
+func leafRadii(position standard.FinderPosition, r float64) [4]float64 {
+	if position == standard.FinderTopLeft || position == standard.FinderBottomRight {
+		return [4]float64{r, 0, r, 0}
+	}
+
+	return [4]float64{0, r, 0, r}
+}
+
+// roundedRectangle adds a closed rectangle path whose corners are rounded with
+// radii in the order of top-left, top-right, bottom-right, bottom-left.
+func roundedRectangle(ctx *standard.FinderDrawContext, x, y, w, h float64, radii [4]float64) {
+	tl, tr, br, bl := radii[0], radii[1], radii[2], radii[3]
+
+	ctx.MoveTo(x+tl, y)
+	ctx.LineTo(x+w-tr, y)
+	if tr > 0 {
+		ctx.QuadraticTo(x+w, y, x+w, y+tr)
+	}
+	ctx.LineTo(x+w, y+h-br)
+	if br > 0 {
+		ctx.QuadraticTo(x+w, y+h, x+w-br, y+h)
+	}
+	ctx.LineTo(x+bl, y+h)
+	if bl > 0 {
+		ctx.QuadraticTo(x, y+h, x, y+h-bl)
+	}
+	ctx.LineTo(x, y+tl)
+	if tl > 0 {
+		ctx.QuadraticTo(x, y, x+tl, y)
+	}
+	ctx.ClosePath()
+}
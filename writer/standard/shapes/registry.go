@@ -0,0 +1,50 @@
+package shapes
+
+import "github.com/yeqown/go-qrcode/writer/standard"
+
+// Shapes in this package are registered into standard by name when the package is
+// imported, so that they could be chosen in standard.Style, for example:
+//
+//	import _ "github.com/yeqown/go-qrcode/writer/standard/shapes"
+//
+//	style := standard.Style{Shape: &standard.ShapeStyle{Block: "liquid", Finder: "rounded"}}
+//
+// Finder patterns are named as "<frame>-<pupil>", such as "leaf-circle", colors of
+// them are the finder color.
+func init() {
+	blocks := map[string]func(ctx *standard.DrawContext){
+		"liquid":        LiquidBlock(),
+		"chain":         ChainBlock(),
+		"vchain":        VChainBlock(),
+		"hchain":        HChainBlock(),
+		"hstripe":       HStripeBlock(0.85),
+		"vstripe":       VStripeBlock(0.85),
+		"square-blocks": SquareBlocks(0.8),
+		"circle-blocks": CircleBlocks(0.8),
+	}
+	for name, draw := range blocks {
+		standard.RegisterBlockShape(name, draw)
+	}
+
+	standard.RegisterFinderShape("rounded", RoundedFinder())
+	standard.RegisterFinderShape("square", SquareFinder())
+
+	frames := map[string]EyeFrame{
+		"square":  FrameSquare,
+		"rounded": FrameRounded,
+		"circle":  FrameCircle,
+		"leaf":    FrameLeaf,
+		"dotted":  FrameDotted,
+	}
+	pupils := map[string]EyePupil{
+		"square":  PupilSquare,
+		"circle":  PupilCircle,
+		"diamond": PupilDiamond,
+		"star":    PupilStar,
+	}
+	for frameName, frame := range frames {
+		for pupilName, pupil := range pupils {
+			standard.RegisterFinderPattern(frameName+"-"+pupilName, Eye(frame, pupil, nil, nil))
+		}
+	}
+}
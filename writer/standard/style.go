@@ -0,0 +1,450 @@
+package standard
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
+)
+
+// Style is the declarative form of image options, it could be stored, diffed and
+// shared as JSON (or YAML, field tags are set for both). Empty fields keep the
+// default options. Colors are hex strings such as "#1f3a93" or "#fff", shapes are
+// chosen by registered names (see RegisterBlockShape), and images are referenced by
+// file path. Options which take Go values, such as WithModuleColorFunc and
+// WithCustomImageEncoder, could not be expressed and are applied after WithStyle.
+type Style struct {
+	// Background is a hex color or "transparent".
+	Background string `json:"background,omitempty" yaml:"background,omitempty"`
+	// Foreground is the color of all dark modules, DataColor and FinderColor
+	// override it for data modules and finder patterns.
+	Foreground  string `json:"foreground,omitempty" yaml:"foreground,omitempty"`
+	DataColor   string `json:"dataColor,omitempty" yaml:"dataColor,omitempty"`
+	FinderColor string `json:"finderColor,omitempty" yaml:"finderColor,omitempty"`
+
+	Gradient *GradientStyle `json:"gradient,omitempty" yaml:"gradient,omitempty"`
+
+	Shape *ShapeStyle `json:"shape,omitempty" yaml:"shape,omitempty"`
+	// FinderPattern is the registered name of the shape drawing finder patterns as a
+	// whole, such as "rounded-circle" from the shapes package.
+	FinderPattern string `json:"finderPattern,omitempty" yaml:"finderPattern,omitempty"`
+
+	// ModuleWidth is the pixels of each module, from 1 to 255.
+	ModuleWidth int `json:"moduleWidth,omitempty" yaml:"moduleWidth,omitempty"`
+	// Borders in pixels: [all], [top/bottom, left/right] or [top, right, bottom, left].
+	Borders []int `json:"borders,omitempty" yaml:"borders,omitempty"`
+	// QuietZone in modules overrides Borders.
+	QuietZone *int `json:"quietZone,omitempty" yaml:"quietZone,omitempty"`
+	// Resolution is the edge length of raster output in pixels.
+	Resolution   int                `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+	PhysicalSize *PhysicalSizeStyle `json:"physicalSize,omitempty" yaml:"physicalSize,omitempty"`
+	DPI          int                `json:"dpi,omitempty" yaml:"dpi,omitempty"`
+
+	// Format is the image encoder: "jpeg" (or "jpg"), "png", "svg", "gif", "bmp", "tiff"
+	// (or "tif") or "png-paletted", case-insensitive.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	Logo     *LogoStyle     `json:"logo,omitempty" yaml:"logo,omitempty"`
+	Frame    *FrameStyle    `json:"frame,omitempty" yaml:"frame,omitempty"`
+	Halftone *HalftoneStyle `json:"halftone,omitempty" yaml:"halftone,omitempty"`
+	Lint     *LintStyle     `json:"lint,omitempty" yaml:"lint,omitempty"`
+}
+
+// GradientStyle is the linear gradient of dark modules.
+type GradientStyle struct {
+	// Angle in degrees.
+	Angle float64     `json:"angle" yaml:"angle"`
+	Stops []StopStyle `json:"stops" yaml:"stops"`
+}
+
+// StopStyle is a gradient color stop, Offset is from 0 to 1.
+type StopStyle struct {
+	Offset float64 `json:"offset" yaml:"offset"`
+	Color  string  `json:"color" yaml:"color"`
+}
+
+// ShapeStyle names the registered shapes of data modules and finder modules, empty
+// means "rectangle".
+type ShapeStyle struct {
+	Block  string `json:"block,omitempty" yaml:"block,omitempty"`
+	Finder string `json:"finder,omitempty" yaml:"finder,omitempty"`
+}
+
+// PhysicalSizeStyle is the printed width (quiet zone included) in millimetre at DPI.
+type PhysicalSizeStyle struct {
+	MM  float64 `json:"mm" yaml:"mm"`
+	DPI int     `json:"dpi" yaml:"dpi"`
+}
+
+// LogoStyle references the logo file and how it's placed.
+type LogoStyle struct {
+	// File is the path of the logo in any registered image format or SVG.
+	File string `json:"file" yaml:"file"`
+	// Scale is the fraction of the QR code width, SizeMultiplier limits the logo
+	// size if Scale is not set.
+	Scale          float64 `json:"scale,omitempty" yaml:"scale,omitempty"`
+	SizeMultiplier int     `json:"sizeMultiplier,omitempty" yaml:"sizeMultiplier,omitempty"`
+	SafeZone       bool    `json:"safeZone,omitempty" yaml:"safeZone,omitempty"`
+	// Crop is "square" (no crop), "rounded" or "circle".
+	Crop  string          `json:"crop,omitempty" yaml:"crop,omitempty"`
+	Plate *LogoPlateStyle `json:"plate,omitempty" yaml:"plate,omitempty"`
+	// Check refuses to render if the logo is not safe.
+	Check bool `json:"check,omitempty" yaml:"check,omitempty"`
+}
+
+// LogoPlateStyle is the plate under the logo, the background color is used if Color
+// is empty.
+type LogoPlateStyle struct {
+	Shape   string `json:"shape,omitempty" yaml:"shape,omitempty"`
+	Color   string `json:"color,omitempty" yaml:"color,omitempty"`
+	Padding int    `json:"padding,omitempty" yaml:"padding,omitempty"`
+}
+
+// FrameStyle is the caption frame around the QR code.
+type FrameStyle struct {
+	// Template is "rounded-card" (default), "speech-bubble", "bottom-banner" or
+	// "top-bottom-labels".
+	Template  string `json:"template,omitempty" yaml:"template,omitempty"`
+	Text      string `json:"text,omitempty" yaml:"text,omitempty"`
+	SubText   string `json:"subText,omitempty" yaml:"subText,omitempty"`
+	Color     string `json:"color,omitempty" yaml:"color,omitempty"`
+	TextColor string `json:"textColor,omitempty" yaml:"textColor,omitempty"`
+	// FontFile is the path of a TTF/OTF font.
+	FontFile       string `json:"fontFile,omitempty" yaml:"fontFile,omitempty"`
+	TextAsOutlines bool   `json:"textAsOutlines,omitempty" yaml:"textAsOutlines,omitempty"`
+}
+
+// HalftoneStyle references the halftone image and how it's dithered.
+type HalftoneStyle struct {
+	File string `json:"file" yaml:"file"`
+	Grid int    `json:"grid,omitempty" yaml:"grid,omitempty"`
+	// Dither is "threshold" (default), "bayer", "floyd-steinberg" or "atkinson".
+	Dither    string `json:"dither,omitempty" yaml:"dither,omitempty"`
+	Threshold *int   `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Color     bool   `json:"color,omitempty" yaml:"color,omitempty"`
+}
+
+// LintStyle configures Lint and strict lint.
+type LintStyle struct {
+	DPI    int  `json:"dpi,omitempty" yaml:"dpi,omitempty"`
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+}
+
+var (
+	styleFormats = map[string]formatTyp{
+		"jpeg": JPEG_FORMAT,
+		"jpg":  JPEG_FORMAT,
+		"png":  PNG_FORMAT,
+		"svg":  SVG_FORMAT,
+		"gif":  GIF_FORMAT,
+		"bmp":  BMP_FORMAT,
+		"tiff": TIFF_FORMAT,
+		"tif":  TIFF_FORMAT,
+
+		"png-paletted": PNG_PALETTED_FORMAT,
+	}
+
+	styleLogoShapes = map[string]LogoShape{
+		"square":  LogoSquare,
+		"rounded": LogoRounded,
+		"circle":  LogoCircle,
+	}
+
+	styleFrameTemplates = map[string]FrameTemplate{
+		"rounded-card":      FrameRoundedCard,
+		"speech-bubble":     FrameSpeechBubble,
+		"bottom-banner":     FrameBottomBanner,
+		"top-bottom-labels": FrameTopBottomLabels,
+	}
+
+	styleDitherMethods = map[string]imgkit.DitherMethod{
+		"threshold":       imgkit.DitherThreshold,
+		"bayer":           imgkit.DitherBayer,
+		"floyd-steinberg": imgkit.DitherFloydSteinberg,
+		"atkinson":        imgkit.DitherAtkinson,
+	}
+)
+
+// ParseStyle unmarshals the JSON style and validates it.
+func ParseStyle(data []byte) (*Style, error) {
+	style := new(Style)
+	if err := json.Unmarshal(data, style); err != nil {
+		return nil, fmt.Errorf("parse style failed: %w", err)
+	}
+	if err := style.Validate(); err != nil {
+		return nil, err
+	}
+
+	return style, nil
+}
+
+// Validate checks colors, names and ranges in the style, files are not read.
+func (s *Style) Validate() error {
+	_, err := s.Options()
+	return err
+}
+
+// Options converts the style into image options, it fails if the style is invalid.
+func (s *Style) Options() ([]ImageOption, error) {
+	var (
+		opts     []ImageOption
+		problems []string
+	)
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	// parseColor returns false if hex is empty or invalid.
+	parseColor := func(field, hex string) (color.RGBA, bool) {
+		if hex == "" {
+			return color.RGBA{}, false
+		}
+		c, err := parseHex(hex)
+		if err != nil {
+			fail("%s: invalid color %q", field, hex)
+			return color.RGBA{}, false
+		}
+		return c, true
+	}
+
+	switch s.Background {
+	case "":
+	case "transparent":
+		opts = append(opts, WithBgTransparent())
+	default:
+		if c, ok := parseColor("background", s.Background); ok {
+			opts = append(opts, WithBgColor(c))
+		}
+	}
+	if c, ok := parseColor("foreground", s.Foreground); ok {
+		opts = append(opts, WithFgColor(c))
+	}
+	if c, ok := parseColor("dataColor", s.DataColor); ok {
+		opts = append(opts, WithDataColor(c))
+	}
+	if c, ok := parseColor("finderColor", s.FinderColor); ok {
+		opts = append(opts, WithFinderColor(c))
+	}
+
+	if g := s.Gradient; g != nil {
+		if len(g.Stops) < 2 {
+			fail("gradient: at least 2 stops are required")
+		}
+		stops := make([]ColorStop, 0, len(g.Stops))
+		for i, stop := range g.Stops {
+			if stop.Offset < 0 || stop.Offset > 1 {
+				fail("gradient.stops[%d]: offset %v is out of [0, 1]", i, stop.Offset)
+			}
+			if stop.Color == "" {
+				fail("gradient.stops[%d]: color is required", i)
+			}
+			c, _ := parseColor(fmt.Sprintf("gradient.stops[%d]", i), stop.Color)
+			stops = append(stops, ColorStop{T: stop.Offset, Color: c})
+		}
+		opts = append(opts, WithFgGradient(NewGradient(g.Angle, stops...)))
+	}
+
+	if s.Shape != nil {
+		shape, err := LookupShape(s.Shape.Block, s.Shape.Finder)
+		if err != nil {
+			fail("shape: %v", err)
+		} else {
+			opts = append(opts, WithCustomShape(shape))
+		}
+	}
+	if s.FinderPattern != "" {
+		pattern, err := LookupFinderPattern(s.FinderPattern)
+		if err != nil {
+			fail("finderPattern: %v", err)
+		} else {
+			opts = append(opts, WithFinderShape(pattern))
+		}
+	}
+
+	if s.ModuleWidth != 0 {
+		if s.ModuleWidth < 1 || s.ModuleWidth > 255 {
+			fail("moduleWidth: %d is out of [1, 255]", s.ModuleWidth)
+		}
+		opts = append(opts, WithQRWidth(uint8(s.ModuleWidth)))
+	}
+	switch len(s.Borders) {
+	case 0:
+	case 1, 2, 4:
+		opts = append(opts, WithBorderWidth(s.Borders...))
+	default:
+		fail("borders: 1, 2 or 4 values are required, got %d", len(s.Borders))
+	}
+	if s.QuietZone != nil {
+		if *s.QuietZone < 0 {
+			fail("quietZone: %d is negative", *s.QuietZone)
+		}
+		opts = append(opts, WithQuietZone(*s.QuietZone))
+	}
+	if s.Resolution != 0 {
+		if s.Resolution < 0 {
+			fail("resolution: %d is negative", s.Resolution)
+		}
+		resolution := s.Resolution
+		opts = append(opts, WithResolution(&resolution))
+	}
+	if p := s.PhysicalSize; p != nil {
+		if p.MM <= 0 || p.DPI <= 0 {
+			fail("physicalSize: mm and dpi should be positive")
+		}
+		opts = append(opts, WithPhysicalSize(p.MM, p.DPI))
+	}
+	if s.DPI != 0 {
+		opts = append(opts, WithDPI(s.DPI))
+	}
+
+	if s.Format != "" {
+		format, ok := styleFormats[strings.ToLower(s.Format)]
+		if !ok {
+			fail("format: unknown format %q", s.Format)
+		}
+		opts = append(opts, WithBuiltinImageEncoder(format))
+	}
+
+	if l := s.Logo; l != nil {
+		if l.File == "" {
+			fail("logo.file: is required")
+		}
+		opts = append(opts, WithLogoImageFile(l.File))
+		if l.Scale != 0 {
+			if l.Scale <= 0 || l.Scale >= 1 {
+				fail("logo.scale: %v is out of (0, 1)", l.Scale)
+			}
+			opts = append(opts, WithLogoScale(l.Scale))
+		}
+		if l.SizeMultiplier != 0 {
+			opts = append(opts, WithLogoSizeMultiplier(l.SizeMultiplier))
+		}
+		if l.SafeZone {
+			opts = append(opts, WithLogoSafeZone())
+		}
+		if l.Crop != "" {
+			crop, ok := styleLogoShapes[l.Crop]
+			if !ok {
+				fail("logo.crop: unknown shape %q", l.Crop)
+			}
+			opts = append(opts, WithLogoCrop(crop))
+		}
+		if p := l.Plate; p != nil {
+			shape, ok := styleLogoShapes[p.Shape]
+			if p.Shape == "" {
+				shape, ok = LogoSquare, true
+			}
+			if !ok {
+				fail("logo.plate.shape: unknown shape %q", p.Shape)
+			}
+			if c, ok := parseColor("logo.plate.color", p.Color); ok {
+				opts = append(opts, WithLogoPlate(shape, c, p.Padding))
+			} else {
+				opts = append(opts, WithLogoPlate(shape, nil, p.Padding))
+			}
+		}
+		if l.Check {
+			opts = append(opts, WithLogoCheck())
+		}
+	}
+
+	if f := s.Frame; f != nil {
+		frame := Frame{Text: f.Text, SubText: f.SubText, TextAsOutlines: f.TextAsOutlines}
+		if f.Template != "" {
+			template, ok := styleFrameTemplates[f.Template]
+			if !ok {
+				fail("frame.template: unknown template %q", f.Template)
+			}
+			frame.Template = template
+		}
+		if c, ok := parseColor("frame.color", f.Color); ok {
+			frame.Color = c
+		}
+		if c, ok := parseColor("frame.textColor", f.TextColor); ok {
+			frame.TextColor = c
+		}
+		fontFile := f.FontFile
+		opts = append(opts, newFuncOption(func(oo *outputImageOptions) {
+			frame := frame
+			if fontFile != "" {
+				font, err := os.ReadFile(fontFile)
+				if err != nil {
+					oo.fail(fmt.Errorf("read frame font(%s): %w", fontFile, err))
+					return
+				}
+				frame.Font = font
+			}
+			WithFrame(frame).apply(oo)
+		}))
+	}
+
+	if h := s.Halftone; h != nil {
+		if h.File == "" {
+			fail("halftone.file: is required")
+		}
+		opts = append(opts, WithHalftone(h.File))
+		if h.Grid != 0 {
+			if h.Grid < _minHalftoneGrid || h.Grid > _maxHalftoneGrid {
+				fail("halftone.grid: %d is out of [%d, %d]", h.Grid, _minHalftoneGrid, _maxHalftoneGrid)
+			}
+			opts = append(opts, WithHalftoneGrid(h.Grid))
+		}
+		if h.Dither != "" || h.Threshold != nil {
+			method, ok := styleDitherMethods[h.Dither]
+			if h.Dither == "" {
+				method, ok = imgkit.DitherThreshold, true
+			}
+			if !ok {
+				fail("halftone.dither: unknown method %q", h.Dither)
+			}
+			threshold := 128
+			if method == imgkit.DitherThreshold {
+				threshold = _defaultHalftoneThreshold
+			}
+			if h.Threshold != nil {
+				threshold = *h.Threshold
+			}
+			if threshold < 0 || threshold > 255 {
+				fail("halftone.threshold: %d is out of [0, 255]", threshold)
+			}
+			opts = append(opts, WithHalftoneDither(method, uint8(threshold)))
+		}
+		if h.Color {
+			opts = append(opts, WithHalftoneColor())
+		}
+	}
+
+	if l := s.Lint; l != nil {
+		if l.DPI != 0 {
+			opts = append(opts, WithLintDPI(l.DPI))
+		}
+		if l.Strict {
+			opts = append(opts, WithStrictLint())
+		}
+	}
+
+	if len(problems) != 0 {
+		return nil, fmt.Errorf("invalid style: %s", strings.Join(problems, "; "))
+	}
+
+	return opts, nil
+}
+
+// WithStyle applies all options in style, an invalid style is ignored as a whole and
+// its error is returned when the writer is created or draws, use Style.Validate to
+// check it in advance.
+func WithStyle(style Style) ImageOption {
+	return newFuncOption(func(oo *outputImageOptions) {
+		opts, err := style.Options()
+		if err != nil {
+			oo.fail(err)
+			return
+		}
+
+		for _, opt := range opts {
+			opt.apply(oo)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package standard
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+func Test_Style(t *testing.T) {
+	quietZone := 4
+	style := Style{
+		Background:  "#fffdf0",
+		Foreground:  "#1f3a93",
+		FinderColor: "#c00",
+		Gradient: &GradientStyle{Angle: 45, Stops: []StopStyle{
+			{Offset: 0, Color: "#1f3a93"}, {Offset: 1, Color: "#000000"},
+		}},
+		Shape:       &ShapeStyle{Block: "circle", Finder: "circle"},
+		ModuleWidth: 12,
+		QuietZone:   &quietZone,
+		Format:      "png",
+		Lint:        &LintStyle{DPI: 300},
+	}
+
+	// marshalled and unmarshalled without loss.
+	data, err := json.Marshal(style)
+	require.NoError(t, err)
+	parsed, err := ParseStyle(data)
+	require.NoError(t, err)
+	assert.Equal(t, style, *parsed)
+
+	oo := defaultOutputImageOption()
+	WithStyle(*parsed).apply(oo)
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xfd, B: 0xf0, A: 0xff}, oo.bgColor)
+	assert.Equal(t, color.RGBA{R: 0x1f, G: 0x3a, B: 0x93, A: 0xff}, oo.qrColor)
+	assert.Equal(t, color.RGBA{R: 0xcc, A: 0xff}, oo.qrColors.getFinderColor(oo.qrColor))
+	require.NotNil(t, oo.qrGradient)
+	assert.Len(t, oo.qrGradient.Stops, 2)
+	assert.Equal(t, 12, oo.qrWidth)
+	assert.Equal(t, 4, oo.quietZone)
+	assert.Equal(t, 300, oo.lintDPI)
+	assert.IsType(t, pngEncoder{}, oo.imageEncoder)
+	// built-in shapes are kept as they are.
+	assert.Equal(t, _shapeCircle, oo.shape)
+
+	qrc, err := qrcode.New("Test_Style")
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, qrc.Save(NewWithWriter(nopCloser{Writer: buf}, WithStyle(style))))
+	assert.Equal(t, []byte("\x89PNG"), buf.Bytes()[:4])
+}
+
+func Test_Style_Validate(t *testing.T) {
+	threshold := 300
+	style := Style{
+		Background: "white",
+		Gradient:   &GradientStyle{Stops: []StopStyle{{Offset: 2, Color: "#000"}}},
+		Shape:      &ShapeStyle{Block: "no-such-shape"},
+		Borders:    []int{1, 2, 3},
+		Format:     "webp",
+		Logo:       &LogoStyle{File: "logo.png", Crop: "hexagon"},
+		Halftone:   &HalftoneStyle{File: "bg.png", Grid: 7, Dither: "bayer", Threshold: &threshold},
+	}
+
+	err := style.Validate()
+	require.Error(t, err)
+	for _, field := range []string{
+		"background", "gradient: at least 2", "gradient.stops[0]: offset", "shape: unknown block shape",
+		"borders", "format", "logo.crop", "halftone.grid", "halftone.threshold",
+	} {
+		assert.Contains(t, err.Error(), field)
+	}
+
+	// the invalid style is ignored as a whole, and its error is kept.
+	oo := defaultOutputImageOption()
+	WithStyle(style).apply(oo)
+	assert.Equal(t, defaultOutputImageOption().borderWidths, oo.borderWidths)
+	assert.Equal(t, err, oo.err)
+
+	_, err = ParseStyle([]byte(`{"background": 1}`))
+	assert.Error(t, err)
+}
+
+func Test_ShapeRegistry(t *testing.T) {
+	var drawn []string
+	RegisterBlockShape("Test_ShapeRegistry", func(ctx *DrawContext) { drawn = append(drawn, "block") })
+	RegisterFinderShape("Test_ShapeRegistry", func(ctx *DrawContext) { drawn = append(drawn, "finder") })
+
+	shape, err := LookupShape("Test_ShapeRegistry", "Test_ShapeRegistry")
+	require.NoError(t, err)
+	shape.Draw(&DrawContext{})
+	shape.DrawFinder(&DrawContext{})
+	assert.Equal(t, []string{"block", "finder"}, drawn)
+
+	shape, err = LookupShape("", "")
+	require.NoError(t, err)
+	assert.Equal(t, _shapeRectangle, shape)
+	_, err = LookupShape("circle", "no-such-shape")
+	assert.Error(t, err)
+
+	_, err = LookupFinderPattern("no-such-pattern")
+	assert.Error(t, err)
+
+	blocks, finders, _ := ShapeNames()
+	assert.Contains(t, blocks, "Test_ShapeRegistry")
+	assert.Contains(t, finders, "rectangle")
+}
@@ -5,11 +5,9 @@ import (
 	"image"
 	"image/color"
 	"io"
-	"log"
 	"os"
 
 	"github.com/yeqown/go-qrcode/v2"
-	"github.com/yeqown/go-qrcode/writer/standard/imgkit"
 
 	"github.com/fogleman/gg"
 	"github.com/pkg/errors"
@@ -21,19 +19,27 @@ var (
 	ErrNilWriter = errors.New("nil writer")
 )
 
-// Writer is a writer that writes QR Code to io.Writer.
+// Writer is a writer that writes QR Code to io.Writer, it's a Renderer bound to
+// the io.WriteCloser which is closed by QRCode.Save.
 type Writer struct {
-	option *outputImageOptions
+	renderer *Renderer
 
 	closer io.WriteCloser
 }
 
-// New creates a standard writer.
+// New creates a standard writer which writes into the file. The image encoder is
+// decided by the extension of filename (see RegisterImageEncoder), it could be
+// overridden by options. Unknown extension and errors of options are returned
+// before the file is created.
 func New(filename string, opts ...ImageOption) (*Writer, error) {
-	if _, err := os.Stat(filename); err != nil && os.IsExist(err) {
-		// custom path got: "file exists"
-		log.Printf("could not find path: %s, then save to %s", filename, _defaultFilename)
-		filename = _defaultFilename
+	byExt, err := encoderForFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRenderer(append([]ImageOption{byExt}, opts...)...)
+	if r.option.err != nil {
+		return nil, r.option.err
 	}
 
 	fd, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
@@ -41,32 +47,32 @@ func New(filename string, opts ...ImageOption) (*Writer, error) {
 		return nil, errors.Wrap(err, "create file failed")
 	}
 
-	return NewWithWriter(fd, opts...), nil
+	return NewWithRenderer(fd, r), nil
 }
 
 func NewWithWriter(writeCloser io.WriteCloser, opts ...ImageOption) *Writer {
-	dst := defaultOutputImageOption()
-	for _, opt := range opts {
-		opt.apply(dst)
-	}
+	return NewWithRenderer(writeCloser, NewRenderer(opts...))
+}
 
+// NewWithRenderer creates a standard writer which renders by r, r could be shared
+// by many writers.
+func NewWithRenderer(writeCloser io.WriteCloser, r *Renderer) *Writer {
 	if writeCloser == nil {
 		panic("writeCloser could not be nil")
 	}
 
 	return &Writer{
-		option: dst,
-		closer: writeCloser,
+		renderer: r,
+		closer:   writeCloser,
 	}
 }
 
 const (
-	_defaultFilename = "default.jpeg"
-	_defaultPadding  = 40
+	_defaultPadding = 40
 )
 
 func (w Writer) Write(mat qrcode.Matrix) error {
-	return drawTo(w.closer, mat, w.option)
+	return w.renderer.Encode(w.closer, mat)
 }
 
 func (w Writer) Close() error {
@@ -82,7 +88,7 @@ func (w Writer) Close() error {
 }
 
 func (w Writer) Attribute(dimension int) *Attribute {
-	return w.option.preCalculateAttribute(dimension)
+	return w.renderer.Attribute(dimension)
 }
 
 func drawTo(w io.Writer, mat qrcode.Matrix, option *outputImageOptions) (err error) {
@@ -94,7 +100,18 @@ func drawTo(w io.Writer, mat qrcode.Matrix, option *outputImageOptions) (err err
 		return ErrNilWriter
 	}
 
-	img := draw(mat, option)
+	if option.err != nil {
+		return option.err
+	}
+
+	// decide the module width and borders for this QR code.
+	option = option.resolve(mat.Width())
+
+	if option.strictLint {
+		if err = lintError(option.lint(mat)); err != nil {
+			return err
+		}
+	}
 
 	// DONE(@yeqown): support file format specified config option
 	// Try to use encoder with matrix if available (for SVG shape generation)
@@ -102,7 +119,14 @@ func drawTo(w io.Writer, mat qrcode.Matrix, option *outputImageOptions) (err err
 		if err = encoderWithMatrix.EncodeMatrix(w, mat, option); err != nil {
 			err = fmt.Errorf("imageEncoder.EncodeMatrix failed: %v", err)
 		}
-	} else if encoderWithOpts, ok := option.imageEncoder.(ImageEncoderWithOptions); ok {
+		return
+	}
+
+	img, err := draw(mat, option)
+	if err != nil {
+		return err
+	}
+	if encoderWithOpts, ok := option.imageEncoder.(ImageEncoderWithOptions); ok {
 		if err = encoderWithOpts.EncodeWithOptions(w, img, option); err != nil {
 			err = fmt.Errorf("imageEncoder.EncodeWithOptions failed: %v", err)
 		}
@@ -118,34 +142,32 @@ func drawTo(w io.Writer, mat qrcode.Matrix, option *outputImageOptions) (err err
 // draw deal QRCode's matrix to be an image.Image. Notice that if anyone changed this function,
 // please also check the function outputImageOptions.preCalculateAttribute().
 // When resolution is set, block size is derived so the image is drawn natively at resolution×resolution (sharp PNG/JPEG). Otherwise layout uses qrWidth and borders.
-func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
+// Plain square modules in solid colors are drawn by drawFast, others by drawWithGG.
+func draw(mat qrcode.Matrix, opt *outputImageOptions) (image.Image, error) {
+	opt = opt.forMatrix(mat)
+	if opt.canDrawFast() {
+		return drawFast(mat, opt), nil
+	}
+
+	return drawWithGG(mat, opt)
+}
+
+// drawWithGG draws each module by shapes into gg.Context, it supports all options.
+func drawWithGG(mat qrcode.Matrix, opt *outputImageOptions) (image.Image, error) {
 	top, right, bottom, left := opt.borderWidths[0], opt.borderWidths[1], opt.borderWidths[2], opt.borderWidths[3]
 	blockW := opt.qrBlockWidth()
 	w := mat.Width()*blockW + left + right
 	h := mat.Height()*blockW + top + bottom
 
+	// module width and borders have been resolved to fit the resolution.
 	if opt.resolution != nil && *opt.resolution > 0 {
-		res := *opt.resolution
-		availableW := res - left - right
-		availableH := res - top - bottom
-		if availableW > 0 && availableH > 0 && mat.Width() > 0 && mat.Height() > 0 {
-			blockW = availableW / mat.Width()
-			if blockH := availableH / mat.Height(); blockH < blockW {
-				blockW = blockH
-			}
-			if blockW < 1 {
-				blockW = 1
-			}
-			w = mat.Width()*blockW + left + right
-			h = mat.Height()*blockW + top + bottom
-		}
-		w, h = res, res
+		w, h = *opt.resolution, *opt.resolution
 	}
 
 	dc := gg.NewContext(w, h)
 
 	// draw background
-	dc.SetColor(opt.backgroundColor())
+	dc.SetColor(opt.quietZoneColor())
 	dc.DrawRectangle(0, 0, float64(w), float64(h))
 	dc.Fill()
 
@@ -160,58 +182,46 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 	}
 	shape := opt.getShape()
 
-	var (
-		halftoneImg image.Image
-		halftoneW   = float64(blockW) / 3.0
-	)
-	if opt.halftoneImg != nil {
-		halftoneImg = imgkit.Binaryzation(
-			imgkit.Scale(opt.halftoneImg, image.Rect(0, 0, mat.Width()*3, mat.Width()*3), nil),
-			60,
-			false,
-		)
-
-		// _ = imgkit.Save(halftoneImg, "mask.jpeg")
-	}
-
-	// Check if the logo image exists and fits within the QR code bounds.
-	// If so, mark it as valid and store its dimensions for safe zone calculation.
-	var logoValid bool
-	var logoWidth, logoHeight int
-	if opt.logoImage() != nil {
-		bound := opt.logo.Bounds()
-		upperLeft, lowerRight := bound.Min, bound.Max
-		logoWidth, logoHeight = lowerRight.X-upperLeft.X, lowerRight.Y-upperLeft.Y
+	// halftone is nil if there is no halftone image.
+	halftone := opt.prepareHalftone(mat, blockW)
 
-		logoValid = validLogoImage(w, h, logoWidth, logoHeight, opt.logoSizeMultiplier)
+	// logo is nil if there is no logo or the logo is too large.
+	logo, err := opt.layoutLogo(w, h, blockW, left, top, mat.Width())
+	if err != nil {
+		return nil, err
+	}
+	if logo != nil && opt.logoCheck {
+		if err := opt.checkLogo(mat, logo, blockW, left, top); err != nil {
+			return nil, err
+		}
 	}
 
 	// bitMap stores which blocks are set (true = active block)
 	bitMap := mat.Bitmap()
 	// If the logo safe zone is enabled, clear the corresponding area in bitMap
-	if logoValid && opt.logoSafeZone {
-		mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
-			if blockOverlapsLogo(x, y, blockW, left, top, w, h, logoWidth, logoHeight) {
-				bitMap[x][y] = false
-			}
-		})
-	}
+	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
+		if logo.hides(x, y, blockW, left, top) {
+			bitMap[y][x] = false
+		}
+	})
 
 	// iterate the matrix to Draw each pixel
 	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, v qrcode.QRValue) {
+		// finder patterns would be drawn as a whole by finderShape.
+		if opt.finderShape != nil && v.Type() == qrcode.QRType_FINDER {
+			return
+		}
+
 		// Skip drawing this block if it overlaps with the logo area.
 		// This preserves logo visibility by preventing block rendering underneath it.
-		if logoValid && opt.logoSafeZone &&
-			blockOverlapsLogo(x, y, blockW, left, top, w, h, logoWidth, logoHeight) {
-			if v.IsSet() {
-				return
-			}
+		if logo.hides(x, y, blockW, left, top) && v.IsSet() {
+			return
 		}
 
 		// Draw the block
 		ctx.x, ctx.y = float64(x*blockW+left), float64(y*blockW+top)
 		ctx.w, ctx.h = blockW, blockW
-		ctx.color = opt.translateToRGBA(v)
+		ctx.color = opt.moduleColor(x, y, v)
 		ctx.neighbours = getNeighbours(bitMap, x, y)
 
 		// DONE(@yeqown): make this abstract to Shapes
@@ -219,25 +229,18 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 		case qrcode.QRType_FINDER:
 			shape.DrawFinder(ctx)
 		case qrcode.QRType_DATA:
-			if halftoneImg == nil {
+			if !halftone.applies(x, y) {
 				shape.Draw(ctx)
 				return
 			}
 
-			ctx2 := &DrawContext{
-				GraphicsContext: ctx.GraphicsContext,
-				w:               int(halftoneW),
-				h:               int(halftoneW),
-			}
-			// only halftone image enabled and current block is Data.
-			for i := 0; i < 3; i++ {
-				for j := 0; j < 3; j++ {
-					ctx2.x, ctx2.y = ctx.x+float64(i)*halftoneW, ctx.y+float64(j)*halftoneW
-					if i == 1 && j == 1 {
-						ctx2.color = ctx.color
-					} else {
-						ctx2.color = halftoneColor(halftoneImg, opt.bgTransparent, x*3+i, y*3+j)
-					}
+			ctx2 := &DrawContext{GraphicsContext: ctx.GraphicsContext}
+			for i := 0; i < halftone.grid; i++ {
+				for j := 0; j < halftone.grid; j++ {
+					cell := halftone.cell(int(ctx.x), int(ctx.y), blockW, i, j)
+					ctx2.x, ctx2.y = float64(cell.Min.X), float64(cell.Min.Y)
+					ctx2.w, ctx2.h = cell.Dx(), cell.Dy()
+					ctx2.color = halftone.color(x, y, i, j, ctx.color, v.IsSet())
 					shape.Draw(ctx2)
 				}
 			}
@@ -248,29 +251,30 @@ func draw(mat qrcode.Matrix, opt *outputImageOptions) image.Image {
 		// EOFn
 	})
 
+	if opt.finderShape != nil {
+		for _, fctx := range finderDrawContexts(mat, blockW, left, top, opt) {
+			fctx.GraphicsContext = ctx.GraphicsContext
+			opt.finderShape.DrawFinderPattern(fctx)
+		}
+	}
+
 	// Gradient fill
 	if opt.qrGradient != nil {
 		img := opt.qrGradient.applyGradient(dc.Image(), opt.qrColor)
 		dc.DrawImage(img, 0, 0)
 	}
 
-	if opt.logoImage() == nil {
-		goto done
+	if logo != nil {
+		if err = opt.drawLogo(dc, logo); err != nil {
+			return nil, err
+		}
 	}
 
-	// Log a warning and skip drawing the logo if it exceeds the allowed size ratio.
-	if !logoValid {
-		log.Printf("w=%d, h=%d, logoW=%d, logoH=%d, logo is over than 1/%d of QRCode \n",
-			w, h, logoWidth, logoHeight, opt.logoSizeMultiplier)
-		goto done
+	if opt.frame != nil {
+		return opt.frame.drawAround(dc.Image(), blockW, opt), nil
 	}
 
-	// DONE(@yeqown): calculate the xOffset and yOffset which point(xOffset, yOffset)
-	// should icon upper-left to start
-	dc.DrawImage(opt.logoImage(), (w-logoWidth)/2, (h-logoHeight)/2)
-
-done:
-	return dc.Image()
+	return dc.Image(), nil
 }
 
 // getNeighbours returns a bitmask (uint16) representing the 8 neighboring cells
@@ -316,46 +320,10 @@ func getNeighbours(mtx [][]bool, x, y int) uint16 {
 	return res
 }
 
-// halftoneImage is an image.Gray type image, which At(x, y) return color.Gray.
-// black equals to color.Gray{0}, white equals to color.Gray{255}.
-func halftoneColor(halftoneImage image.Image, transparent bool, x, y int) color.Color {
-
-	c0 := halftoneImage.At(x, y)
-	c1, ok := halftoneImage.At(x, y).(color.Gray)
-	if !ok {
-		log.Printf("halftoneColor: not a gray image, got: %T\n", c0)
-		return c0
-	}
-
-	if c1.Y == 255 {
-		if transparent {
-			return color.RGBA{}
-		}
-		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	}
-
-	return color.RGBA{R: 0, G: 0, B: 0, A: 255}
-}
-
 func validLogoImage(qrWidth, qrHeight, logoWidth, logoHeight, logoSizeMultiplier int) bool {
 	return qrWidth >= logoSizeMultiplier*logoWidth && qrHeight >= logoSizeMultiplier*logoHeight
 }
 
-func blockOverlapsLogo(x, y, blockSize, left, top, w, h, logoWidth, logoHeight int) bool {
-	blockLeft := x*blockSize + left
-	blockTop := y*blockSize + top
-	blockRight := blockLeft + blockSize
-	blockBottom := blockTop + blockSize
-
-	logoLeft := (w - logoWidth) / 2
-	logoTop := (h - logoHeight) / 2
-	logoRight := logoLeft + logoWidth
-	logoBottom := logoTop + logoHeight
-
-	return blockRight > logoLeft && blockLeft < logoRight &&
-		blockBottom > logoTop && blockTop < logoBottom
-}
-
 // Attribute contains basic information of generated image.
 type Attribute struct {
 	// width and height of image
@@ -364,4 +332,14 @@ type Attribute struct {
 	Borders [4]int
 	// the length of  block edges
 	BlockWidth int
+	// the widths of the frame out of borders, in the order of "top, right, bottom, left".
+	FrameWidths [4]int
+
+	// DPI is set by WithDPI or WithPhysicalSize, 0 means unknown, and then the
+	// following physical sizes are 0 too.
+	DPI int
+	// WidthMM, HeightMM are the physical size of image in millimetre.
+	WidthMM, HeightMM float64
+	// ModuleMM is the physical edge length of one module in millimetre.
+	ModuleMM float64
 }
@@ -1,11 +1,15 @@
 package standard
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"image/color"
+	"image/jpeg"
 	"image/png"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/yeqown/go-qrcode/v2"
@@ -55,11 +59,17 @@ func Test_New_WithOutputOption_Logo(t *testing.T) {
 	qrc, err := qrcode.New("Test_New_WithOutputOption_Logo")
 	require.NoError(t, err)
 
+	logo := filepath.Join(t.TempDir(), "logo.jpeg")
+	fd, err := os.Create(logo)
+	require.NoError(t, err)
+	require.NoError(t, jpeg.Encode(fd, solidImage(60, 60, color.RGBA{R: 0x33, A: 0xff}), nil))
+	require.NoError(t, fd.Close())
+
 	w, err := New("./testdata/qrtest_logo.jpeg",
 		WithBgColorRGBHex("#b8de6f"),
 		WithFgColorRGBHex("#f1e189"),
-		WithLogoImageFileJPEG("./testdata/logo.jpeg"),
-		WithLogoImageAdaptiveFileJPEG("./testdata/logo.jpeg", 5, 20, qrc.Dimension()),
+		WithLogoImageFileJPEG(logo),
+		WithLogoImageAdaptiveFileJPEG(logo, 5, 20, qrc.Dimension()),
 		//WithLogoImageFilePNG("./testdata/logo.png"), // png required
 	)
 	require.NoError(t, err)
@@ -118,6 +128,13 @@ func Test_Issue40(t *testing.T) {
 	assert.Equal(t, h1, h2)
 }
 
+// nopCloser wraps io.Writer into io.WriteCloser for testing.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
 func hashFile(filename string) (string, error) {
 	h := md5.New()
 
@@ -206,3 +223,40 @@ func Test_writer_WithBgTransparent(t *testing.T) {
 	err = qrc.Save(w)
 	assert.NoError(t, err)
 }
+
+func Test_writer_ReflectanceReversal_Mirror(t *testing.T) {
+	normal := recordMatrix(t, "Test_writer_ReflectanceReversal")
+	reversed := recordMatrix(t, "Test_writer_ReflectanceReversal", qrcode.WithReflectanceReversal())
+	mirrored := recordMatrix(t, "Test_writer_ReflectanceReversal", qrcode.WithMirror())
+	blockW := 4
+
+	for _, shape := range []ImageOption{WithQRWidth(uint8(blockW)), WithCircleShape()} {
+		r := NewRenderer(WithQRWidth(uint8(blockW)), WithBorderWidth(8), shape)
+		img, err := r.Render(reversed)
+		require.NoError(t, err)
+
+		// the quiet zone is dark, the center of finder pattern is light.
+		assert.Equal(t, color.Gray{Y: 0}, color.GrayModel.Convert(img.At(1, 1)))
+		center := 8 + 3*blockW + blockW/2
+		assert.Equal(t, color.Gray{Y: 0xff}, color.GrayModel.Convert(img.At(center, center)))
+	}
+
+	r := NewRenderer(WithQRWidth(uint8(blockW)), WithBorderWidth(8))
+	want, err := r.Render(normal)
+	require.NoError(t, err)
+	got, err := r.Render(mirrored)
+	require.NoError(t, err)
+	w := want.Bounds().Dx()
+	for y := 0; y < want.Bounds().Dy(); y++ {
+		for x := 0; x < w; x++ {
+			if want.At(x, y) != got.At(w-1-x, y) {
+				t.Fatalf("pixel (%d, %d) is not mirrored", x, y)
+			}
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, NewRenderer(WithBuiltinImageEncoder(SVG_FORMAT)).Encode(buf, reversed))
+	assert.Contains(t, buf.String(), `fill="#000000"/>`)
+	assert.Contains(t, buf.String(), `#ffffff`)
+}
@@ -5,12 +5,16 @@ go 1.19
 require (
 	github.com/mattn/go-runewidth v0.0.16
 	github.com/nsf/termbox-go v1.1.1
+	github.com/stretchr/testify v1.7.0
 	github.com/yeqown/go-qrcode/v2 v2.2.5
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/yeqown/reedsolomon v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
 
 //replace github.com/yeqown/go-qrcode/v2 => ../../
@@ -0,0 +1,310 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Protocol is how the QR code is printed into terminal.
+type Protocol uint8
+
+const (
+	// ProtocolAuto detects the protocol from environment variables, see DetectProtocol.
+	ProtocolAuto Protocol = iota
+	// ProtocolCharacters prints the QR code by characters and ANSI colors.
+	ProtocolCharacters
+	// ProtocolSixel prints pixels by Sixel graphics (xterm -ti vt340, mlterm, foot, etc.).
+	ProtocolSixel
+	// ProtocolKitty prints pixels by Kitty graphics protocol.
+	ProtocolKitty
+	// ProtocolITerm2 prints pixels by iTerm2 inline images protocol, it's supported by
+	// WezTerm too.
+	ProtocolITerm2
+)
+
+const _defaultModulePixels = 4
+
+// kittyChunkSize is the max size of base64 payload in each escape code.
+const kittyChunkSize = 4096
+
+// DetectProtocol picks the graphics protocol supported by the terminal from environment
+// variables (KITTY_WINDOW_ID, TERM_PROGRAM and TERM), ProtocolCharacters is returned if
+// none is known.
+func DetectProtocol() Protocol {
+	term := strings.ToLower(os.Getenv("TERM"))
+	termProgram := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || strings.Contains(term, "kitty") || termProgram == "ghostty":
+		return ProtocolKitty
+	case termProgram == "iTerm.app" || termProgram == "WezTerm":
+		return ProtocolITerm2
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "mlterm") ||
+		strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "yaft"):
+		return ProtocolSixel
+	}
+
+	return ProtocolCharacters
+}
+
+// graphicsProtocol returns the protocol to print, the detected one if it's ProtocolAuto.
+func (o *outputOptions) graphicsProtocol() Protocol {
+	if o.protocol == ProtocolAuto {
+		return DetectProtocol()
+	}
+
+	return o.protocol
+}
+
+// matrixImage draws the rows of modules (with quiet zone) into a paletted image, each
+// module is modulePixels×modulePixels.
+func (w Writer) matrixImage(rows [][]bool) *image.Paletted {
+	dark, light := w.option.colors()
+	n := w.option.modulePixels
+
+	img := image.NewPaletted(image.Rect(0, 0, len(rows[0])*n, len(rows)*n), color.Palette{light, dark})
+	for y, row := range rows {
+		for x, isDark := range row {
+			if !isDark {
+				continue
+			}
+			for dy := 0; dy < n; dy++ {
+				offset := img.PixOffset(x*n, y*n+dy)
+				for dx := 0; dx < n; dx++ {
+					img.Pix[offset+dx] = 1
+				}
+			}
+		}
+	}
+
+	return img
+}
+
+// WriteImage prints img by the graphics protocol, such as a QR code rendered by the
+// standard writer with styles. Without graphics protocol, img is printed by half block
+// characters in true color, scaled down to at most 80 columns.
+func (w Writer) WriteImage(img image.Image) error {
+	bw := bufio.NewWriter(w.out)
+
+	var err error
+	switch w.option.graphicsProtocol() {
+	case ProtocolSixel:
+		err = writeSixel(bw, img)
+	case ProtocolKitty:
+		err = writeKitty(bw, img)
+	case ProtocolITerm2:
+		err = writeITerm2(bw, img)
+	default:
+		err = writeHalfBlockImage(bw, img, 80)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// writeKitty writes img as PNG by Kitty graphics protocol, the payload is split into
+// chunks.
+func writeKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	for first := true; first || payload != ""; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		control := "m=" + strconv.Itoa(more)
+		if first {
+			control = "a=T,f=100," + control
+		}
+		if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", control, chunk); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeITerm2 writes img as PNG by iTerm2 inline images protocol.
+func writeITerm2(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	b := img.Bounds()
+	_, err := fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1:%s\a\n",
+		buf.Len(), b.Dx(), b.Dy(), base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return err
+}
+
+// writeSixel writes img by Sixel graphics, img is quantized if it has more than 256
+// colors. Transparent pixels are left as the background of terminal.
+func writeSixel(w io.Writer, img image.Image) error {
+	p := toPaletted(img)
+	b := p.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	// P2=1: pixels of zero are left unchanged.
+	if _, err := fmt.Fprintf(w, "\x1bP0;1;0q\"1;1;%d;%d", width, height); err != nil {
+		return err
+	}
+	opaque := make([]bool, len(p.Palette))
+	for i, c := range p.Palette {
+		r, g, bb, a := c.RGBA()
+		if a < 0x8000 {
+			continue
+		}
+		opaque[i] = true
+		// colors are in percent, un-premultiplied.
+		if _, err := fmt.Fprintf(w, "#%d;2;%d;%d;%d", i, r*100/a, g*100/a, bb*100/a); err != nil {
+			return err
+		}
+	}
+
+	var line strings.Builder
+	sixels := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		for i := range p.Palette {
+			if !opaque[i] {
+				continue
+			}
+
+			used := false
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if p.Pix[p.PixOffset(b.Min.X+x, b.Min.Y+band+dy)] == uint8(i) {
+						bits |= 1 << dy
+					}
+				}
+				sixels[x] = '?' + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+
+			line.Reset()
+			line.WriteString("#" + strconv.Itoa(i))
+			writeSixelRuns(&line, sixels)
+			line.WriteByte('$') // back to the start of the band for the next color
+			if _, err := io.WriteString(w, line.String()); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "-"); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "\x1b\\\n")
+	return err
+}
+
+// writeSixelRuns writes sixels with run-length encoding (!<n><sixel>).
+func writeSixelRuns(sb *strings.Builder, sixels []byte) {
+	for x := 0; x < len(sixels); {
+		run := 1
+		for x+run < len(sixels) && sixels[x+run] == sixels[x] {
+			run++
+		}
+		if run > 3 {
+			sb.WriteString("!" + strconv.Itoa(run))
+			sb.WriteByte(sixels[x])
+		} else {
+			for i := 0; i < run; i++ {
+				sb.WriteByte(sixels[x])
+			}
+		}
+		x += run
+	}
+}
+
+// toPaletted returns img in palette, the colors of img are kept if there are at most
+// 256 colors, otherwise it's quantized by Floyd-Steinberg dithering into web-safe
+// palette.
+func toPaletted(img image.Image) *image.Paletted {
+	if p, ok := img.(*image.Paletted); ok {
+		return p
+	}
+
+	b := img.Bounds()
+	p := image.NewPaletted(b, nil)
+	index := make(map[color.RGBA]uint8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i, ok := index[c]
+			if !ok {
+				if len(p.Palette) == 256 {
+					webSafe := append(color.Palette{color.RGBA{}}, palette.WebSafe...)
+					quantized := image.NewPaletted(b, webSafe)
+					draw.FloydSteinberg.Draw(quantized, b, img, b.Min)
+					return quantized
+				}
+				i = uint8(len(p.Palette))
+				index[c] = i
+				p.Palette = append(p.Palette, c)
+			}
+			p.Pix[p.PixOffset(x, y)] = i
+		}
+	}
+
+	return p
+}
+
+// writeHalfBlockImage prints img by half block characters in true color, the image
+// is scaled down (nearest neighbour) to fit maxColumns.
+func writeHalfBlockImage(w io.Writer, img image.Image, maxColumns int) error {
+	b := img.Bounds()
+	scale := (b.Dx() + maxColumns - 1) / maxColumns
+	if scale < 1 {
+		scale = 1
+	}
+
+	at := func(x, y int) color.RGBA {
+		if y >= b.Dy() {
+			return color.RGBA{}
+		}
+		return color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+	}
+
+	for y := 0; y < b.Dy(); y += 2 * scale {
+		for x := 0; x < b.Dx(); x += scale {
+			top, bottom := at(x, y), at(x, y+scale)
+			if _, err := io.WriteString(w, "\x1b["+sgr(top, ColorModeTrueColor, false)+";"+
+				sgr(bottom, ColorModeTrueColor, true)+"m▀"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "\x1b[0m\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
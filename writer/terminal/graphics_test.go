@@ -0,0 +1,330 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearTerminalEnv unsets environment variables used by DetectProtocol.
+func clearTerminalEnv(t *testing.T) {
+	for _, key := range []string{"KITTY_WINDOW_ID", "TERM_PROGRAM", "TERM"} {
+		t.Setenv(key, "")
+	}
+}
+
+func Test_DetectProtocol(t *testing.T) {
+	cases := []struct {
+		key, value string
+		want       Protocol
+	}{
+		{"KITTY_WINDOW_ID", "1", ProtocolKitty},
+		{"TERM", "xterm-kitty", ProtocolKitty},
+		{"TERM_PROGRAM", "ghostty", ProtocolKitty},
+		{"TERM_PROGRAM", "iTerm.app", ProtocolITerm2},
+		{"TERM_PROGRAM", "WezTerm", ProtocolITerm2},
+		{"TERM", "foot", ProtocolSixel},
+		{"TERM", "mlterm", ProtocolSixel},
+		{"TERM", "xterm-256color", ProtocolCharacters},
+	}
+	for _, c := range cases {
+		t.Run(c.key+"="+c.value, func(t *testing.T) {
+			clearTerminalEnv(t)
+			t.Setenv(c.key, c.value)
+			assert.Equal(t, c.want, DetectProtocol())
+		})
+	}
+}
+
+func Test_NewWithWriter_Characters(t *testing.T) {
+	clearTerminalEnv(t)
+	t.Setenv("KITTY_WINDOW_ID", "1")
+
+	qrc, err := qrcode.New("no graphics")
+	require.NoError(t, err)
+
+	// output into a writer doesn't depend on the terminal.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(NewWithWriter(&buf)))
+	assert.NotContains(t, buf.String(), "\x1b_G")
+	assert.Contains(t, buf.String(), "\x1b[48;5;")
+
+	// stdout is detected.
+	assert.Equal(t, ProtocolKitty, New().option.graphicsProtocol())
+	assert.Equal(t, ProtocolCharacters, New(WithProtocol(ProtocolCharacters)).option.graphicsProtocol())
+}
+
+func Test_Writer_Kitty(t *testing.T) {
+	qrc, err := qrcode.New("kitty")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// modules of the mirrored matrix are 3×3 pixels, the quiet zone is 1 module.
+	var buf bytes.Buffer
+	w := NewWithWriter(&buf, WithProtocol(ProtocolKitty), WithModulePixels(3), WithQuietZone(1))
+	require.NoError(t, w.Write(*mat.Mirror()))
+
+	var payload strings.Builder
+	for _, chunk := range regexp.MustCompile("\x1b_G[^;]*;([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(buf.String(), -1) {
+		payload.WriteString(chunk[1])
+	}
+	img := decodePNG(t, payload.String())
+	n := mat.Width() + 2
+	require.Equal(t, image.Rect(0, 0, n*3, n*3), img.Bounds())
+	bm := mat.Bitmap()
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			dark := y > 0 && y < n-1 && x > 0 && x < n-1 && bm[y-1][n-2-x]
+			want := color.RGBAModel.Convert(White)
+			if dark {
+				want = color.RGBAModel.Convert(Black)
+			}
+			assert.Equal(t, want, color.RGBAModel.Convert(img.At(x*3+1, y*3+1)), "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_WriteImage_HalfBlock(t *testing.T) {
+	// the image of 200 columns is scaled down by 3 to fit 80 columns, each line is 6
+	// rows of pixels, the lower half of last line is beyond the image.
+	img := noise(200, 8)
+	var buf bytes.Buffer
+	require.NoError(t, NewWithWriter(&buf).WriteImage(img))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\x1b[0m\n"), "\x1b[0m\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, 67, strings.Count(line, "▀"))
+	}
+	first := color.RGBAModel.Convert(img.At(0, 0)).(color.RGBA)
+	assert.True(t, strings.HasPrefix(lines[0], "\x1b["+sgr(first, ColorModeTrueColor, false)+";"))
+	assert.True(t, strings.HasSuffix(lines[1], sgr(color.RGBA{}, ColorModeTrueColor, true)+"m▀"))
+}
+
+// noise returns an image of random colors, whose PNG is large.
+func noise(w, h int) *image.RGBA {
+	r := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	return img
+}
+
+func decodePNG(t *testing.T, payload string) image.Image {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	return img
+}
+
+func assertSameImage(t *testing.T, want, got image.Image) {
+	require.Equal(t, want.Bounds(), got.Bounds())
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			require.Equal(t, color.RGBAModel.Convert(want.At(x, y)), color.RGBAModel.Convert(got.At(x, y)),
+				"pixel (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_writeKitty(t *testing.T) {
+	img := noise(64, 64)
+	var buf bytes.Buffer
+	require.NoError(t, writeKitty(&buf, img))
+
+	chunks := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(buf.String(), -1)
+	require.Greater(t, len(chunks), 2)
+
+	var payload strings.Builder
+	for i, chunk := range chunks {
+		control := "m=1"
+		if i == 0 {
+			control = "a=T,f=100,m=1"
+		}
+		if i == len(chunks)-1 {
+			control = "m=0"
+		}
+		assert.Equal(t, control, chunk[1])
+		assert.LessOrEqual(t, len(chunk[2]), kittyChunkSize)
+		payload.WriteString(chunk[2])
+	}
+	assertSameImage(t, img, decodePNG(t, payload.String()))
+	assert.True(t, strings.HasSuffix(buf.String(), "\x1b\\\n"))
+}
+
+func Test_writeITerm2(t *testing.T) {
+	img := noise(10, 6)
+	var buf bytes.Buffer
+	require.NoError(t, writeITerm2(&buf, img))
+
+	m := regexp.MustCompile("^\x1b]1337;File=inline=1;size=(\\d+);width=10px;height=6px;preserveAspectRatio=1:([^\a]*)\a\n$").
+		FindStringSubmatch(buf.String())
+	require.NotNil(t, m)
+	data, err := base64.StdEncoding.DecodeString(m[2])
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(len(data)), m[1])
+	assertSameImage(t, img, decodePNG(t, m[2]))
+}
+
+// decodeSixel decodes the output of writeSixel into palette indexes of pixels, -1 is
+// a pixel left unchanged.
+func decodeSixel(t *testing.T, out string) ([][]int, map[int][3]int) {
+	require.True(t, strings.HasPrefix(out, "\x1bP0;1;0q\""))
+	require.True(t, strings.HasSuffix(out, "\x1b\\\n"))
+	out = strings.TrimSuffix(strings.TrimPrefix(out, "\x1bP0;1;0q\""), "\x1b\\\n")
+
+	number := func() int {
+		i := 0
+		for i < len(out) && out[i] >= '0' && out[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(out[:i])
+		require.NoError(t, err)
+		out = out[i:]
+		return n
+	}
+	skip := func(c byte) {
+		require.Equal(t, c, out[0])
+		out = out[1:]
+	}
+
+	// raster attributes: 1;1;width;height
+	number()
+	skip(';')
+	number()
+	skip(';')
+	width := number()
+	skip(';')
+	height := number()
+
+	pixels := make([][]int, height)
+	for y := range pixels {
+		pixels[y] = make([]int, width)
+		for x := range pixels[y] {
+			pixels[y][x] = -1
+		}
+	}
+	palette := make(map[int][3]int)
+	set := func(x, band, current int, sixel byte) {
+		bits := sixel - '?'
+		for dy := 0; dy < 6; dy++ {
+			if bits&(1<<dy) != 0 {
+				require.Less(t, band+dy, height)
+				pixels[band+dy][x] = current
+			}
+		}
+	}
+
+	x, band, current := 0, 0, -1
+	for len(out) > 0 {
+		switch c := out[0]; {
+		case c == '#':
+			out = out[1:]
+			i := number()
+			if out != "" && out[0] == ';' {
+				var rgb [3]int
+				skip(';')
+				require.Equal(t, 2, number())
+				for j := range rgb {
+					skip(';')
+					rgb[j] = number()
+				}
+				palette[i] = rgb
+				continue
+			}
+			current = i
+		case c == '!':
+			out = out[1:]
+			n := number()
+			for j := 0; j < n; j++ {
+				set(x, band, current, out[0])
+				x++
+			}
+			out = out[1:]
+		case c == '$':
+			x = 0
+			out = out[1:]
+		case c == '-':
+			x, band = 0, band+6
+			out = out[1:]
+		case c >= '?' && c <= '~':
+			set(x, band, current, c)
+			x++
+			out = out[1:]
+		default:
+			t.Fatalf("unexpected sixel %q", c)
+		}
+	}
+
+	return pixels, palette
+}
+
+func Test_writeSixel(t *testing.T) {
+	// 13 rows are 3 bands, the last one is partial. Transparent pixels are left.
+	colors := []color.RGBA{{}, {R: 255, A: 255}, {G: 255, B: 255, A: 255}}
+	img := image.NewRGBA(image.Rect(0, 0, 9, 13))
+	for y := 0; y < 13; y++ {
+		for x := 0; x < 9; x++ {
+			img.SetRGBA(x, y, colors[(x/2+y)%3])
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSixel(&buf, img))
+	pixels, palette := decodeSixel(t, buf.String())
+
+	p := toPaletted(img)
+	require.Len(t, pixels, 13)
+	for y := range pixels {
+		for x, i := range pixels[y] {
+			c := colors[(x/2+y)%3]
+			if c.A == 0 {
+				assert.Equal(t, -1, i, "pixel (%d, %d)", x, y)
+				continue
+			}
+			require.Equal(t, int(p.ColorIndexAt(x, y)), i, "pixel (%d, %d)", x, y)
+			assert.Equal(t, [3]int{int(c.R) * 100 / 255, int(c.G) * 100 / 255, int(c.B) * 100 / 255}, palette[i])
+		}
+	}
+	// runs of more than 3 sixels are encoded.
+	assert.Contains(t, buf.String(), "!")
+}
+
+func Test_toPaletted(t *testing.T) {
+	colors := []color.RGBA{{R: 1, A: 255}, {G: 2, A: 255}, {B: 3, A: 255}}
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	for x, c := range colors {
+		img.SetRGBA(x, 0, c)
+	}
+	p := toPaletted(img)
+	require.Len(t, p.Palette, 3)
+	for x, c := range colors {
+		assert.Equal(t, c, p.Palette[p.ColorIndexAt(x, 0)])
+	}
+
+	// paletted image is kept.
+	assert.Same(t, p, toPaletted(p))
+
+	// more than 256 colors are quantized into transparent and web-safe colors.
+	p = toPaletted(noise(32, 32))
+	assert.Len(t, p.Palette, 217)
+	assert.Equal(t, color.RGBA{}, p.Palette[0])
+}
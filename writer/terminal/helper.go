@@ -1,36 +1,103 @@
 package terminal
 
-//
-//import (
-//	"image/color"
-//	"os"
-//	"syscall"
-//	"unsafe"
-//)
-//
-//const defaultRatio float64 = 7.0 / 3.0 // The terminal's default cursor width/height ratio
-//
-//func terminalSize() (int, int, float64) {
-//	var size [4]uint16
-//	if _, _, err := syscall.Syscall6(syscall.SYS_IOCTL,
-//		uintptr(os.Stdout.Fd()), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)),
-//		0, 0, 0); err != 0 {
-//		panic(err)
-//	}
-//	rows, cols, width, height := size[0], size[1], size[2], size[3]
-//
-//	whratio := defaultRatio
-//	if width > 0 && height > 0 {
-//		whratio = float64(height/rows) / float64(width/cols)
-//	}
-//
-//	return int(cols), int(rows), whratio
-//}
-//
-//func terminalColor(rgb color.RGBA) uint16 {
-//	r := (((rgb.R * 5) + 127) / 255) * 36
-//	g := (((rgb.G * 5) + 127) / 255) * 6
-//	b := ((rgb.B * 5) + 127) / 255
-//
-//	return uint16(r+g+b) + 16 + 1 // termbox default color offset
-//}
+import (
+	"image/color"
+	"strconv"
+)
+
+// basicColors are the 16 basic colors in xterm defaults.
+var basicColors = [16]color.RGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// cubeLevels are the levels of each channel in the 6×6×6 color cube of xterm.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+func distance(a, b color.RGBA) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+// basicColor returns the index of the nearest basic color.
+func basicColor(c color.RGBA) int {
+	best := 0
+	for i, bc := range basicColors {
+		if distance(c, bc) < distance(c, basicColors[best]) {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// xterm256Color returns the index of the nearest color in the 256-color palette, from
+// the color cube (16-231) or the gray ramp (232-255).
+func xterm256Color(c color.RGBA) int {
+	nearestLevel := func(v uint8) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if absDiff(v, l) < absDiff(v, cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	r, g, b := nearestLevel(c.R), nearestLevel(c.G), nearestLevel(c.B)
+	cube := color.RGBA{R: cubeLevels[r], G: cubeLevels[g], B: cubeLevels[b]}
+
+	// gray ramp is 8, 18, ..., 238.
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	k := (avg - 8 + 5) / 10
+	if k < 0 {
+		k = 0
+	} else if k > 23 {
+		k = 23
+	}
+	level := uint8(8 + 10*k)
+	gray := color.RGBA{R: level, G: level, B: level}
+
+	if distance(c, gray) < distance(c, cube) {
+		return 232 + k
+	}
+
+	return 16 + 36*r + 6*g + b
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+
+	return int(b - a)
+}
+
+// sgr returns the SGR parameters setting c as foreground (or background) color.
+func sgr(c color.RGBA, mode ColorMode, background bool) string {
+	switch mode {
+	case ColorMode16:
+		i, base := basicColor(c), 30
+		if background {
+			base = 40
+		}
+		if i >= 8 {
+			return strconv.Itoa(base + 60 + i - 8)
+		}
+		return strconv.Itoa(base + i)
+	case ColorModeTrueColor:
+		prefix := "38;2;"
+		if background {
+			prefix = "48;2;"
+		}
+		return prefix + strconv.Itoa(int(c.R)) + ";" + strconv.Itoa(int(c.G)) + ";" + strconv.Itoa(int(c.B))
+	default:
+		prefix := "38;5;"
+		if background {
+			prefix = "48;5;"
+		}
+		return prefix + strconv.Itoa(xterm256Color(c))
+	}
+}
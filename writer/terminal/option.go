@@ -1,41 +1,167 @@
 package terminal
 
-//
-//import (
-//	"fmt"
-//	"image/color"
-//)
-//
-//var (
-//	White = color.RGBA{R: 255, G: 255, B: 255, A: 255}
-//	Black = color.RGBA{A: 255}
-//)
-//
-//// hexToRGBA convert hex string into color.RGBA
-//func hexToRGBA(s string) color.RGBA {
-//	c := color.RGBA{
-//		R: 0,
-//		G: 0,
-//		B: 0,
-//		A: 0xff,
-//	}
-//
-//	var err error
-//	switch len(s) {
-//	case 7:
-//		_, err = fmt.Sscanf(s, "#%02x%02x%02x", &c.R, &c.G, &c.B)
-//	case 4:
-//		_, err = fmt.Sscanf(s, "#%1x%1x%1x", &c.R, &c.G, &c.B)
-//		// Double the hex digits:
-//		c.R *= 17
-//		c.G *= 17
-//		c.B *= 17
-//	default:
-//		err = fmt.Errorf("invalid length, must be 7 or 4")
-//	}
-//	if err != nil {
-//		panic(err)
-//	}
-//
-//	return c
-//}
+import (
+	"image/color"
+)
+
+// ColorMode decides how colors are written in ANSI escape sequences.
+type ColorMode uint8
+
+const (
+	// ColorMode256 writes colors in the 256-color palette of xterm, it's the default.
+	ColorMode256 ColorMode = iota
+	// ColorMode16 writes colors in the 16 basic colors, which are supported by almost
+	// all terminals.
+	ColorMode16
+	// ColorModeTrueColor writes colors in 24-bit RGB.
+	ColorModeTrueColor
+)
+
+var (
+	White = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	Black = color.RGBA{A: 255}
+)
+
+const _defaultQuietZone = 4
+
+// Option configures the terminal writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	// fg and bg are colors of dark and light modules.
+	fg, bg    color.RGBA
+	colorMode ColorMode
+	// quietZone is the width of quiet zone in modules.
+	quietZone int
+	invert    bool
+	halfBlock bool
+
+	// interactive takes over the screen by termbox, and waits for a key.
+	interactive bool
+
+	// protocol prints pixels by terminal graphics, modulePixels is the size of each
+	// module in pixels.
+	protocol     Protocol
+	modulePixels int
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		fg:           Black,
+		bg:           White,
+		colorMode:    ColorMode256,
+		quietZone:    _defaultQuietZone,
+		protocol:     ProtocolCharacters,
+		modulePixels: _defaultModulePixels,
+	}
+}
+
+// colors returns colors of dark and light modules, swapped if inverted.
+func (o *outputOptions) colors() (dark, light color.RGBA) {
+	if o.invert {
+		return o.bg, o.fg
+	}
+
+	return o.fg, o.bg
+}
+
+// WithColors sets colors of dark modules (fg) and light modules and the quiet zone (bg).
+func WithColors(fg, bg color.Color) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if fg != nil {
+			o.fg = color.RGBAModel.Convert(fg).(color.RGBA)
+		}
+		if bg != nil {
+			o.bg = color.RGBAModel.Convert(bg).(color.RGBA)
+		}
+	})
+}
+
+// WithColorMode sets how colors are written, ColorMode256 by default.
+func WithColorMode(mode ColorMode) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mode > ColorModeTrueColor {
+			return
+		}
+
+		o.colorMode = mode
+	})
+}
+
+// WithQuietZone sets the width of quiet zone in modules, 4 by default as ISO/IEC 18004
+// requires. Many scanners read QR codes with narrower quiet zone in terminals.
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithInvert swaps the colors of dark and light modules, which looks like the terminal
+// with dark theme is printing. Scanners must support reflectance reversal to read it.
+func WithInvert() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.invert = true
+	})
+}
+
+// WithHalfBlock draws two modules in one character by half blocks (▀), so that the QR
+// code is half as large (in height and width) as the default layout, which draws a
+// module by two spaces.
+func WithHalfBlock() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.halfBlock = true
+	})
+}
+
+// WithInteractive draws the QR code in full screen by termbox and waits for a key
+// before Write returns, the output writer is not used.
+func WithInteractive() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.interactive = true
+	})
+}
+
+// WithProtocol sets how the QR code is printed. ProtocolAuto detects graphics protocol
+// from environment variables and falls back to characters, it's the default of New.
+// NewWithWriter prints characters by default.
+func WithProtocol(p Protocol) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if p > ProtocolITerm2 {
+			return
+		}
+
+		o.protocol = p
+	})
+}
+
+// WithModulePixels sets the size of each module in pixels when it's printed by graphics
+// protocol, 4 by default.
+func WithModulePixels(n int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if n <= 0 {
+			return
+		}
+
+		o.modulePixels = n
+	})
+}
@@ -1,6 +1,12 @@
 package terminal
 
 import (
+	"bufio"
+	"errors"
+	"image/color"
+	"io"
+	"os"
+
 	"github.com/yeqown/go-qrcode/v2"
 
 	"github.com/mattn/go-runewidth"
@@ -9,13 +15,36 @@ import (
 
 var _ qrcode.Writer = (*Writer)(nil)
 
-// Writer implements qrcode.Writer based on termbox to print QRCode into
-// terminal / console.
-type Writer struct{}
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("terminal: empty matrix")
+
+// Writer implements qrcode.Writer to print QRCode into terminal / console. It writes
+// characters in ANSI escape sequences, so that it works in CI logs and SSH sessions
+// without TTY, or pixels by Sixel, Kitty or iTerm2 graphics (see WithProtocol).
+// WithInteractive takes over the screen by termbox.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a terminal writer which prints into os.Stdout, pixels are printed if the
+// terminal supports graphics (ProtocolAuto).
+func New(opts ...Option) *Writer {
+	return NewWithWriter(os.Stdout, append([]Option{WithProtocol(ProtocolAuto)}, opts...)...)
+}
+
+// NewWithWriter creates a terminal writer which prints into out, out is not closed.
+// Characters are printed unless WithProtocol is set, since out may not be a terminal.
+func NewWithWriter(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
 
-func New() *Writer {
-	w := &Writer{}
-	w.init()
+	w := &Writer{out: out, option: option}
+	if option.interactive {
+		w.init()
+	}
 
 	return w
 }
@@ -30,51 +59,95 @@ func (w Writer) init() {
 	termbox.SetOutputMode(termbox.Output256)
 }
 
-func (w Writer) preDraw(width, height, padding int, bg termbox.Attribute) {
-	for i := 0; i < width+2*padding; i++ {
-		for j := 0; j < height+2*padding; j++ {
-			w.drawBlock(i, j, 0, bg, bg)
+func (w Writer) Write(mat qrcode.Matrix) error {
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+	if w.option.interactive {
+		return w.writeInteractive(mat)
+	}
+	if w.option.graphicsProtocol() != ProtocolCharacters {
+		return w.WriteImage(w.matrixImage(mat.BitmapWithQuietZone(w.option.quietZone)))
+	}
+
+	dark, light := w.option.colors()
+	colorOf := func(isDark bool) color.RGBA {
+		if isDark {
+			return dark
 		}
+		return light
 	}
-}
 
-// drawBlock draws a block at (x, y) with fg and bg colors.
-// each block takes 2 times width of one character terminal, it looks like: ██
-func (w Writer) drawBlock(x, y, padding int, fg termbox.Attribute, bg termbox.Attribute) {
-	x1, y1 := x*2+2*padding, y+padding
-	x2, y2 := x1+1, y1
+	bw := bufio.NewWriter(w.out)
+	rows := mat.BitmapWithQuietZone(w.option.quietZone)
+	step := 1
+	if w.option.halfBlock {
+		step = 2
+	}
 
-	termbox.SetCell(x1, y1, '█', fg, bg)
-	termbox.SetCell(x2, y2, '█', fg, bg)
+	for y := 0; y < len(rows); y += step {
+		// SGR parameters are written only when colors change.
+		var lastFg, lastBg string
+		for x := range rows[y] {
+			bg := sgr(colorOf(rows[y][x]), w.option.colorMode, true)
+			cell, fg := "  ", lastFg
+			if w.option.halfBlock {
+				// the upper half is the module at y in foreground color, the lower
+				// half is the module at y+1 (quiet zone after the last row) in
+				// background color.
+				bottom := mat.IsReflectanceReversed()
+				if y+1 < len(rows) {
+					bottom = rows[y+1][x]
+				}
+				fg = sgr(colorOf(rows[y][x]), w.option.colorMode, false)
+				bg = sgr(colorOf(bottom), w.option.colorMode, true)
+				cell = "▀"
+			}
+
+			if fg != lastFg || bg != lastBg {
+				params := bg
+				if fg != lastFg {
+					params = fg + ";" + bg
+				}
+				_, _ = bw.WriteString("\x1b[" + params + "m")
+				lastFg, lastBg = fg, bg
+			}
+			_, _ = bw.WriteString(cell)
+		}
+		_, _ = bw.WriteString("\x1b[0m\n")
+	}
+
+	return bw.Flush()
 }
 
-func (w Writer) Write(mat qrcode.Matrix) error {
-	//width, height, whratio := terminalSize()
-	//_ = width
-	//_ = height
-	//_ = whratio
-
-	ww, hh := mat.Width(), mat.Height()
-	bg := termbox.ColorWhite
-	fg := termbox.ColorBlack
-
-	padding, curRow := 1, 0
-	w.preDraw(ww, hh, padding, bg)
-	mat.Iterate(qrcode.IterDirection_ROW, func(x int, y int, state qrcode.QRValue) {
-		if state.IsSet() {
-			fg = termbox.ColorBlack
-		} else {
-			fg = termbox.ColorWhite
+// writeInteractive draws the QR code in full screen and waits for a key.
+func (w Writer) writeInteractive(mat qrcode.Matrix) error {
+	dark, light := w.option.colors()
+	attr := func(isDark bool) termbox.Attribute {
+		if isDark {
+			return termbox.Attribute(xterm256Color(dark) + 1)
 		}
+		return termbox.Attribute(xterm256Color(light) + 1)
+	}
 
-		w.drawBlock(x, y, padding, fg, bg)
-		curRow = y
-	})
+	rows := mat.BitmapWithQuietZone(w.option.quietZone)
+	for y := range rows {
+		for x := range rows[y] {
+			w.drawBlock(x, y, attr(rows[y][x]))
+		}
+	}
 
-	printTip(curRow + 2*padding + 1 + 1)
+	printTip(len(rows) + 1)
 	return hold()
 }
 
+// drawBlock draws a block at (x, y) in color c.
+// each block takes 2 times width of one character terminal, it looks like: ██
+func (w Writer) drawBlock(x, y int, c termbox.Attribute) {
+	termbox.SetCell(x*2, y, '█', c, c)
+	termbox.SetCell(x*2+1, y, '█', c, c)
+}
+
 func printTip(y int) {
 	tip := "Press any key to quit."
 	x := 0
@@ -104,8 +177,11 @@ wait:
 	return nil
 }
 
+// Close closes termbox in interactive mode, the output writer is not closed.
 func (w Writer) Close() error {
-	termbox.Close()
+	if w.option.interactive {
+		termbox.Close()
+	}
 
 	return nil
 }
@@ -0,0 +1,233 @@
+package terminal
+
+import (
+	"bytes"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cell is a character cell with colors set by SGR.
+type cell struct {
+	text   string
+	fg, bg color.RGBA
+}
+
+// parseCells splits out into lines of cells, SGR parameters of true color are applied.
+func parseCells(t *testing.T, out string) [][]cell {
+	var (
+		lines  [][]cell
+		line   []cell
+		fg, bg color.RGBA
+	)
+	for len(out) > 0 {
+		switch {
+		case strings.HasPrefix(out, "\x1b["):
+			end := strings.IndexByte(out, 'm')
+			require.Greater(t, end, 0)
+			params := strings.Split(out[2:end], ";")
+			out = out[end+1:]
+			for len(params) > 0 {
+				switch params[0] {
+				case "0":
+					fg, bg = color.RGBA{}, color.RGBA{}
+					params = params[1:]
+				case "38", "48":
+					require.GreaterOrEqual(t, len(params), 5)
+					require.Equal(t, "2", params[1])
+					var c [3]uint8
+					for i := range c {
+						v, err := strconv.Atoi(params[2+i])
+						require.NoError(t, err)
+						c[i] = uint8(v)
+					}
+					if params[0] == "38" {
+						fg = color.RGBA{R: c[0], G: c[1], B: c[2], A: 255}
+					} else {
+						bg = color.RGBA{R: c[0], G: c[1], B: c[2], A: 255}
+					}
+					params = params[5:]
+				default:
+					t.Fatalf("unexpected SGR parameter %q", params[0])
+				}
+			}
+		case out[0] == '\n':
+			lines = append(lines, line)
+			line = nil
+			out = out[1:]
+		case strings.HasPrefix(out, "▀"):
+			line = append(line, cell{text: "▀", fg: fg, bg: bg})
+			out = out[len("▀"):]
+		case strings.HasPrefix(out, "  "):
+			line = append(line, cell{text: "  ", fg: fg, bg: bg})
+			out = out[2:]
+		default:
+			t.Fatalf("unexpected output %q", out[:1])
+		}
+	}
+
+	return lines
+}
+
+// expected returns the modules of mat with quiet zone of qz modules.
+func expected(mat qrcode.Matrix, qz int) [][]bool {
+	bm := mat.Bitmap()
+	rows := make([][]bool, len(bm)+2*qz)
+	for y := range rows {
+		rows[y] = make([]bool, len(bm)+2*qz)
+		for x := range rows[y] {
+			if y >= qz && y < qz+len(bm) && x >= qz && x < qz+len(bm) {
+				rows[y][x] = bm[y-qz][x-qz]
+			}
+		}
+	}
+
+	return rows
+}
+
+func Test_sgr(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	gray := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+
+	assert.Equal(t, "30", sgr(Black, ColorMode16, false))
+	assert.Equal(t, "107", sgr(White, ColorMode16, true))
+	assert.Equal(t, "91", sgr(red, ColorMode16, false))
+	assert.Equal(t, 9, basicColor(red))
+
+	assert.Equal(t, "38;5;16", sgr(Black, ColorMode256, false))
+	assert.Equal(t, "48;5;231", sgr(White, ColorMode256, true))
+	assert.Equal(t, "38;5;196", sgr(red, ColorMode256, false))
+	// grays are in the gray ramp: 8, 18, ..., 238.
+	assert.Equal(t, 244, xterm256Color(gray))
+
+	assert.Equal(t, "38;2;255;0;0", sgr(red, ColorModeTrueColor, false))
+	assert.Equal(t, "48;2;128;128;128", sgr(gray, ColorModeTrueColor, true))
+}
+
+func Test_Writer(t *testing.T) {
+	qrc, err := qrcode.New("terminal writer")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(NewWithWriter(&buf, WithColorMode(ColorModeTrueColor), WithQuietZone(2))))
+	lines := parseCells(t, buf.String())
+	want := expected(qrc.Matrix(), 2)
+	require.Len(t, lines, len(want))
+	for y, line := range lines {
+		require.Len(t, line, len(want[y]))
+		for x, c := range line {
+			assert.Equal(t, "  ", c.text)
+			assert.Equal(t, want[y][x], c.bg == Black, "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_ColorChanges(t *testing.T) {
+	qrc, err := qrcode.New("terminal writer")
+	require.NoError(t, err)
+
+	// colors are written only when they change, the top row of finder pattern is 7 dark
+	// modules and a light separator.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(NewWithWriter(&buf, WithColorMode(ColorMode256), WithQuietZone(0))))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "\x1b[48;5;16m"+strings.Repeat("  ", 7)+"\x1b[48;5;231m  "))
+	assert.True(t, strings.HasSuffix(out, "\x1b[0m\n"))
+	assert.Equal(t, qrc.Dimension(), strings.Count(out, "\x1b[0m\n"))
+}
+
+func Test_Writer_HalfBlock(t *testing.T) {
+	qrc, err := qrcode.New("half block")
+	require.NoError(t, err)
+
+	// 21 modules with 4 modules quiet zone are 29 rows, the last line has only the
+	// upper half, whose lower half is the quiet zone.
+	want := expected(qrc.Matrix(), 4)
+	require.Equal(t, 1, len(want)%2)
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(NewWithWriter(&buf, WithHalfBlock(), WithColorMode(ColorModeTrueColor))))
+	lines := parseCells(t, buf.String())
+	require.Len(t, lines, (len(want)+1)/2)
+	for i, line := range lines {
+		require.Len(t, line, len(want[0]))
+		for x, c := range line {
+			y := 2 * i
+			assert.Equal(t, "▀", c.text)
+			assert.Equal(t, want[y][x], c.fg == Black, "module (%d, %d)", x, y)
+			if y+1 < len(want) {
+				assert.Equal(t, want[y+1][x], c.bg == Black, "module (%d, %d)", x, y+1)
+			} else {
+				assert.Equal(t, White, c.bg)
+			}
+		}
+	}
+
+	// without quiet zone the last line is the last row of modules, its lower half is the
+	// dark quiet zone of reflectance reversed matrix.
+	reversed, err := qrcode.NewWith("half block", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, reversed.Save(NewWithWriter(&buf, WithHalfBlock(), WithQuietZone(0), WithColorMode(ColorModeTrueColor))))
+	lines = parseCells(t, buf.String())
+	require.Len(t, lines, 11)
+	for _, c := range lines[10] {
+		assert.Equal(t, Black, c.bg)
+	}
+}
+
+func Test_Writer_Invert(t *testing.T) {
+	qrc, err := qrcode.New("invert")
+	require.NoError(t, err)
+
+	// dark modules are white, light modules and the quiet zone are black.
+	want := expected(qrc.Matrix(), 1)
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(NewWithWriter(&buf, WithInvert(), WithQuietZone(1), WithColorMode(ColorModeTrueColor))))
+	lines := parseCells(t, buf.String())
+	require.Len(t, lines, len(want))
+	for y, line := range lines {
+		for x, c := range line {
+			assert.Equal(t, want[y][x], c.bg == White, "module (%d, %d)", x, y)
+		}
+	}
+	assert.Equal(t, Black, lines[0][0].bg)
+
+	// the quiet zone of reflectance reversed matrix is dark.
+	reversed, err := qrcode.NewWith("invert", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, reversed.Save(NewWithWriter(&buf, WithQuietZone(1), WithColorMode(ColorModeTrueColor))))
+	assert.Equal(t, Black, parseCells(t, buf.String())[0][0].bg)
+}
+
+func Test_Writer_Mirrored(t *testing.T) {
+	qrc, err := qrcode.New("mirrored")
+	require.NoError(t, err)
+
+	// lines of mirrored matrix are lines of the matrix read from right to left.
+	mat := qrc.Matrix()
+	var buf bytes.Buffer
+	require.NoError(t, NewWithWriter(&buf, WithColorMode(ColorModeTrueColor)).Write(*mat.Mirror()))
+	lines := parseCells(t, buf.String())
+	want := expected(mat, 4)
+	require.Len(t, lines, len(want))
+	for y, line := range lines {
+		for x, c := range line {
+			assert.Equal(t, want[y][len(want[y])-1-x], c.bg == Black, "module (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	for _, opt := range []Option{WithQuietZone(4), WithHalfBlock(), WithProtocol(ProtocolKitty)} {
+		var buf bytes.Buffer
+		assert.ErrorIs(t, NewWithWriter(&buf, opt).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+		assert.Zero(t, buf.Len())
+	}
+}
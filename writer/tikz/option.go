@@ -0,0 +1,139 @@
+package tikz
+
+import (
+	"image/color"
+)
+
+// Environment is the LaTeX environment of drawing.
+type Environment uint8
+
+const (
+	// EnvironmentTikZ writes a tikzpicture, it needs \usepackage{tikz}. It's the default.
+	EnvironmentTikZ Environment = iota
+	// EnvironmentPicture writes the picture environment of LaTeX kernel by \rule, no
+	// package is needed unless colors are set (\usepackage{color}).
+	EnvironmentPicture
+)
+
+// black is the default color of dark modules, which needs no color definition.
+var black = color.RGBA{A: 255}
+
+const (
+	_defaultModuleSize = 0.5
+	_defaultUnit       = "mm"
+	_defaultQuietZone  = 4
+)
+
+// texUnits are units of TeX dimensions.
+var texUnits = map[string]bool{
+	"pt": true, "mm": true, "cm": true, "in": true, "bp": true, "pc": true,
+	"dd": true, "cc": true, "sp": true, "em": true, "ex": true,
+}
+
+// Option configures the TikZ writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	environment Environment
+	// moduleSize in unit is the size of each module.
+	moduleSize float64
+	unit       string
+	// quietZone is the width of quiet zone in modules.
+	quietZone int
+
+	// fg is the color of dark modules, bg is the color of light modules and the quiet
+	// zone, nil bg leaves them transparent.
+	fg color.RGBA
+	bg *color.RGBA
+
+	// standalone wraps the drawing in a standalone document.
+	standalone bool
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		environment: EnvironmentTikZ,
+		moduleSize:  _defaultModuleSize,
+		unit:        _defaultUnit,
+		quietZone:   _defaultQuietZone,
+		fg:          black,
+	}
+}
+
+// colored reports whether colors need to be defined.
+func (o *outputOptions) colored() bool {
+	return o.fg != black || o.bg != nil
+}
+
+// WithEnvironment sets the LaTeX environment, EnvironmentTikZ by default.
+func WithEnvironment(env Environment) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if env > EnvironmentPicture {
+			return
+		}
+
+		o.environment = env
+	})
+}
+
+// WithModuleSize sets the size of each module in any TeX unit (pt, mm, cm, in, bp, pc,
+// dd, cc, sp, em or ex), 0.5mm by default.
+func WithModuleSize(size float64, unit string) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if size <= 0 || !texUnits[unit] {
+			return
+		}
+
+		o.moduleSize, o.unit = size, unit
+	})
+}
+
+// WithQuietZone sets the width of quiet zone in modules, 4 by default as ISO/IEC 18004
+// requires. The quiet zone is included in the size of drawing.
+func WithQuietZone(modules int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if modules < 0 {
+			return
+		}
+
+		o.quietZone = modules
+	})
+}
+
+// WithColors sets colors of dark modules (fg) and light modules and the quiet zone (bg).
+// Dark modules are black and light ones are transparent by default, nil keeps it.
+func WithColors(fg, bg color.Color) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if fg != nil {
+			o.fg = color.RGBAModel.Convert(fg).(color.RGBA)
+		}
+		if bg != nil {
+			c := color.RGBAModel.Convert(bg).(color.RGBA)
+			o.bg = &c
+		}
+	})
+}
+
+// WithStandalone writes a complete document of standalone class, which could be
+// compiled alone and included as PDF.
+func WithStandalone() Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.standalone = true
+	})
+}
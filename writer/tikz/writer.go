@@ -0,0 +1,149 @@
+package tikz
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// ErrEmptyMatrix means the matrix has no modules.
+var ErrEmptyMatrix = errors.New("tikz: empty matrix")
+
+// _rectsPerLine is the count of rectangles in each line of output.
+const _rectsPerLine = 8
+
+// Writer implements qrcode.Writer to draw QR code in LaTeX, dark modules are merged into
+// rectangles.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a TikZ writer which writes LaTeX into out, out is not closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	if mat.Width() == 0 || mat.Height() == 0 {
+		return ErrEmptyMatrix
+	}
+
+	modules := mat.BitmapWithQuietZone(w.option.quietZone)
+	bw := bufio.NewWriter(w.out)
+	o := w.option
+
+	if o.standalone {
+		bw.WriteString("\\documentclass{standalone}\n")
+		if o.environment == EnvironmentTikZ {
+			bw.WriteString("\\usepackage{tikz}\n")
+		} else if o.colored() {
+			bw.WriteString("\\usepackage{color}\n")
+		}
+		bw.WriteString("\\begin{document}\n")
+	}
+	if o.colored() {
+		w.writeColors(bw)
+	}
+
+	if o.environment == EnvironmentPicture {
+		w.writePicture(bw, modules)
+	} else {
+		w.writeTikZ(bw, modules)
+	}
+
+	if o.standalone {
+		bw.WriteString("\\end{document}\n")
+	}
+
+	return bw.Flush()
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// rgb returns c in the rgb model of color package, channels are in [0, 1].
+func rgb(c color.RGBA) string {
+	channel := func(v uint8) string {
+		return strconv.FormatFloat(float64(v)/255, 'f', 4, 64)
+	}
+
+	return channel(c.R) + "," + channel(c.G) + "," + channel(c.B)
+}
+
+// writeColors defines qrdark and qrlight colors.
+func (w *Writer) writeColors(bw *bufio.Writer) {
+	fmt.Fprintf(bw, "\\definecolor{qrdark}{rgb}{%s}\n", rgb(w.option.fg))
+	if w.option.bg != nil {
+		fmt.Fprintf(bw, "\\definecolor{qrlight}{rgb}{%s}\n", rgb(*w.option.bg))
+	}
+}
+
+// writeTikZ writes a tikzpicture in units of modules, dark rectangles are in one path.
+// Y axis is up, so rows are flipped.
+func (w *Writer) writeTikZ(bw *bufio.Writer, modules [][]bool) {
+	o := w.option
+	n := len(modules)
+	size := num(o.moduleSize) + o.unit
+
+	fmt.Fprintf(bw, "\\begin{tikzpicture}[x=%s,y=%s]\n", size, size)
+	// the bounding box includes the quiet zone.
+	if o.bg != nil {
+		fmt.Fprintf(bw, "\\fill[qrlight] (0,0) rectangle (%d,%d);\n", n, n)
+	} else {
+		fmt.Fprintf(bw, "\\path[use as bounding box] (0,0) rectangle (%d,%d);\n", n, n)
+	}
+
+	fill := "\\fill"
+	if o.colored() {
+		fill = "\\fill[qrdark]"
+	}
+	bw.WriteString(fill)
+	for i, r := range qrcode.BitmapRects(modules) {
+		if i%_rectsPerLine == 0 {
+			bw.WriteString("\n ")
+		}
+		fmt.Fprintf(bw, " (%d,%d) rectangle +(%d,%d)", r.Min.X, n-r.Max.Y, r.Dx(), r.Dy())
+	}
+	bw.WriteString(";\n\\end{tikzpicture}\n")
+}
+
+// writePicture writes a picture environment, dark rectangles are \rule in \put. The
+// \unitlength is set in a group, so that it doesn't leak.
+func (w *Writer) writePicture(bw *bufio.Writer, modules [][]bool) {
+	o := w.option
+	n := len(modules)
+
+	fmt.Fprintf(bw, "{\\setlength{\\unitlength}{%s%s}%%\n", num(o.moduleSize), o.unit)
+	fmt.Fprintf(bw, "\\begin{picture}(%d,%d)\n", n, n)
+	if o.bg != nil {
+		fmt.Fprintf(bw, "\\put(0,0){\\color{qrlight}\\rule{%d\\unitlength}{%d\\unitlength}}\n", n, n)
+	}
+	if o.colored() {
+		bw.WriteString("\\color{qrdark}\n")
+	}
+	for _, r := range qrcode.BitmapRects(modules) {
+		fmt.Fprintf(bw, "\\put(%d,%d){\\rule{%d\\unitlength}{%d\\unitlength}}\n", r.Min.X, n-r.Max.Y, r.Dx(), r.Dy())
+	}
+	bw.WriteString("\\end{picture}}\n")
+}
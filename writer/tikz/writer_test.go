@@ -0,0 +1,124 @@
+package tikz
+
+import (
+	"bytes"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paint marks modules covered by rectangles (x, y, w, h) in y-up coordinates.
+func paint(t *testing.T, n int, matches [][]string) [][]bool {
+	bm := make([][]bool, n)
+	for i := range bm {
+		bm[i] = make([]bool, n)
+	}
+	for _, m := range matches {
+		var v [4]int
+		for i := range v {
+			var err error
+			v[i], err = strconv.Atoi(m[i+1])
+			require.NoError(t, err)
+		}
+		for y := v[1]; y < v[1]+v[3]; y++ {
+			for x := v[0]; x < v[0]+v[2]; x++ {
+				require.False(t, bm[n-1-y][x], "rectangles overlap")
+				bm[n-1-y][x] = true
+			}
+		}
+	}
+
+	return bm
+}
+
+// _rect matches a rectangle of tikzpicture.
+var _rect = regexp.MustCompile(`\((\d+),(\d+)\) rectangle \+\((\d+),(\d+)\)`)
+
+func Test_Writer_TikZ(t *testing.T) {
+	qrc, err := qrcode.New("tikz writer")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// invalid options are ignored.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithQuietZone(0), WithModuleSize(1.5, "pt"),
+		WithModuleSize(-1, "pt"), WithModuleSize(1, "px"), WithQuietZone(-1), WithEnvironment(EnvironmentPicture+1))))
+	out := buf.String()
+	assert.Contains(t, out, "\\begin{tikzpicture}[x=1.5pt,y=1.5pt]\n")
+	assert.NotContains(t, out, "definecolor")
+
+	assert.Equal(t, mat.Bitmap(), paint(t, mat.Width(), _rect.FindAllStringSubmatch(out, -1)))
+}
+
+func Test_Writer_Picture(t *testing.T) {
+	qrc, err := qrcode.NewWith("picture writer", qrcode.WithMirror())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithEnvironment(EnvironmentPicture), WithQuietZone(0))))
+	out := buf.String()
+	assert.Contains(t, out, "{\\setlength{\\unitlength}{0.5mm}%\n")
+	assert.NotContains(t, out, "tikzpicture")
+
+	matches := regexp.MustCompile(`\\put\((\d+),(\d+)\)\{\\rule\{(\d+)\\unitlength\}\{(\d+)\\unitlength\}\}`).FindAllStringSubmatch(out, -1)
+	bm := paint(t, n, matches)
+	assert.Equal(t, mat.Bitmap(), bm)
+	// the bottom-left finder is at the bottom-right of mirrored matrix.
+	assert.True(t, bm[n-1][n-1])
+	assert.True(t, bm[n-7][n-7])
+}
+
+func Test_Writer_ReflectanceReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("reversed", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	n := mat.Width() + 2*_defaultQuietZone
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf)))
+	out := buf.String()
+	size := strconv.Itoa(n)
+	assert.Contains(t, out, "\\path[use as bounding box] (0,0) rectangle ("+size+","+size+");\n")
+
+	// the dark quiet zone is filled, the top-left corner of the finder pattern is light.
+	bm := paint(t, n, _rect.FindAllStringSubmatch(out, -1))
+	assert.Equal(t, mat.BitmapWithQuietZone(_defaultQuietZone), bm)
+	assert.True(t, bm[0][0])
+	assert.False(t, bm[_defaultQuietZone][_defaultQuietZone])
+}
+
+func Test_Writer_Standalone_Colors(t *testing.T) {
+	qrc, err := qrcode.New("standalone")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithStandalone(), WithColors(color.RGBA{R: 255, A: 255}, color.White))))
+	out := buf.String()
+	assert.Regexp(t, `^\\documentclass\{standalone\}\n\\usepackage\{tikz\}\n\\begin\{document\}\n`, out)
+	assert.Contains(t, out, "\\definecolor{qrdark}{rgb}{1.0000,0.0000,0.0000}\n")
+	assert.Contains(t, out, "\\definecolor{qrlight}{rgb}{1.0000,1.0000,1.0000}\n")
+	n := mat.Width() + 8
+	assert.Contains(t, out, "\\fill[qrlight] (0,0) rectangle ("+strconv.Itoa(n)+","+strconv.Itoa(n)+");\n\\fill[qrdark]")
+	assert.Regexp(t, `\\end\{document\}\n$`, out)
+
+	buf.Reset()
+	require.NoError(t, qrc.Save(New(&buf, WithStandalone(), WithEnvironment(EnvironmentPicture), WithColors(nil, color.White))))
+	out = buf.String()
+	assert.Contains(t, out, "\\usepackage{color}\n")
+	assert.Contains(t, out, "\\color{qrdark}\n")
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorIs(t, New(&buf, WithStandalone()).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+	assert.Zero(t, buf.Len())
+}
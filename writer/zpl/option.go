@@ -0,0 +1,156 @@
+package zpl
+
+// Mode decides how the QR code is described to the printer.
+type Mode uint8
+
+const (
+	// ModeNative emits the QR code command of printer (^BQ) with the payload and the
+	// error correction level, so that the printer draws it at its own resolution. It's
+	// the default. Modules larger than 10 dots (the max magnification of ^BQ) are
+	// emitted as ModeGraphic.
+	ModeNative Mode = iota
+	// ModeGraphic emits the matrix as bitmap (^GFA) at exact dots per module.
+	ModeGraphic
+)
+
+// Language is the command language of printer.
+type Language uint8
+
+const (
+	// LanguageZPL is the Zebra Programming Language (ZPL II), it's the default.
+	LanguageZPL Language = iota
+	// LanguageEPL is the Eltron Programming Language (EPL2) of older Zebra desktop
+	// printers, only ModeGraphic (GW) is supported.
+	LanguageEPL
+)
+
+const (
+	_defaultDPI = 203
+	// _defaultModuleMillimeters is the size of module if dots per module is not set,
+	// 0.5mm is readable for most handheld scanners.
+	_defaultModuleMillimeters = 0.5
+	// _textMillimeters is the height of human-readable text.
+	_textMillimeters = 3
+)
+
+// Option configures the label printer writer.
+type Option interface {
+	apply(o *outputOptions)
+}
+
+type funcOption struct {
+	f func(o *outputOptions)
+}
+
+func (fo *funcOption) apply(o *outputOptions) {
+	fo.f(o)
+}
+
+func newFuncOption(f func(o *outputOptions)) *funcOption {
+	return &funcOption{
+		f: f,
+	}
+}
+
+type outputOptions struct {
+	mode     Mode
+	language Language
+	dpi      int
+	// dotsPerModule is the size of module in dots, 0 means it's derived from dpi.
+	dotsPerModule int
+	// offsetX and offsetY are the position of the QR code on label in dots.
+	offsetX, offsetY int
+	// text is printed under the QR code if it's not empty.
+	text string
+}
+
+func defaultOutputOptions() *outputOptions {
+	return &outputOptions{
+		mode:     ModeNative,
+		language: LanguageZPL,
+		dpi:      _defaultDPI,
+	}
+}
+
+// dots converts millimeters into dots in printer resolution, at least 1 dot.
+func (o *outputOptions) dots(mm float64) int {
+	n := int(mm*float64(o.dpi)/25.4 + 0.5)
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// moduleDots returns the size of module in dots.
+func (o *outputOptions) moduleDots() int {
+	if o.dotsPerModule > 0 {
+		return o.dotsPerModule
+	}
+
+	return o.dots(_defaultModuleMillimeters)
+}
+
+// WithMode sets how the QR code is described, ModeNative by default.
+func WithMode(mode Mode) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if mode > ModeGraphic {
+			return
+		}
+
+		o.mode = mode
+	})
+}
+
+// WithLanguage sets the command language, LanguageZPL by default.
+func WithLanguage(lang Language) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if lang > LanguageEPL {
+			return
+		}
+
+		o.language = lang
+	})
+}
+
+// WithDPI sets the resolution of printer, 203, 300 or 600 dots per inch, 203 by default.
+// It decides the size of module (0.5mm) and text in dots.
+func WithDPI(dpi int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		switch dpi {
+		case 203, 300, 600:
+			o.dpi = dpi
+		}
+	})
+}
+
+// WithDotsPerModule sets the size of module in dots, which overrides the size derived
+// from DPI. Native ^BQ supports at most 10 dots per module, larger modules are emitted
+// as bitmap.
+func WithDotsPerModule(n int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if n <= 0 {
+			return
+		}
+
+		o.dotsPerModule = n
+	})
+}
+
+// WithOffset sets the position of the QR code on the label in dots.
+func WithOffset(x, y int) Option {
+	return newFuncOption(func(o *outputOptions) {
+		if x < 0 || y < 0 {
+			return
+		}
+
+		o.offsetX, o.offsetY = x, y
+	})
+}
+
+// WithText prints human-readable text under the QR code.
+func WithText(text string) Option {
+	return newFuncOption(func(o *outputOptions) {
+		o.text = text
+	})
+}
@@ -0,0 +1,226 @@
+package zpl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yeqown/go-qrcode/v2"
+)
+
+var _ qrcode.Writer = (*Writer)(nil)
+
+// ErrNoPayload means the matrix is not built by QRCode, so it can't be described by
+// ^BQ, ModeGraphic works for any matrix.
+var ErrNoPayload = errors.New("zpl: matrix has no payload")
+
+// ErrEmptyMatrix means the matrix emitted as bitmap has no modules.
+var ErrEmptyMatrix = errors.New("zpl: empty matrix")
+
+const (
+	// _maxMagnification is the max magnification (dots per module) of ^BQ.
+	_maxMagnification = 10
+	// quietZoneModules is the width of the quiet zone required by ISO/IEC 18004.
+	quietZoneModules = 4
+	// _textGapMillimeters is the gap between the QR code and text.
+	_textGapMillimeters = 1
+)
+
+// Writer implements qrcode.Writer to describe QR code as a label in commands of Zebra
+// printers, the label could be sent to the printer directly.
+type Writer struct {
+	out    io.Writer
+	option *outputOptions
+}
+
+// New creates a label printer writer which writes commands into out, out is not
+// closed.
+func New(out io.Writer, opts ...Option) *Writer {
+	option := defaultOutputOptions()
+	for _, opt := range opts {
+		opt.apply(option)
+	}
+
+	return &Writer{out: out, option: option}
+}
+
+// Close method to implement qrcode.Writer, the output writer is not closed.
+func (w *Writer) Close() error { return nil }
+
+// Write method to implement qrcode.Writer.
+func (w *Writer) Write(mat qrcode.Matrix) error {
+	if w.out == nil {
+		return errors.New("nil writer")
+	}
+	// ^BQ of empty matrix fails with ErrNoPayload.
+	if !w.native(mat) && (mat.Width() == 0 || mat.Height() == 0) {
+		return ErrEmptyMatrix
+	}
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	if w.option.language == LanguageEPL {
+		w.writeEPL(&buf, mat)
+	} else {
+		err = w.writeZPL(&buf, mat)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.out.Write(buf.Bytes())
+	return err
+}
+
+// native reports whether the matrix is described by ^BQ. Mirrored and reflectance
+// reversed matrix can't be drawn by printers, and ^BQ can't magnify modules over
+// _maxMagnification dots, so they are emitted as bitmap.
+func (w *Writer) native(mat qrcode.Matrix) bool {
+	return w.option.mode == ModeNative && w.option.language == LanguageZPL &&
+		!mat.IsMirrored() && !mat.IsReflectanceReversed() &&
+		w.option.moduleDots() <= _maxMagnification
+}
+
+// nativeModules returns the width of ^BQ in modules conservatively. The printer picks
+// the version by its own encoding of the payload, which is not larger than the version
+// of the payload encoded in byte mode.
+func nativeModules(mat qrcode.Matrix) int {
+	modules := mat.Width()
+	qrc, err := qrcode.NewWith(mat.Payload(),
+		qrcode.WithEncodingMode(qrcode.EncModeByte),
+		qrcode.WithErrorCorrectionLevel(mat.ErrorCorrectionLevel()))
+	if err == nil && qrc.Dimension() > modules {
+		modules = qrc.Dimension()
+	}
+
+	return modules
+}
+
+func (w *Writer) writeZPL(buf *bytes.Buffer, mat qrcode.Matrix) error {
+	x, y := w.option.offsetX, w.option.offsetY
+	height := 0
+
+	buf.WriteString("^XA\n")
+	if w.native(mat) {
+		if mat.Payload() == nil {
+			return ErrNoPayload
+		}
+		ec, err := ecLevelCode(mat)
+		if err != nil {
+			return err
+		}
+
+		mag := w.option.moduleDots()
+		// model 2, the data is prefixed by error correction level and "A" for
+		// automatic data input.
+		fmt.Fprintf(buf, "^FO%d,%d^BQN,2,%d^FH_^FD%sA,%s^FS\n", x, y, mag, ec, escapeZPL(mat.Payload()))
+		height = nativeModules(mat) * mag
+	} else {
+		rows, bpr, ink := w.bitmap(mat)
+		fmt.Fprintf(buf, "^FO%d,%d^GFA,%d,%d,%d,", x, y, len(ink), len(ink), bpr)
+		fmt.Fprintf(buf, "%X^FS\n", ink)
+		height = rows
+	}
+
+	if w.option.text != "" {
+		h := w.option.dots(_textMillimeters)
+		fmt.Fprintf(buf, "^FO%d,%d^A0N,%d,%d^FH_^FD%s^FS\n",
+			x, y+height+w.option.dots(_textGapMillimeters), h, h, escapeZPL([]byte(w.option.text)))
+	}
+	buf.WriteString("^XZ\n")
+
+	return nil
+}
+
+func (w *Writer) writeEPL(buf *bytes.Buffer, mat qrcode.Matrix) {
+	x, y := w.option.offsetX, w.option.offsetY
+	rows, bpr, ink := w.bitmap(mat)
+
+	// the leading line feed ends any incomplete command, N clears the image buffer.
+	buf.WriteString("\nN\n")
+	// bits of GW are 0 for black dots.
+	fmt.Fprintf(buf, "GW%d,%d,%d,%d,", x, y, bpr, rows)
+	for _, b := range ink {
+		buf.WriteByte(^b)
+	}
+	buf.WriteString("\n")
+
+	if w.option.text != "" {
+		fmt.Fprintf(buf, "A%d,%d,0,3,1,1,N,\"%s\"\n",
+			x, y+rows+w.option.dots(_textGapMillimeters), escapeEPL(w.option.text))
+	}
+	buf.WriteString("P1\n")
+}
+
+// bitmap returns the matrix in dots, bit 1 is a black dot and rows are padded to bytes.
+// The dark quiet zone of reflectance reversed matrix is included, since the label is
+// white.
+func (w *Writer) bitmap(mat qrcode.Matrix) (rows, bytesPerRow int, ink []byte) {
+	qz := 0
+	if mat.IsReflectanceReversed() {
+		qz = quietZoneModules
+	}
+	modules := mat.BitmapWithQuietZone(qz)
+	dots := w.option.moduleDots()
+
+	// the QR code is square, so the width in dots equals rows.
+	rows = len(modules) * dots
+	bytesPerRow = (rows + 7) / 8
+	ink = make([]byte, rows*bytesPerRow)
+	for my, row := range modules {
+		for mx, dark := range row {
+			if !dark {
+				continue
+			}
+			for dy := 0; dy < dots; dy++ {
+				row := ink[(my*dots+dy)*bytesPerRow:]
+				for dx := 0; dx < dots; dx++ {
+					px := mx*dots + dx
+					row[px/8] |= 0x80 >> (px % 8)
+				}
+			}
+		}
+	}
+
+	return rows, bytesPerRow, ink
+}
+
+// ecLevelCode returns the error correction level in ^BQ.
+func ecLevelCode(mat qrcode.Matrix) (string, error) {
+	switch mat.ErrorCorrectionLevel() {
+	case qrcode.ErrorCorrectionLow:
+		return "L", nil
+	case qrcode.ErrorCorrectionMedium:
+		return "M", nil
+	case qrcode.ErrorCorrectionQuart:
+		return "Q", nil
+	case qrcode.ErrorCorrectionHighest:
+		return "H", nil
+	}
+
+	return "", errors.New("zpl: unknown error correction level")
+}
+
+// escapeZPL escapes field data after ^FH_, command prefixes (^ and ~), the hex
+// indicator (_) and bytes out of printable ASCII are written as _XX.
+func escapeZPL(data []byte) string {
+	var sb strings.Builder
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e || b == '^' || b == '~' || b == '_' {
+			fmt.Fprintf(&sb, "_%02X", b)
+			continue
+		}
+		sb.WriteByte(b)
+	}
+
+	return sb.String()
+}
+
+// escapeEPL escapes quotes and backslashes in EPL strings.
+func escapeEPL(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
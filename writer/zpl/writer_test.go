@@ -0,0 +1,175 @@
+package zpl
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yeqown/go-qrcode/v2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	golden := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(golden, got, 0644))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func Test_Writer_Golden(t *testing.T) {
+	qrc, err := qrcode.NewWith("SKU^12_3~", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium))
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	cases := []struct {
+		golden string
+		opts   []Option
+	}{
+		{golden: "native_203.zpl", opts: []Option{WithOffset(30, 40), WithText("SKU^12_3~")}},
+		{golden: "native_600.zpl", opts: []Option{WithDPI(600), WithDotsPerModule(10)}},
+		{golden: "graphic_203.zpl", opts: []Option{WithMode(ModeGraphic), WithDotsPerModule(3), WithText("SKU")}},
+		{golden: "graphic_203.epl", opts: []Option{WithLanguage(LanguageEPL), WithDotsPerModule(2), WithText(`say "hi"`)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, New(&buf, c.opts...).Write(mat))
+			assertGolden(t, c.golden, buf.Bytes())
+		})
+	}
+}
+
+func Test_Writer_Bitmap(t *testing.T) {
+	qrc, err := qrcode.New("bitmap")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	bm := mat.Bitmap()
+
+	w := New(nil, WithDotsPerModule(3))
+	rows, bpr, ink := w.bitmap(mat)
+	require.Equal(t, len(bm)*3, rows)
+	require.Equal(t, (rows+7)/8, bpr)
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < rows; x++ {
+			dot := ink[y*bpr+x/8]&(0x80>>(x%8)) != 0
+			require.Equal(t, bm[y/3][x/3], dot, "dot (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_ReflectanceReversed(t *testing.T) {
+	qrc, err := qrcode.NewWith("reversed", qrcode.WithReflectanceReversal())
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	// printers can't draw reversed ^BQ, so it's emitted as bitmap with dark quiet zone.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(1))))
+	assert.NotContains(t, buf.String(), "^BQ")
+	assert.Contains(t, buf.String(), "^GFA,")
+
+	rows, _, ink := New(nil, WithDotsPerModule(1)).bitmap(mat)
+	assert.Equal(t, mat.Width()+2*quietZoneModules, rows)
+	assert.Equal(t, byte(0xff), ink[0])
+}
+
+func Test_Writer_NativeFallback(t *testing.T) {
+	qrc, err := qrcode.New("SKU-12345")
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+
+	cases := []struct {
+		name   string
+		opts   []Option
+		dots   int
+		native bool
+	}{
+		{name: "10 dots", opts: []Option{WithDotsPerModule(10)}, dots: 10, native: true},
+		{name: "11 dots", opts: []Option{WithDotsPerModule(11)}, dots: 11},
+		// 0.5mm is 12 dots at 600 DPI, ^BQ can't magnify modules so much.
+		{name: "600 DPI", opts: []Option{WithDPI(600)}, dots: 12},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, qrc.Save(New(&buf, c.opts...)))
+			if c.native {
+				assert.Contains(t, buf.String(), "^BQN,2,10")
+				assert.NotContains(t, buf.String(), "^GFA")
+				return
+			}
+
+			assert.NotContains(t, buf.String(), "^BQ")
+			rows, bpr, ink := New(nil, c.opts...).bitmap(mat)
+			assert.Equal(t, mat.Width()*c.dots, rows)
+			assert.Contains(t, buf.String(), fmt.Sprintf("^GFA,%d,%d,%d,", len(ink), len(ink), bpr))
+		})
+	}
+}
+
+func Test_Writer_Mirrored(t *testing.T) {
+	qrc, err := qrcode.NewWith("mirrored", qrcode.WithMirror())
+	require.NoError(t, err)
+	normal, err := qrcode.New("mirrored")
+	require.NoError(t, err)
+	mat := normal.Matrix()
+
+	// printers can't mirror ^BQ, dots of the bitmap are mirrored modules.
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(2))))
+	assert.NotContains(t, buf.String(), "^BQ")
+
+	rows, bpr, ink := New(nil, WithDotsPerModule(2)).bitmap(qrc.Matrix())
+	require.Equal(t, mat.Width()*2, rows)
+	bm := mat.Bitmap()
+	for y := 0; y < rows; y++ {
+		for x := 0; x < rows; x++ {
+			dot := ink[y*bpr+x/8]&(0x80>>(x%8)) != 0
+			require.Equal(t, bm[y/2][len(bm)-1-x/2], dot, "dot (%d, %d)", x, y)
+		}
+	}
+}
+
+func Test_Writer_NativeText(t *testing.T) {
+	// the alphanumeric payload is version 1 (21 modules), but it's version 2 (25
+	// modules) in byte mode, which the printer may pick.
+	qrc, err := qrcode.NewWith("HELLO WORLD 12345", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium))
+	require.NoError(t, err)
+	mat := qrc.Matrix()
+	require.Equal(t, 21, mat.Width())
+	assert.Equal(t, 25, nativeModules(mat))
+
+	var buf bytes.Buffer
+	require.NoError(t, qrc.Save(New(&buf, WithDotsPerModule(4), WithText("HELLO"))))
+	out := buf.String()
+	assert.Contains(t, out, "^BQN,2,4")
+	// the gap is 1mm (8 dots at 203 DPI).
+	assert.Contains(t, out, fmt.Sprintf("^FO0,%d^A0N", 25*4+8))
+}
+
+func Test_Writer_EmptyMatrix(t *testing.T) {
+	// ^BQ needs the payload, bitmaps need modules.
+	var buf bytes.Buffer
+	assert.ErrorIs(t, New(&buf).Write(qrcode.Matrix{}), ErrNoPayload)
+	assert.ErrorIs(t, New(&buf, WithMode(ModeGraphic)).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+	assert.ErrorIs(t, New(&buf, WithLanguage(LanguageEPL)).Write(qrcode.Matrix{}), ErrEmptyMatrix)
+	assert.Zero(t, buf.Len())
+}
+
+func Test_escapeZPL(t *testing.T) {
+	assert.Equal(t, "a_5Eb_7Ec_5F_C3_A9", escapeZPL([]byte("a^b~c_é")))
+}